@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
@@ -24,6 +26,19 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// ReloadRequested is called when a configuration reload is requested via
+	// the control API. Reloading is not supported if unset.
+	ReloadRequested func()
+
+	gatherRequests trigger
+	flushRequests  trigger
+
+	// The running input and output units used to apply configuration changes
+	// at runtime. The lock also protects the plugin lists of the config.
+	inputs    *inputUnit
+	outputs   *outputUnit
+	unitsLock sync.Mutex
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -48,6 +63,15 @@ func NewAgent(cfg *config.Config) *Agent {
 type inputUnit struct {
 	dst    chan<- telegraf.Metric
 	inputs []*models.RunningInput
+
+	// State of the gather loops required for adding and removing inputs at
+	// runtime
+	sync.Mutex
+	ctx       context.Context
+	startTime time.Time
+	loops     map[*models.RunningInput]*pluginLoop
+	wg        sync.WaitGroup
+	closed    bool
 }
 
 //  ______     ┌───────────┐     ______
@@ -99,6 +123,89 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+
+	// Outputs receiving all metrics of the pipeline, i.e. all outputs except
+	// those only receiving metrics rejected by other outputs or metrics sent
+	// along routes
+	receivers []*models.RunningOutput
+
+	// Routes sending the selected metrics exclusively to the output at the
+	// same position, nil if the output is not running
+	routes       []*models.Route
+	routeTargets []*models.RunningOutput
+
+	// State of the flush loops required for adding and removing outputs at
+	// runtime
+	sync.RWMutex
+	ctx    context.Context
+	loops  map[*models.RunningOutput]*pluginLoop
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// pluginLoop is the handle of a running gather or flush loop.
+type pluginLoop struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// forwardOutput forwards the metrics rejected by an output or the batches of
+// a failing output to another running output. The target is looked up on
+// each call as outputs might be replaced at runtime.
+type forwardOutput struct {
+	unit   *outputUnit
+	target string
+}
+
+func (d *forwardOutput) Add(metrics []telegraf.Metric) error {
+	d.unit.RLock()
+	defer d.unit.RUnlock()
+
+	for _, output := range d.unit.outputs {
+		if output.HasName(d.target) {
+			for _, m := range metrics {
+				output.AddMetricNoCopy(m)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("output %q not running", d.target)
+}
+
+func (*forwardOutput) Close() error {
+	return nil
+}
+
+// updateReceivers determines the outputs receiving the metrics of the
+// pipeline. The caller must hold the lock of the unit.
+func (unit *outputUnit) updateReceivers() {
+	var targets []string
+	for _, output := range unit.outputs {
+		if output.Config.DeadLetter != nil && output.Config.DeadLetter.Output != "" {
+			targets = append(targets, output.Config.DeadLetter.Output)
+		}
+	}
+
+	unit.routeTargets = make([]*models.RunningOutput, len(unit.routes))
+	for i, route := range unit.routes {
+		targets = append(targets, route.Output)
+		if route.Fallback != "" {
+			targets = append(targets, route.Fallback)
+		}
+		for _, output := range unit.outputs {
+			if output.HasName(route.Output) {
+				unit.routeTargets[i] = output
+				break
+			}
+		}
+	}
+
+	unit.receivers = make([]*models.RunningOutput, 0, len(unit.outputs))
+	for _, output := range unit.outputs {
+		if !slices.ContainsFunc(targets, output.HasName) {
+			unit.receivers = append(unit.receivers, output)
+		}
+	}
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -135,6 +242,18 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	if a.Config.Agent.ControlAPIAddress != "" {
+		log.Printf("D! [agent] Starting control API")
+		api, err := newControlAPI(a, a.Config.Agent.ControlAPIAddress)
+		if err != nil {
+			return fmt.Errorf("creating control API failed: %w", err)
+		}
+		if err := api.start(); err != nil {
+			return fmt.Errorf("starting control API failed: %w", err)
+		}
+		defer api.stop()
+	}
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
@@ -170,6 +289,15 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	a.unitsLock.Lock()
+	a.inputs, a.outputs = iu, ou
+	a.unitsLock.Unlock()
+	defer func() {
+		a.unitsLock.Lock()
+		a.inputs, a.outputs = nil, nil
+		a.unitsLock.Unlock()
+	}()
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -205,6 +333,14 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.runInputs(ctx, startTime, iu)
 	}()
 
+	if a.Config.Persister != nil && a.Config.Persister.Interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runPersister(ctx, a.Config.Persister)
+		}()
+	}
+
 	wg.Wait()
 
 	if a.Config.Persister != nil {
@@ -259,6 +395,25 @@ func (a *Agent) InitPlugins() error {
 	return nil
 }
 
+// runPersister periodically stores the plugin states until the context is
+// done. The final states are stored on shutdown after all plugins stopped.
+func (a *Agent) runPersister(ctx context.Context, p *persister.Persister) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Printf("D! [agent] Checkpointing plugin states")
+			if err := p.Store(); err != nil {
+				log.Printf("E! [agent] Checkpointing plugin states failed: %v", err)
+			}
+		}
+	}
+}
+
 // initPersister initializes the persister and registers the plugins.
 func (a *Agent) initPersister() error {
 	if err := a.Config.Persister.Init(); err != nil {
@@ -349,45 +504,56 @@ func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningIn
 	}
 
 	for _, input := range inputs {
-		// Service input plugins are not normally subject to timestamp
-		// rounding except for when precision is set on the input plugin.
-		//
-		// This only applies to the accumulator passed to Start(), the
-		// Gather() accumulator does apply rounding according to the
-		// precision and interval agent/plugin settings.
-		var interval time.Duration
-		var precision time.Duration
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
-
-		acc := NewAccumulator(input, dst)
-		acc.SetPrecision(getPrecision(precision, interval))
-
-		if err := input.Start(acc); err != nil {
-			// If the model tells us to remove the plugin we do so without error
-			var fatalErr *internal.FatalError
-			if errors.As(err, &fatalErr) {
-				log.Printf("I! [agent] Failed to start %s, shutting down plugin: %s", input.LogName(), err)
-				continue
-			}
-
+		started, err := startInput(dst, input)
+		if err != nil {
 			stopRunningInputs(unit.inputs)
-
-			return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
+			return nil, err
 		}
-		if err := input.Probe(); err != nil {
-			// Probe failures are non-fatal to the agent but should only remove the plugin
-			log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
-			input.Stop()
-			continue
+		if started {
+			unit.inputs = append(unit.inputs, input)
 		}
-		unit.inputs = append(unit.inputs, input)
 	}
 
 	return unit, nil
 }
 
+// startInput starts the given input and returns false if the plugin should be
+// removed without causing an error.
+func startInput(dst chan<- telegraf.Metric, input *models.RunningInput) (bool, error) {
+	// Service input plugins are not normally subject to timestamp
+	// rounding except for when precision is set on the input plugin.
+	//
+	// This only applies to the accumulator passed to Start(), the
+	// Gather() accumulator does apply rounding according to the
+	// precision and interval agent/plugin settings.
+	var interval time.Duration
+	var precision time.Duration
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	acc := NewAccumulator(input, dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	if err := input.Start(acc); err != nil {
+		// If the model tells us to remove the plugin we do so without error
+		var fatalErr *internal.FatalError
+		if errors.As(err, &fatalErr) {
+			log.Printf("I! [agent] Failed to start %s, shutting down plugin: %s", input.LogName(), err)
+			return false, nil
+		}
+
+		return false, fmt.Errorf("starting input %s: %w", input.LogName(), err)
+	}
+	if err := input.Probe(); err != nil {
+		// Probe failures are non-fatal to the agent but should only remove the plugin
+		log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
+		input.Stop()
+		return false, nil
+	}
+	return true, nil
+}
+
 // runInputs starts and triggers the periodic gather for Inputs.
 //
 // When the context is done the timers are stopped and this function returns
@@ -397,58 +563,86 @@ func (a *Agent) runInputs(
 	startTime time.Time,
 	unit *inputUnit,
 ) {
-	var wg sync.WaitGroup
-	tickers := make([]Ticker, 0, len(unit.inputs))
+	unit.Lock()
+	unit.ctx = ctx
+	unit.startTime = startTime
+	unit.loops = make(map[*models.RunningInput]*pluginLoop, len(unit.inputs))
 	for _, input := range unit.inputs {
-		// Overwrite agent interval if this plugin has its own.
-		interval := time.Duration(a.Config.Agent.Interval)
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
-		}
+		a.startGatherLoop(unit, input)
+	}
+	running := len(unit.inputs) > 0
+	unit.Unlock()
 
-		// Overwrite agent precision if this plugin has its own.
-		precision := time.Duration(a.Config.Agent.Precision)
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
+	// Keep running until the context is done to allow adding inputs at
+	// runtime. Without any input to start with there is nothing to do.
+	if running {
+		<-ctx.Done()
+	}
 
-		// Overwrite agent collection_jitter if this plugin has its own.
-		jitter := time.Duration(a.Config.Agent.CollectionJitter)
-		if input.Config.CollectionJitter != 0 {
-			jitter = input.Config.CollectionJitter
-		}
+	unit.Lock()
+	unit.closed = true
+	unit.Unlock()
+	unit.wg.Wait()
 
-		// Overwrite agent collection_offset if this plugin has its own.
-		offset := time.Duration(a.Config.Agent.CollectionOffset)
-		if input.Config.CollectionOffset != 0 {
-			offset = input.Config.CollectionOffset
-		}
+	log.Printf("D! [agent] Stopping service inputs")
+	stopRunningInputs(unit.inputs)
 
-		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
-			ticker = NewAlignedTicker(startTime, interval, jitter, offset)
-		} else {
-			ticker = NewUnalignedTicker(interval, jitter, offset)
-		}
-		tickers = append(tickers, ticker)
+	close(unit.dst)
+	log.Printf("D! [agent] Input channel closed")
+}
 
-		acc := NewAccumulator(input, unit.dst)
-		acc.SetPrecision(getPrecision(precision, interval))
+// startGatherLoop runs the periodic gather for the given input in the
+// background. The caller must hold the lock of the unit.
+func (a *Agent) startGatherLoop(unit *inputUnit, input *models.RunningInput) {
+	// Overwrite agent interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.Interval)
+	if input.Config.Interval != 0 {
+		interval = input.Config.Interval
+	}
 
-		wg.Add(1)
-		go func(input *models.RunningInput) {
-			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
-		}(input)
+	// Overwrite agent precision if this plugin has its own.
+	precision := time.Duration(a.Config.Agent.Precision)
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
 	}
-	defer stopTickers(tickers)
-	wg.Wait()
 
-	log.Printf("D! [agent] Stopping service inputs")
-	stopRunningInputs(unit.inputs)
+	// Overwrite agent collection_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.CollectionJitter)
+	if input.Config.CollectionJitter != 0 {
+		jitter = input.Config.CollectionJitter
+	}
 
-	close(unit.dst)
-	log.Printf("D! [agent] Input channel closed")
+	// Overwrite agent collection_offset if this plugin has its own.
+	offset := time.Duration(a.Config.Agent.CollectionOffset)
+	if input.Config.CollectionOffset != 0 {
+		offset = input.Config.CollectionOffset
+	}
+
+	var ticker Ticker
+	if a.Config.Agent.RoundInterval {
+		ticker = NewAlignedTicker(unit.startTime, interval, jitter, offset)
+	} else {
+		ticker = NewUnalignedTicker(interval, jitter, offset)
+	}
+
+	acc := NewAccumulator(input, unit.dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	loop := &pluginLoop{cancel: cancel, done: make(chan struct{})}
+	unit.loops[input] = loop
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(loop.done)
+		defer ticker.Stop()
+
+		requested := a.gatherRequests.subscribe()
+		defer a.gatherRequests.unsubscribe(requested)
+
+		a.gatherLoop(ctx, acc, input, ticker, interval, requested)
+	}()
 }
 
 // testStartInputs is a variation of startInputs for use in --test and --once mode.
@@ -560,14 +754,15 @@ func stopRunningOutputs(outputs []*models.RunningOutput) {
 	}
 }
 
-// gather runs an input's gather function periodically until the context is
-// done.
+// gather runs an input's gather function periodically or on request until the
+// context is done.
 func (a *Agent) gatherLoop(
 	ctx context.Context,
 	acc telegraf.Accumulator,
 	input *models.RunningInput,
 	ticker Ticker,
 	interval time.Duration,
+	requested <-chan struct{},
 ) {
 	for {
 		select {
@@ -576,6 +771,11 @@ func (a *Agent) gatherLoop(
 			if err != nil {
 				acc.AddError(err)
 			}
+		case <-requested:
+			err := a.gatherOnce(acc, input, ticker, interval)
+			if err != nil {
+				acc.AddError(err)
+			}
 		case <-ctx.Done():
 			return
 		}
@@ -829,56 +1029,111 @@ func (*Agent) connectOutput(ctx context.Context, output *models.RunningOutput) e
 func (a *Agent) runOutputs(
 	unit *outputUnit,
 ) {
-	var wg sync.WaitGroup
-
-	// Start flush loop
-	interval := time.Duration(a.Config.Agent.FlushInterval)
-	jitter := time.Duration(a.Config.Agent.FlushJitter)
-
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Start flush loops
+	unit.Lock()
+	unit.ctx = ctx
+	unit.loops = make(map[*models.RunningOutput]*pluginLoop, len(unit.outputs))
 	for _, output := range unit.outputs {
-		interval := interval
-		// Overwrite agent flush_interval if this plugin has its own.
-		if output.Config.FlushInterval != 0 {
-			interval = output.Config.FlushInterval
-		}
-
-		jitter := jitter
-		// Overwrite agent flush_jitter if this plugin has its own.
-		if output.Config.FlushJitter != 0 {
-			jitter = output.Config.FlushJitter
-		}
-
-		wg.Add(1)
-		go func(output *models.RunningOutput) {
-			defer wg.Done()
-
-			ticker := NewRollingTicker(interval, jitter)
-			defer ticker.Stop()
-
-			a.flushLoop(ctx, output, ticker)
-		}(output)
+		setForwardOutputs(unit, output)
+		a.startFlushLoop(unit, output)
 	}
+	unit.routes = a.Config.Routes
+	unit.updateReceivers()
+	unit.Unlock()
 
+	var targets []*models.RunningOutput
 	for metric := range unit.src {
-		for i, output := range unit.outputs {
-			if i == len(unit.outputs)-1 {
+		unit.RLock()
+		targets = append(targets[:0], unit.receivers...)
+		if output := unit.route(metric); output != nil {
+			targets = append(targets, output)
+		}
+		if len(targets) == 0 {
+			metric.Drop()
+		}
+		for i, output := range targets {
+			if i == len(targets)-1 {
 				output.AddMetricNoCopy(metric)
 			} else {
 				output.AddMetric(metric)
 			}
 		}
+		unit.RUnlock()
 	}
 
 	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
+	unit.Lock()
+	unit.closed = true
+	unit.Unlock()
 	cancel()
-	wg.Wait()
+	unit.wg.Wait()
 
 	log.Println("I! [agent] Stopping running outputs")
 	stopRunningOutputs(unit.outputs)
 }
 
+// route returns the output of the first route selecting the metric if any.
+// The caller must hold the lock of the unit.
+func (unit *outputUnit) route(metric telegraf.Metric) *models.RunningOutput {
+	for i, route := range unit.routes {
+		ok, err := route.Match(metric)
+		if err != nil {
+			log.Printf("E! [agent] Evaluating route to %q failed: %v", route.Output, err)
+			continue
+		}
+		if ok {
+			return unit.routeTargets[i]
+		}
+	}
+	return nil
+}
+
+// setForwardOutputs forwards the metrics rejected by the given output to
+// the configured dead-letter output and the batches of the failing output to
+// the configured fallback output if any.
+func setForwardOutputs(unit *outputUnit, output *models.RunningOutput) {
+	if output.Config.Fallback != nil {
+		output.SetFallbackSink(&forwardOutput{unit: unit, target: output.Config.Fallback.Output})
+	}
+	if output.Config.DeadLetter == nil || output.Config.DeadLetter.Output == "" {
+		return
+	}
+	output.SetDeadLetterSink(&forwardOutput{unit: unit, target: output.Config.DeadLetter.Output})
+}
+
+// startFlushLoop runs the periodic flush for the given output in the
+// background. The caller must hold the lock of the unit.
+func (a *Agent) startFlushLoop(unit *outputUnit, output *models.RunningOutput) {
+	// Overwrite agent flush_interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.FlushInterval)
+	if output.Config.FlushInterval != 0 {
+		interval = output.Config.FlushInterval
+	}
+
+	// Overwrite agent flush_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.FlushJitter)
+	if output.Config.FlushJitter != 0 {
+		jitter = output.Config.FlushJitter
+	}
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	loop := &pluginLoop{cancel: cancel, done: make(chan struct{})}
+	unit.loops[output] = loop
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(loop.done)
+
+		ticker := NewRollingTicker(interval, jitter)
+		defer ticker.Stop()
+
+		a.flushLoop(ctx, output, ticker)
+	}()
+}
+
 // flushLoop runs an output's flush function periodically until the context is
 // done.
 func (a *Agent) flushLoop(
@@ -897,6 +1152,9 @@ func (a *Agent) flushLoop(
 	watchForFlushSignal(flushRequested)
 	defer stopListeningForFlushSignal(flushRequested)
 
+	flushTriggered := a.flushRequests.subscribe()
+	defer a.flushRequests.unsubscribe(flushTriggered)
+
 	for {
 		// Favor shutdown over other methods.
 		select {
@@ -914,6 +1172,8 @@ func (a *Agent) flushLoop(
 			logError(a.flushOnce(output, ticker, output.Write))
 		case <-flushRequested:
 			logError(a.flushOnce(output, ticker, output.Write))
+		case <-flushTriggered:
+			logError(a.flushOnce(output, ticker, output.Write))
 		case <-output.BatchReady:
 			logError(a.flushBatch(output, output.WriteBatch))
 		}
@@ -1207,9 +1467,3 @@ func panicRecover(input *models.RunningInput) {
 			"https://github.com/influxdata/telegraf/issues/new/choose")
 	}
 }
-
-func stopTickers(tickers []Ticker) {
-	for _, ticker := range tickers {
-		ticker.Stop()
-	}
-}
@@ -118,6 +118,39 @@ func TestAgent_LoadOutput(t *testing.T) {
 	require.Len(t, a.Config.Outputs, 3)
 }
 
+func TestAgent_Routes(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[outputs.discard]]
+  alias = "cpu"
+[[outputs.discard]]
+  alias = "other"
+[[outputs.discard]]
+  alias = "backup"
+[[outputs.discard]]
+  alias = "all"
+
+[[routes]]
+  output = "cpu"
+  fallback = "backup"
+  namepass = ["cpu"]
+[[routes]]
+  output = "other"
+`), config.EmptySourcePath))
+
+	unit := &outputUnit{outputs: c.Outputs, routes: c.Routes}
+	unit.updateReceivers()
+
+	// Outputs used in routes must only receive routed metrics
+	require.Equal(t, []*models.RunningOutput{c.Outputs[3]}, unit.receivers)
+
+	// Metrics must be routed to the first matching route only
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Same(t, c.Outputs[0], unit.route(m))
+	m = testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Same(t, c.Outputs[1], unit.route(m))
+}
+
 func TestWindow(t *testing.T) {
 	parse := func(s string) time.Time {
 		tm, err := time.Parse(time.RFC3339, s)
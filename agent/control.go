@@ -0,0 +1,266 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// trigger allows to request an action, e.g. a gather or a flush, from a set of
+// running plugin loops on demand. Each loop subscribes a channel and receives
+// at most one pending request at a time.
+type trigger struct {
+	listeners map[chan struct{}]bool
+	sync.Mutex
+}
+
+// subscribe returns a new channel receiving the requests.
+func (t *trigger) subscribe() chan struct{} {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.listeners == nil {
+		t.listeners = make(map[chan struct{}]bool)
+	}
+	ch := make(chan struct{}, 1)
+	t.listeners[ch] = true
+	return ch
+}
+
+// unsubscribe removes the given channel from the listeners.
+func (t *trigger) unsubscribe(ch chan struct{}) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.listeners, ch)
+}
+
+// fire sends a request to all listeners without blocking and returns the
+// number of listeners notified.
+func (t *trigger) fire() int {
+	t.Lock()
+	defer t.Unlock()
+
+	for ch := range t.listeners {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// There is already a pending request
+		}
+	}
+	return len(t.listeners)
+}
+
+// pluginStatus is the state of a running plugin as reported by the control API.
+type pluginStatus struct {
+	ID            string           `json:"id"`
+	Name          string           `json:"name"`
+	Alias         string           `json:"alias,omitempty"`
+	BufferLength  *int             `json:"buffer_length,omitempty"`
+	Statistics    map[string]int64 `json:"statistics"`
+	LastError     string           `json:"last_error,omitempty"`
+	LastErrorTime *time.Time       `json:"last_error_time,omitempty"`
+}
+
+// pluginGraph lists the running plugins by type.
+type pluginGraph struct {
+	Inputs      []pluginStatus `json:"inputs"`
+	Processors  []pluginStatus `json:"processors"`
+	Aggregators []pluginStatus `json:"aggregators"`
+	Outputs     []pluginStatus `json:"outputs"`
+}
+
+// requestResponse is returned by the control API for action requests.
+type requestResponse struct {
+	Requested int `json:"requested"`
+}
+
+// errorResponse is returned by the control API in case of errors.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// controlAPI serves the local HTTP API to inspect and control the agent.
+type controlAPI struct {
+	agent    *Agent
+	scheme   string
+	address  string
+	listener net.Listener
+	server   *http.Server
+	done     chan struct{}
+}
+
+func newControlAPI(agent *Agent, address string) (*controlAPI, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing address failed: %w", err)
+	}
+
+	api := &controlAPI{
+		agent:  agent,
+		scheme: u.Scheme,
+	}
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing host in %q", address)
+		}
+		api.address = u.Host
+	case "unix":
+		api.address = filepath.FromSlash(u.Path)
+		if runtime.GOOS == "windows" && strings.Contains(api.address, ":") {
+			api.address = strings.TrimPrefix(api.address, `\`)
+		}
+		if api.address == "" {
+			return nil, fmt.Errorf("missing socket path in %q", address)
+		}
+	default:
+		return nil, fmt.Errorf("unknown protocol %q in %q", u.Scheme, address)
+	}
+
+	return api, nil
+}
+
+// start opens the listener and serves the API in the background.
+func (c *controlAPI) start() error {
+	if c.scheme == "unix" {
+		if err := os.Remove(c.address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing socket failed: %w", err)
+		}
+	}
+
+	listener, err := net.Listen(c.scheme, c.address)
+	if err != nil {
+		return err
+	}
+	c.listener = listener
+	c.server = &http.Server{
+		Handler:      c.handler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		if err := c.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving control API failed: %v", err)
+		}
+	}()
+	log.Printf("I! [agent] Control API listening on %s://%s", c.scheme, listener.Addr())
+
+	return nil
+}
+
+// stop shuts down the API server and waits for it to finish.
+func (c *controlAPI) stop() {
+	if c.server == nil {
+		return
+	}
+	if err := c.server.Close(); err != nil {
+		log.Printf("E! [agent] Closing control API failed: %v", err)
+	}
+	<-c.done
+}
+
+func (c *controlAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/plugins", c.servePlugins)
+	mux.HandleFunc("POST /api/v1/gather", c.serveGather)
+	mux.HandleFunc("POST /api/v1/flush", c.serveFlush)
+	mux.HandleFunc("POST /api/v1/reload", c.serveReload)
+	return mux
+}
+
+func (c *controlAPI) servePlugins(w http.ResponseWriter, _ *http.Request) {
+	c.agent.unitsLock.Lock()
+	defer c.agent.unitsLock.Unlock()
+	cfg := c.agent.Config
+
+	graph := pluginGraph{
+		Inputs:      make([]pluginStatus, 0, len(cfg.Inputs)),
+		Processors:  make([]pluginStatus, 0, len(cfg.Processors)),
+		Aggregators: make([]pluginStatus, 0, len(cfg.Aggregators)),
+		Outputs:     make([]pluginStatus, 0, len(cfg.Outputs)),
+	}
+	for _, input := range cfg.Inputs {
+		status := newPluginStatus(input.ID(), input.Config.Name, input.Config.Alias, input.Statistics())
+		status.setLastError(input.LastError())
+		graph.Inputs = append(graph.Inputs, status)
+	}
+	for _, processor := range cfg.Processors {
+		status := newPluginStatus(processor.ID(), processor.Config.Name, processor.Config.Alias, processor.Statistics())
+		status.setLastError(processor.LastError())
+		graph.Processors = append(graph.Processors, status)
+	}
+	for _, aggregator := range cfg.Aggregators {
+		status := newPluginStatus(aggregator.ID(), aggregator.Config.Name, aggregator.Config.Alias, aggregator.Statistics())
+		status.setLastError(aggregator.LastError())
+		graph.Aggregators = append(graph.Aggregators, status)
+	}
+	for _, output := range cfg.Outputs {
+		status := newPluginStatus(output.ID(), output.Config.Name, output.Config.Alias, output.Statistics())
+		status.setLastError(output.LastError())
+		length := output.BufferLength()
+		status.BufferLength = &length
+		graph.Outputs = append(graph.Outputs, status)
+	}
+
+	writeJSON(w, http.StatusOK, graph)
+}
+
+func (c *controlAPI) serveGather(w http.ResponseWriter, _ *http.Request) {
+	n := c.agent.gatherRequests.fire()
+	log.Printf("D! [agent] Gather requested via control API for %d inputs", n)
+	writeJSON(w, http.StatusAccepted, requestResponse{Requested: n})
+}
+
+func (c *controlAPI) serveFlush(w http.ResponseWriter, _ *http.Request) {
+	n := c.agent.flushRequests.fire()
+	log.Printf("D! [agent] Flush requested via control API for %d outputs", n)
+	writeJSON(w, http.StatusAccepted, requestResponse{Requested: n})
+}
+
+func (c *controlAPI) serveReload(w http.ResponseWriter, _ *http.Request) {
+	if c.agent.ReloadRequested == nil {
+		writeJSON(w, http.StatusNotImplemented, errorResponse{Error: "reloading is not supported"})
+		return
+	}
+	log.Printf("I! [agent] Config reload requested via control API")
+	c.agent.ReloadRequested()
+	writeJSON(w, http.StatusAccepted, requestResponse{Requested: 1})
+}
+
+func newPluginStatus(id, name, alias string, stats map[string]int64) pluginStatus {
+	return pluginStatus{
+		ID:         id,
+		Name:       name,
+		Alias:      alias,
+		Statistics: stats,
+	}
+}
+
+func (s *pluginStatus) setLastError(msg string, ts time.Time) {
+	if msg == "" {
+		return
+	}
+	s.LastError = msg
+	s.LastErrorTime = &ts
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("E! [agent] Writing control API response failed: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestControlAPIAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		scheme   string
+		expected string
+		errmsg   string
+	}{
+		{
+			name:     "tcp",
+			address:  "tcp://127.0.0.1:8099",
+			scheme:   "tcp",
+			expected: "127.0.0.1:8099",
+		},
+		{
+			name:     "unix",
+			address:  "unix:///var/run/telegraf/control.sock",
+			scheme:   "unix",
+			expected: "/var/run/telegraf/control.sock",
+		},
+		{
+			name:    "missing host",
+			address: "tcp://",
+			errmsg:  "missing host",
+		},
+		{
+			name:    "unknown protocol",
+			address: "udp://127.0.0.1:8099",
+			errmsg:  "unknown protocol",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, err := newControlAPI(&Agent{}, tt.address)
+			if tt.errmsg != "" {
+				require.ErrorContains(t, err, tt.errmsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.scheme, api.scheme)
+			require.Equal(t, tt.expected, api.address)
+		})
+	}
+}
+
+func TestControlAPIPlugins(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[[inputs.mem]]
+  alias = "memory"
+[[outputs.discard]]
+`), config.EmptySourcePath))
+
+	api, err := newControlAPI(NewAgent(cfg), "tcp://127.0.0.1:0")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/plugins", nil)
+	resp := httptest.NewRecorder()
+	api.handler().ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var graph pluginGraph
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &graph))
+	require.Len(t, graph.Inputs, 1)
+	require.Equal(t, "mem", graph.Inputs[0].Name)
+	require.Equal(t, "memory", graph.Inputs[0].Alias)
+	require.Equal(t, cfg.Inputs[0].ID(), graph.Inputs[0].ID)
+	require.Contains(t, graph.Inputs[0].Statistics, "metrics_gathered")
+	require.Empty(t, graph.Processors)
+	require.Empty(t, graph.Aggregators)
+	require.Len(t, graph.Outputs, 1)
+	require.Equal(t, "discard", graph.Outputs[0].Name)
+	require.NotNil(t, graph.Outputs[0].BufferLength)
+	require.Equal(t, 0, *graph.Outputs[0].BufferLength)
+	require.Contains(t, graph.Outputs[0].Statistics, "buffer_size")
+
+	// Only GET is allowed for listing the plugins
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/plugins", nil)
+	resp = httptest.NewRecorder()
+	api.handler().ServeHTTP(resp, req)
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
+
+func TestControlAPITrigger(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	api, err := newControlAPI(a, "tcp://127.0.0.1:0")
+	require.NoError(t, err)
+
+	gather := a.gatherRequests.subscribe()
+	defer a.gatherRequests.unsubscribe(gather)
+	flush := a.flushRequests.subscribe()
+	defer a.flushRequests.unsubscribe(flush)
+
+	// Trigger a gather and make sure it is received
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/gather", nil)
+	resp := httptest.NewRecorder()
+	api.handler().ServeHTTP(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	require.JSONEq(t, `{"requested": 1}`, resp.Body.String())
+	require.Len(t, gather, 1)
+	require.Empty(t, flush)
+
+	// Further requests must not block while a request is pending
+	resp = httptest.NewRecorder()
+	api.handler().ServeHTTP(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	require.Len(t, gather, 1)
+
+	// Trigger a flush
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/flush", nil)
+	resp = httptest.NewRecorder()
+	api.handler().ServeHTTP(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	require.Len(t, flush, 1)
+}
+
+func TestControlAPIReload(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	api, err := newControlAPI(a, "tcp://127.0.0.1:0")
+	require.NoError(t, err)
+
+	// Reloading is not supported without a callback
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reload", nil)
+	resp := httptest.NewRecorder()
+	api.handler().ServeHTTP(resp, req)
+	require.Equal(t, http.StatusNotImplemented, resp.Code)
+
+	var reloaded bool
+	a.ReloadRequested = func() { reloaded = true }
+	resp = httptest.NewRecorder()
+	api.handler().ServeHTTP(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	require.True(t, reloaded)
+}
+
+func TestControlAPIServe(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	api, err := newControlAPI(a, "tcp://127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, api.start())
+	defer api.stop()
+
+	resp, err := http.Get("http://" + api.listener.Addr().String() + "/api/v1/plugins")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
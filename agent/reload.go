@@ -0,0 +1,267 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/models"
+)
+
+// ErrRestartRequired is returned when configuration changes cannot be applied
+// to the running agent and a full restart is necessary.
+var ErrRestartRequired = errors.New("restart required")
+
+// ApplyConfig transitions the running agent to the given configuration by
+// starting added and stopping removed inputs and outputs while all other
+// plugins keep running. Changes to other parts of the configuration are
+// rejected with ErrRestartRequired and leave the agent untouched.
+func (a *Agent) ApplyConfig(ctx context.Context, cfg *config.Config) error {
+	a.unitsLock.Lock()
+	defer a.unitsLock.Unlock()
+
+	if a.inputs == nil || a.outputs == nil {
+		discardOutputs(cfg.Outputs)
+		return fmt.Errorf("%w: agent is not running", ErrRestartRequired)
+	}
+
+	diff := a.Config.Diff(cfg)
+	if diff.RequiresRestart() {
+		discardOutputs(cfg.Outputs)
+		return fmt.Errorf("%w: %s", ErrRestartRequired, strings.Join(diff.Incompatible, ", "))
+	}
+	discardOutputs(diff.UnchangedOutputs)
+
+	if diff.Empty() {
+		log.Printf("I! [agent] Configuration unchanged")
+		return nil
+	}
+
+	// Initialize and start the new plugins before touching the running ones
+	// so we can bail out without interrupting the pipeline.
+	outputs, err := a.prepareOutputs(ctx, diff.AddedOutputs)
+	if err != nil {
+		return err
+	}
+	inputs, err := a.prepareInputs(diff.AddedInputs)
+	if err != nil {
+		for _, output := range outputs {
+			output.Close()
+		}
+		return err
+	}
+
+	if a.Config.Persister != nil {
+		if err := a.updatePersister(diff); err != nil {
+			stopRunningInputs(inputs)
+			for _, output := range outputs {
+				output.Close()
+			}
+			return err
+		}
+	}
+
+	// Add the outputs first so the metrics of new inputs are not lost and
+	// remove outputs last so the metrics of removed inputs are still written.
+	for _, output := range outputs {
+		if !a.outputs.add(a, output) {
+			output.Close()
+		}
+	}
+	for _, input := range diff.RemovedInputs {
+		a.inputs.remove(input)
+	}
+	for _, input := range inputs {
+		if !a.inputs.add(a, input) {
+			input.Stop()
+		}
+	}
+	for _, output := range diff.RemovedOutputs {
+		a.outputs.remove(output)
+	}
+
+	a.Config.Inputs = slices.DeleteFunc(a.Config.Inputs, func(input *models.RunningInput) bool {
+		return slices.Contains(diff.RemovedInputs, input)
+	})
+	a.Config.Inputs = append(a.Config.Inputs, diff.AddedInputs...)
+	a.Config.Outputs = slices.DeleteFunc(a.Config.Outputs, func(output *models.RunningOutput) bool {
+		return slices.Contains(diff.RemovedOutputs, output)
+	})
+	a.Config.Outputs = append(a.Config.Outputs, diff.AddedOutputs...)
+
+	log.Printf("I! [agent] Applied configuration changes: %d input(s) added, %d input(s) removed, %d output(s) added, %d output(s) removed",
+		len(diff.AddedInputs), len(diff.RemovedInputs), len(diff.AddedOutputs), len(diff.RemovedOutputs))
+
+	return nil
+}
+
+// prepareInputs initializes and starts the given inputs and returns the ones
+// to add to the running agent.
+func (a *Agent) prepareInputs(inputs []*models.RunningInput) ([]*models.RunningInput, error) {
+	for _, input := range inputs {
+		// Share the snmp translator setting with plugins that need it.
+		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
+			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
+		}
+		if err := input.Init(); err != nil {
+			return nil, fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
+		}
+	}
+
+	started := make([]*models.RunningInput, 0, len(inputs))
+	for _, input := range inputs {
+		ok, err := startInput(a.inputs.dst, input)
+		if err != nil {
+			stopRunningInputs(started)
+			return nil, err
+		}
+		if ok {
+			started = append(started, input)
+		}
+	}
+	return started, nil
+}
+
+// prepareOutputs initializes and connects the given outputs and returns the
+// ones to add to the running agent.
+func (a *Agent) prepareOutputs(ctx context.Context, outputs []*models.RunningOutput) ([]*models.RunningOutput, error) {
+	for _, output := range outputs {
+		if err := output.Init(); err != nil {
+			discardOutputs(outputs)
+			return nil, fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
+		}
+	}
+
+	connected := make([]*models.RunningOutput, 0, len(outputs))
+	for i, output := range outputs {
+		if err := a.connectOutput(ctx, output); err != nil {
+			var fatalErr *internal.FatalError
+			if errors.As(err, &fatalErr) {
+				// If the model tells us to remove the plugin we do so without error
+				log.Printf("I! [agent] Failed to connect to [%s], error was %q;  shutting down plugin...", output.LogName(), err)
+				output.Close()
+				continue
+			}
+
+			for _, o := range connected {
+				o.Close()
+			}
+			discardOutputs(outputs[i:])
+			return nil, fmt.Errorf("connecting output %s: %w", output.LogName(), err)
+		}
+		connected = append(connected, output)
+	}
+	return connected, nil
+}
+
+// updatePersister unregisters the states of removed and registers the states
+// of added plugins.
+func (a *Agent) updatePersister(diff *config.Diff) error {
+	for _, input := range diff.RemovedInputs {
+		if _, ok := input.Input.(telegraf.StatefulPlugin); ok {
+			a.Config.Persister.Unregister(input.ID())
+		}
+	}
+	for _, output := range diff.RemovedOutputs {
+		if _, ok := output.Output.(telegraf.StatefulPlugin); ok {
+			a.Config.Persister.Unregister(output.ID())
+		}
+	}
+
+	for _, input := range diff.AddedInputs {
+		if plugin, ok := input.Input.(telegraf.StatefulPlugin); ok {
+			if err := a.Config.Persister.Register(input.ID(), plugin); err != nil {
+				return fmt.Errorf("could not register input %s: %w", input.LogName(), err)
+			}
+		}
+	}
+	for _, output := range diff.AddedOutputs {
+		if plugin, ok := output.Output.(telegraf.StatefulPlugin); ok {
+			if err := a.Config.Persister.Register(output.ID(), plugin); err != nil {
+				return fmt.Errorf("could not register output %s: %w", output.LogName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// add starts gathering the given input. It returns false if the unit is
+// already shutting down.
+func (unit *inputUnit) add(a *Agent, input *models.RunningInput) bool {
+	unit.Lock()
+	defer unit.Unlock()
+
+	if unit.closed || unit.loops == nil {
+		return false
+	}
+	unit.inputs = append(unit.inputs, input)
+	a.startGatherLoop(unit, input)
+	return true
+}
+
+// remove stops gathering the given input and waits for an ongoing gather to
+// complete before stopping the plugin.
+func (unit *inputUnit) remove(input *models.RunningInput) {
+	unit.Lock()
+	loop, found := unit.loops[input]
+	if !found || unit.closed {
+		unit.Unlock()
+		return
+	}
+	delete(unit.loops, input)
+	unit.inputs = slices.DeleteFunc(unit.inputs, func(i *models.RunningInput) bool { return i == input })
+	unit.Unlock()
+
+	loop.cancel()
+	<-loop.done
+	input.Stop()
+}
+
+// add starts writing metrics to the given output. It returns false if the
+// unit is already shutting down.
+func (unit *outputUnit) add(a *Agent, output *models.RunningOutput) bool {
+	unit.Lock()
+	defer unit.Unlock()
+
+	if unit.closed || unit.loops == nil {
+		return false
+	}
+	unit.outputs = append(unit.outputs, output)
+	setForwardOutputs(unit, output)
+	a.startFlushLoop(unit, output)
+	unit.updateReceivers()
+	return true
+}
+
+// remove stops sending metrics to the given output, flushes the buffered
+// metrics and closes the plugin.
+func (unit *outputUnit) remove(output *models.RunningOutput) {
+	unit.Lock()
+	loop, found := unit.loops[output]
+	if !found || unit.closed {
+		unit.Unlock()
+		return
+	}
+	delete(unit.loops, output)
+	unit.outputs = slices.DeleteFunc(unit.outputs, func(o *models.RunningOutput) bool { return o == output })
+	unit.updateReceivers()
+	unit.Unlock()
+
+	loop.cancel()
+	<-loop.done
+	output.Close()
+}
+
+// discardOutputs releases the resources of outputs that were never started.
+func discardOutputs(outputs []*models.RunningOutput) {
+	for _, output := range outputs {
+		output.Discard()
+	}
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestApplyConfigNotRunning(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	err := a.ApplyConfig(context.Background(), config.NewConfig())
+	require.ErrorIs(t, err, ErrRestartRequired)
+}
+
+func TestApplyConfigIncompatible(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	a.inputs = &inputUnit{}
+	a.outputs = &outputUnit{}
+
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[global_tags]
+  dc = "us-east-1"
+[[outputs.discard]]
+`), config.EmptySourcePath))
+
+	err := a.ApplyConfig(context.Background(), cfg)
+	require.ErrorIs(t, err, ErrRestartRequired)
+	require.ErrorContains(t, err, "global tags changed")
+	require.Empty(t, a.Config.Outputs)
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -81,6 +82,10 @@ type Telegraf struct {
 
 	cfg *config.Config
 
+	// runningAgent is the agent currently running in service mode, used to
+	// apply configuration changes without restarting
+	runningAgent atomic.Pointer[agent.Agent]
+
 	GlobalFlags
 	WindowFlags
 }
@@ -149,61 +154,52 @@ func (t *Telegraf) reloadLoop() error {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
-		if t.watchConfig != "" {
-			for _, fConfig := range t.configFiles {
-				if isURL(fConfig) {
-					continue
-				}
-
-				if _, err := os.Stat(fConfig); err != nil {
-					log.Printf("W! Cannot watch config %s: %s", fConfig, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfig)
-				}
-			}
-			for _, fConfigDirectory := range t.configDir {
-				if _, err := os.Stat(fConfigDirectory); err != nil {
-					log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfigDirectory)
-				}
-			}
-		}
-		if t.configURLWatchInterval > 0 {
-			remoteConfigs := make([]string, 0)
-			for _, fConfig := range t.configFiles {
-				if isURL(fConfig) {
-					remoteConfigs = append(remoteConfigs, fConfig)
-				}
-			}
-			if len(remoteConfigs) > 0 {
-				go t.watchRemoteConfigs(ctx, signals, t.configURLWatchInterval, remoteConfigs)
-			}
-		}
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Println("I! Reloading Telegraf config")
-					// May need to update the list of known config files
-					// if a delete or create occured. That way on the reload
-					// we ensure we watch the correct files.
-					if err := t.getConfigFiles(); err != nil {
-						log.Println("E! Error loading config files: ", err)
+			for {
+				// The watchers stop after reporting a change so we need to
+				// restart them if the change was applied to the running agent.
+				watchCtx, watchCancel := context.WithCancel(ctx)
+				t.watchConfigs(watchCtx, signals)
+
+				select {
+				case sig := <-signals:
+					watchCancel()
+					if sig == syscall.SIGHUP {
+						log.Println("I! Reloading Telegraf config")
+						// May need to update the list of known config files
+						// if a delete or create occured. That way on the reload
+						// we ensure we watch the correct files.
+						if err := t.getConfigFiles(); err != nil {
+							log.Println("E! Error loading config files: ", err)
+						}
+						if t.applyConfig(ctx) {
+							continue
+						}
+						<-reload
+						reload <- true
 					}
-					<-reload
-					reload <- true
+					cancel()
+				case err := <-t.pprofErr:
+					watchCancel()
+					log.Printf("E! pprof server failed: %v", err)
+					cancel()
+				case <-stop:
+					watchCancel()
+					cancel()
 				}
-				cancel()
-			case err := <-t.pprofErr:
-				log.Printf("E! pprof server failed: %v", err)
-				cancel()
-			case <-stop:
-				cancel()
+				return
 			}
 		}()
 
-		err := t.runAgent(ctx, reloadConfig)
+		// Allow to request a reload e.g. via the control API of the agent
+		requestReload := func() {
+			select {
+			case signals <- syscall.SIGHUP:
+			default:
+			}
+		}
+
+		err := t.runAgent(ctx, reloadConfig, requestReload)
 		if err != nil && !errors.Is(err, context.Canceled) {
 			return fmt.Errorf("[telegraf] Error running agent: %w", err)
 		}
@@ -213,6 +209,71 @@ func (t *Telegraf) reloadLoop() error {
 	return nil
 }
 
+// watchConfigs starts watching the configuration files and URLs for changes
+// if enabled. A change is reported as SIGHUP on the given channel.
+func (t *Telegraf) watchConfigs(ctx context.Context, signals chan os.Signal) {
+	if t.watchConfig != "" {
+		for _, fConfig := range t.configFiles {
+			if isURL(fConfig) {
+				continue
+			}
+
+			if _, err := os.Stat(fConfig); err != nil {
+				log.Printf("W! Cannot watch config %s: %s", fConfig, err)
+			} else {
+				go t.watchLocalConfig(ctx, signals, fConfig)
+			}
+		}
+		for _, fConfigDirectory := range t.configDir {
+			if _, err := os.Stat(fConfigDirectory); err != nil {
+				log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
+			} else {
+				go t.watchLocalConfig(ctx, signals, fConfigDirectory)
+			}
+		}
+	}
+	if t.configURLWatchInterval > 0 {
+		remoteConfigs := make([]string, 0)
+		for _, fConfig := range t.configFiles {
+			if isURL(fConfig) {
+				remoteConfigs = append(remoteConfigs, fConfig)
+			}
+		}
+		if len(remoteConfigs) > 0 {
+			go t.watchRemoteConfigs(ctx, signals, t.configURLWatchInterval, remoteConfigs)
+		}
+	}
+}
+
+// applyConfig loads the configuration and applies the changes to the running
+// agent by only starting and stopping the affected plugins. It returns false
+// if the agent needs to be restarted instead.
+func (t *Telegraf) applyConfig(ctx context.Context) bool {
+	ag := t.runningAgent.Load()
+	if ag == nil {
+		return false
+	}
+
+	c, err := t.loadConfiguration()
+	if err != nil {
+		log.Printf("E! Loading config failed, restarting agent: %v", err)
+		return false
+	}
+	if len(c.Inputs) == 0 || len(c.Outputs) == 0 {
+		return false
+	}
+
+	if err := ag.ApplyConfig(ctx, c); err != nil {
+		if errors.Is(err, agent.ErrRestartRequired) {
+			log.Printf("I! Restarting agent: %v", err)
+		} else {
+			log.Printf("E! Applying config changes failed, restarting agent: %v", err)
+		}
+		return false
+	}
+	return true
+}
+
 func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal, fConfig string) {
 	var mytomb tomb.Tomb
 	var watcher watch.FileWatcher
@@ -352,7 +413,7 @@ func (t *Telegraf) getConfigFiles() error {
 	return nil
 }
 
-func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
+func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool, requestReload func()) error {
 	c := t.cfg
 	var err error
 	if reloadConfig {
@@ -444,6 +505,7 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		}
 	}
 	ag := agent.NewAgent(c)
+	ag.ReloadRequested = requestReload
 
 	// Notify systemd that telegraf is ready
 	// SdNotify() only tries to notify if the NOTIFY_SOCKET environment is set, so it's safe to call when systemd isn't present.
@@ -483,6 +545,9 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		}
 	}
 
+	t.runningAgent.Store(ag)
+	defer t.runningAgent.Store(nil)
+
 	return ag.Run(ctx)
 }
 
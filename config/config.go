@@ -92,6 +92,9 @@ type Config struct {
 	fileProcessors    OrderedPlugins
 	fileAggProcessors OrderedPlugins
 
+	// Routes sending metrics exclusively to one output in order of appearance
+	Routes []*models.Route
+
 	// Parsers are created by their inputs during gather. Config doesn't keep track of them
 	// like the other plugins because they need to be garbage collected (See issue #11809)
 
@@ -275,6 +278,11 @@ type AgentConfig struct {
 	// the state in the file will be restored for the plugins.
 	Statefile string `toml:"statefile"`
 
+	// Interval for periodically storing the state of plugins to the statefile
+	// in addition to storing it on termination. By default, the state is only
+	// stored on termination of Telegraf.
+	StatefileInterval Duration `toml:"statefile_interval"`
+
 	// Flag to always keep tags explicitly defined in the plugin itself and
 	// ensure those tags always pass filtering.
 	AlwaysIncludeLocalTags bool `toml:"always_include_local_tags"`
@@ -293,12 +301,31 @@ type AgentConfig struct {
 	ConfigURLRetryAttempts int `toml:"config_url_retry_attempts"`
 
 	// BufferStrategy is the metric buffer type to use for a given output plugin.
-	// Supported types currently are "memory" and "disk".
+	// Supported types currently are "memory", "disk" and "overflow".
 	BufferStrategy string `toml:"buffer_strategy"`
 
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// BufferDiskMaxSize is the maximum size of the stored metrics per output
+	// when using the "disk" buffer strategy. The oldest metrics are dropped
+	// when exceeding the size. Zero means unlimited.
+	BufferDiskMaxSize Size `toml:"buffer_disk_max_size"`
+
+	// BufferDiskCompression is the algorithm used to compress the metrics
+	// stored by the "disk" buffer strategy. Supported are "zstd" and "snappy".
+	BufferDiskCompression string `toml:"buffer_disk_compression"`
+
+	// BufferOverflowWatermark is the fraction of the metric buffer limit
+	// after which metrics are spilled to disk when using the "overflow"
+	// buffer strategy.
+	BufferOverflowWatermark float64 `toml:"buffer_overflow_watermark"`
+
+	// ControlAPIAddress is the address to serve the local control API on.
+	// Supported are "tcp" and "unix" addresses, e.g. "tcp://127.0.0.1:8099"
+	// or "unix:///var/run/telegraf/control.sock". The API is disabled if empty.
+	ControlAPIAddress string `toml:"control_api_address"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -564,6 +591,13 @@ func (c *Config) LoadAll(configFiles ...string) error {
 	sort.Stable(c.Processors)
 	sort.Stable(c.AggProcessors)
 
+	if err := c.checkDeadLetterOutputs(); err != nil {
+		return err
+	}
+	if err := c.checkRoutes(); err != nil {
+		return err
+	}
+
 	// Set snmp agent translator default
 	if c.Agent.SnmpTranslator == "" {
 		c.Agent.SnmpTranslator = "netsnmp"
@@ -657,6 +691,7 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 	if c.Agent.Statefile != "" {
 		c.Persister = &persister.Persister{
 			Filename: c.Agent.Statefile,
+			Interval: time.Duration(c.Agent.StatefileInterval),
 		}
 	}
 
@@ -673,6 +708,19 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 
 	// Parse all the rest of the plugins:
 	for name, val := range tbl.Fields {
+		if name == "routes" {
+			routeTables, ok := val.([]*ast.Table)
+			if !ok {
+				return errors.New("invalid configuration, routes must be specified as [[routes]]")
+			}
+			for _, t := range routeTables {
+				if err := c.addRoute(t); err != nil {
+					return fmt.Errorf("error parsing route at line %d: %w", t.Line, err)
+				}
+			}
+			continue
+		}
+
 		subTable, ok := val.(*ast.Table)
 		if !ok {
 			return fmt.Errorf("invalid configuration, error parsing field %q as table", name)
@@ -1455,6 +1503,12 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 	if grace, found := c.getFieldDuration(tbl, "grace"); found {
 		conf.Grace = grace
 	}
+	if window, found := c.getFieldDuration(tbl, "window"); found {
+		if window != 0 && window < conf.Period {
+			return nil, fmt.Errorf("window %s of aggregator %s must not be shorter than the period %s", window, name, conf.Period)
+		}
+		conf.Window = window
+	}
 
 	conf.DropOriginal = c.getFieldBool(tbl, "drop_original")
 	conf.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
@@ -1653,11 +1707,14 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 		return nil, err
 	}
 	oc := &models.OutputConfig{
-		Name:            name,
-		Source:          source,
-		Filter:          filter,
-		BufferStrategy:  c.Agent.BufferStrategy,
-		BufferDirectory: c.Agent.BufferDirectory,
+		Name:                    name,
+		Source:                  source,
+		Filter:                  filter,
+		BufferStrategy:          c.Agent.BufferStrategy,
+		BufferDirectory:         c.Agent.BufferDirectory,
+		BufferDiskMaxSize:       int64(c.Agent.BufferDiskMaxSize),
+		BufferDiskCompression:   c.Agent.BufferDiskCompression,
+		BufferOverflowWatermark: c.Agent.BufferOverflowWatermark,
 	}
 
 	// TODO: support FieldPass/FieldDrop on outputs
@@ -1666,6 +1723,7 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.FlushJitter, _ = c.getFieldDuration(tbl, "flush_jitter")
 	oc.MetricBufferLimit = c.getFieldInt(tbl, "metric_buffer_limit")
 	oc.MetricBatchSize = c.getFieldInt(tbl, "metric_batch_size")
+	oc.MaxInflightBatches = c.getFieldInt(tbl, "max_inflight_batches")
 	oc.Alias = c.getFieldString(tbl, "alias")
 	oc.NameOverride = c.getFieldString(tbl, "name_override")
 	oc.NameSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1673,12 +1731,34 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
 
+	oc.Retry.InitialInterval, _ = c.getFieldDuration(tbl, "retry_initial_interval")
+	oc.Retry.MaxInterval, _ = c.getFieldDuration(tbl, "retry_max_interval")
+	oc.Retry.Jitter, _ = c.getFieldDuration(tbl, "retry_jitter")
+	oc.Retry.MaxAttempts = c.getFieldInt(tbl, "retry_max_attempts")
+	oc.Retry.MaxAge, _ = c.getFieldDuration(tbl, "retry_max_age")
+	oc.Retry.CircuitBreakerThreshold = c.getFieldInt(tbl, "circuit_breaker_threshold")
+	oc.Retry.CircuitBreakerTimeout, _ = c.getFieldDuration(tbl, "circuit_breaker_timeout")
+
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
 
-	if oc.BufferStrategy == "disk" {
-		log.Printf("W! Using disk buffer strategy for plugin outputs.%s, this is an experimental feature", name)
+	if node, found := tbl.Fields["dead_letter"]; found {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("invalid dead_letter setting for outputs.%s, expected a table", name)
+		}
+		oc.DeadLetter, err = c.buildDeadLetter(name, subtbl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dead_letter setting for outputs.%s: %w", name, err)
+		}
+	}
+
+	if err := oc.ValidateBuffer(); err != nil {
+		return nil, fmt.Errorf("invalid buffer setting for outputs.%s: %w", name, err)
+	}
+	if oc.BufferStrategy == "disk" || oc.BufferStrategy == "overflow" {
+		log.Printf("W! Using %s buffer strategy for plugin outputs.%s, this is an experimental feature", oc.BufferStrategy, name)
 	}
 
 	// Generate an ID for the plugin
@@ -1686,22 +1766,184 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	return oc, err
 }
 
+// buildDeadLetter parses the dead-letter sink settings of an output. All
+// options except "file" and "output" are passed to the serializer used for
+// writing the file.
+func (c *Config) buildDeadLetter(name string, tbl *ast.Table) (*models.DeadLetterConfig, error) {
+	dl := &models.DeadLetterConfig{
+		File:   c.getFieldString(tbl, "file"),
+		Output: c.getFieldString(tbl, "output"),
+	}
+	if c.hasErrs() {
+		return nil, c.firstErr()
+	}
+
+	switch {
+	case dl.File == "" && dl.Output == "":
+		return nil, errors.New("either 'file' or 'output' must be set")
+	case dl.File != "" && dl.Output != "":
+		return nil, errors.New("'file' and 'output' are mutually exclusive")
+	case dl.Output != "":
+		return dl, nil
+	}
+
+	stbl := &ast.Table{
+		Position: tbl.Position,
+		Line:     tbl.Line,
+		Name:     tbl.Name,
+		Fields:   make(map[string]interface{}, len(tbl.Fields)),
+		Type:     tbl.Type,
+		Data:     tbl.Data,
+	}
+	for key, value := range tbl.Fields {
+		if key != "file" && key != "output" {
+			stbl.Fields[key] = value
+		}
+	}
+	serializer, err := c.addSerializer(name+"::dead_letter", stbl)
+	if err != nil {
+		return nil, err
+	}
+	dl.Serializer = serializer
+
+	return dl, nil
+}
+
+// checkDeadLetterOutputs makes sure outputs forwarding rejected metrics refer
+// to exactly one other output not forwarding metrics itself to avoid loops.
+func (c *Config) checkDeadLetterOutputs() error {
+	for _, output := range c.Outputs {
+		if output.Config.DeadLetter == nil || output.Config.DeadLetter.Output == "" {
+			continue
+		}
+		target := output.Config.DeadLetter.Output
+
+		var found []*models.RunningOutput
+		for _, candidate := range c.Outputs {
+			if candidate.HasName(target) {
+				found = append(found, candidate)
+			}
+		}
+		switch {
+		case len(found) == 0:
+			return fmt.Errorf("dead-letter output %q of %s not found", target, output.LogName())
+		case len(found) > 1:
+			return fmt.Errorf("dead-letter output %q of %s is ambiguous, please use an alias", target, output.LogName())
+		case found[0] == output:
+			return fmt.Errorf("dead-letter output of %s must not refer to itself", output.LogName())
+		case found[0].Config.DeadLetter != nil && found[0].Config.DeadLetter.Output != "":
+			return fmt.Errorf("dead-letter output %q of %s must not forward metrics itself", target, output.LogName())
+		}
+	}
+	return nil
+}
+
+// addRoute parses a route consisting of the metric selection settings, the
+// output to send the selected metrics to and an optional fallback output.
+func (c *Config) addRoute(tbl *ast.Table) error {
+	for key := range tbl.Fields {
+		switch key {
+		case "output", "fallback", "fallback_after",
+			"namepass", "namepass_separator", "namedrop", "namedrop_separator",
+			"tagpass", "tagdrop", "metricpass":
+		default:
+			return fmt.Errorf("unknown setting %q", key)
+		}
+	}
+
+	filter, err := c.buildFilter("routes", tbl)
+	if err != nil {
+		return err
+	}
+	route := &models.Route{
+		Filter:   filter,
+		Output:   c.getFieldString(tbl, "output"),
+		Fallback: c.getFieldString(tbl, "fallback"),
+	}
+	route.FallbackAfter, _ = c.getFieldDuration(tbl, "fallback_after")
+	if c.hasErrs() {
+		return c.firstErr()
+	}
+
+	if route.Output == "" {
+		return errors.New("missing output")
+	}
+	if route.Fallback == "" && route.FallbackAfter > 0 {
+		return errors.New("fallback_after requires a fallback output")
+	}
+
+	route.ID, err = generatePluginID("routes", tbl)
+	if err != nil {
+		return err
+	}
+	c.Routes = append(c.Routes, route)
+	return nil
+}
+
+// checkRoutes makes sure the outputs referred to by routes exist and
+// configures the fallback of the route outputs.
+func (c *Config) checkRoutes() error {
+	lookup := func(name string) (*models.RunningOutput, error) {
+		var found []*models.RunningOutput
+		for _, output := range c.Outputs {
+			if output.HasName(name) {
+				found = append(found, output)
+			}
+		}
+		switch len(found) {
+		case 0:
+			return nil, fmt.Errorf("output %q of route not found", name)
+		case 1:
+			return found[0], nil
+		}
+		return nil, fmt.Errorf("output %q of route is ambiguous, please use an alias", name)
+	}
+
+	for _, route := range c.Routes {
+		output, err := lookup(route.Output)
+		if err != nil {
+			return err
+		}
+		if route.Fallback == "" {
+			continue
+		}
+		fallback, err := lookup(route.Fallback)
+		if err != nil {
+			return err
+		}
+		if fallback == output {
+			return fmt.Errorf("fallback of route to %q must not refer to the same output", route.Output)
+		}
+
+		cfg := &models.FallbackConfig{Output: route.Fallback, After: route.FallbackAfter}
+		if output.Config.Fallback != nil && *output.Config.Fallback != *cfg {
+			return fmt.Errorf("routes to %q specify different fallbacks", route.Output)
+		}
+		output.Config.Fallback = cfg
+	}
+	return nil
+}
+
 func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
 	// General options to ignore
 	case "alias", "always_include_local_tags",
-		"buffer_strategy", "buffer_directory",
+		"buffer_strategy", "buffer_directory", "buffer_disk_max_size", "buffer_disk_compression",
+		"buffer_overflow_watermark",
 		"collection_jitter", "collection_offset",
-		"data_format", "delay", "drop", "drop_original",
+		"data_format", "dead_letter", "delay", "drop", "drop_original",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"max_inflight_batches", "metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
 		"pass", "period", "precision",
-		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
+		"retry_initial_interval", "retry_max_interval", "retry_jitter", "retry_max_attempts", "retry_max_age",
+		"circuit_breaker_threshold", "circuit_breaker_timeout",
+		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior",
+		"window":
 
 	// Secret-store options to ignore
 	case "id":
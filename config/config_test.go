@@ -1049,6 +1049,174 @@ func TestConfig_ProcessorsWithParsers(t *testing.T) {
 	}
 }
 
+func TestConfig_DeadLetter(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/dead_letter.toml"))
+	require.Len(t, c.Outputs, 3)
+
+	dl := findOutput(t, c.Outputs, "http://localhost:8080").Config.DeadLetter
+	require.NotNil(t, dl)
+	require.Equal(t, "/tmp/rejected.json", dl.File)
+	require.Empty(t, dl.Output)
+	serializer, ok := dl.Serializer.(*models.RunningSerializer)
+	require.True(t, ok)
+	require.Equal(t, "json", serializer.Config.DataFormat)
+
+	dl = findOutput(t, c.Outputs, "http://localhost:8081").Config.DeadLetter
+	require.NotNil(t, dl)
+	require.Empty(t, dl.File)
+	require.Equal(t, "rejected", dl.Output)
+
+	require.Nil(t, findOutputByName(t, c.Outputs, "rejected").Config.DeadLetter)
+}
+
+// findOutputByName returns the output with the given alias or plugin name
+func findOutputByName(t *testing.T, outputs []*models.RunningOutput, name string) *models.RunningOutput {
+	t.Helper()
+	for _, output := range outputs {
+		if output.HasName(name) {
+			return output
+		}
+	}
+	require.Failf(t, "output not found", "no output with name %q", name)
+	return nil
+}
+
+func TestConfig_DeadLetterInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/dead_letter_loop.toml"), "must not forward metrics itself")
+
+	c = config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[outputs.http]]
+  url = "http://localhost:8080"
+  [outputs.http.dead_letter]
+    data_format = "json"
+`), config.EmptySourcePath)
+	require.ErrorContains(t, err, "either 'file' or 'output' must be set")
+}
+
+func TestConfig_BufferInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		agent    string
+		expected string
+	}{
+		{
+			name:     "strategy",
+			agent:    `buffer_strategy = "file"`,
+			expected: `invalid buffer strategy "file"`,
+		},
+		{
+			name:     "compression",
+			agent:    "buffer_strategy = \"disk\"\nbuffer_disk_compression = \"lz4\"",
+			expected: `invalid buffer compression "lz4"`,
+		},
+		{
+			name:     "watermark",
+			agent:    "buffer_strategy = \"overflow\"\nbuffer_overflow_watermark = 1.5",
+			expected: "invalid overflow watermark 1.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			err := c.LoadConfigData([]byte("[agent]\n"+tt.agent+"\n[[outputs.http]]\n"), config.EmptySourcePath)
+			require.ErrorContains(t, err, "invalid buffer setting for outputs.http")
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestConfig_Routes(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/routes.toml"))
+	require.Len(t, c.Outputs, 3)
+	require.Len(t, c.Routes, 2)
+
+	require.Equal(t, "eu", c.Routes[0].Output)
+	require.Equal(t, "backup", c.Routes[0].Fallback)
+	require.Equal(t, 5*time.Minute, c.Routes[0].FallbackAfter)
+	require.Equal(t, "default", c.Routes[1].Output)
+	require.NotEqual(t, c.Routes[0].ID, c.Routes[1].ID)
+
+	m := metric.New("cpu", map[string]string{"region": "eu-west"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	ok, err := c.Routes[0].Match(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+	m = metric.New("cpu", map[string]string{"region": "us-east"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	ok, err = c.Routes[0].Match(m)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// The fallback must be set for the route output
+	require.Equal(t, &models.FallbackConfig{Output: "backup", After: 5 * time.Minute}, findOutputByName(t, c.Outputs, "eu").Config.Fallback)
+	require.Nil(t, findOutputByName(t, c.Outputs, "default").Config.Fallback)
+}
+
+func TestConfig_RoutesInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		expected string
+	}{
+		{
+			name: "missing output",
+			cfg: `
+[[outputs.http]]
+  url = "http://localhost:8080"
+[[routes]]
+  namepass = ["cpu"]
+`,
+			expected: "missing output",
+		},
+		{
+			name: "unknown output",
+			cfg: `
+[[outputs.http]]
+  url = "http://localhost:8080"
+[[routes]]
+  output = "foo"
+`,
+			expected: "output \"foo\" of route not found",
+		},
+		{
+			name: "fallback to itself",
+			cfg: `
+[[outputs.http]]
+  url = "http://localhost:8080"
+[[routes]]
+  output = "http"
+  fallback = "http"
+`,
+			expected: "must not refer to the same output",
+		},
+		{
+			name: "unknown setting",
+			cfg: `
+[[outputs.http]]
+  url = "http://localhost:8080"
+[[routes]]
+  output = "http"
+  fieldinclude = ["value"]
+`,
+			expected: "unknown setting \"fieldinclude\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fn := filepath.Join(dir, "telegraf.conf")
+			require.NoError(t, os.WriteFile(fn, []byte(tt.cfg), 0600))
+
+			c := config.NewConfig()
+			require.ErrorContains(t, c.LoadAll(fn), tt.expected)
+		})
+	}
+}
+
 func TestConfigPluginIDsDifferent(t *testing.T) {
 	c := config.NewConfig()
 	c.Agent.Statefile = "/dev/null"
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// Diff contains the changes between two configurations keyed by the plugin
+// IDs. As the ID is derived from the plugin's configuration, a plugin with
+// modified settings is reported as removed with its old and added with its new
+// settings.
+type Diff struct {
+	AddedInputs    []*models.RunningInput
+	RemovedInputs  []*models.RunningInput
+	AddedOutputs   []*models.RunningOutput
+	RemovedOutputs []*models.RunningOutput
+
+	// UnchangedOutputs contains the outputs of the updated configuration that
+	// are identical to an already running output and thus not used.
+	UnchangedOutputs []*models.RunningOutput
+
+	// Incompatible lists the changes that cannot be applied without restarting
+	// the whole processing pipeline.
+	Incompatible []string
+}
+
+// Empty returns true if there are no changes between the configurations.
+func (d *Diff) Empty() bool {
+	return len(d.AddedInputs) == 0 && len(d.RemovedInputs) == 0 &&
+		len(d.AddedOutputs) == 0 && len(d.RemovedOutputs) == 0 &&
+		len(d.Incompatible) == 0
+}
+
+// RequiresRestart returns true if the changes cannot be applied to running
+// plugins individually.
+func (d *Diff) RequiresRestart() bool {
+	return len(d.Incompatible) > 0
+}
+
+// Diff compares the configuration to the updated one and returns the plugins
+// that need to be started or stopped to transition to the updated state.
+// Changes to the agent settings, global tags, processors, aggregators or
+// routes are reported as incompatible.
+func (c *Config) Diff(updated *Config) *Diff {
+	d := &Diff{}
+
+	if !equalAgentConfig(c.Agent, updated.Agent) {
+		d.Incompatible = append(d.Incompatible, "agent settings changed")
+	}
+	if !reflect.DeepEqual(c.Tags, updated.Tags) {
+		d.Incompatible = append(d.Incompatible, "global tags changed")
+	}
+
+	processorIDs := func(processors models.RunningProcessors) []string {
+		ids := make([]string, 0, len(processors))
+		for _, p := range processors {
+			ids = append(ids, p.ID())
+		}
+		return ids
+	}
+	// The processor order is relevant as they form a chain
+	if !slices.Equal(processorIDs(c.Processors), processorIDs(updated.Processors)) ||
+		!slices.Equal(processorIDs(c.AggProcessors), processorIDs(updated.AggProcessors)) {
+		d.Incompatible = append(d.Incompatible, "processors changed")
+	}
+
+	// Routes are evaluated in order
+	routeIDs := func(routes []*models.Route) []string {
+		ids := make([]string, 0, len(routes))
+		for _, r := range routes {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	if !slices.Equal(routeIDs(c.Routes), routeIDs(updated.Routes)) {
+		d.Incompatible = append(d.Incompatible, "routes changed")
+	}
+
+	aggregatorIDs := func(aggregators []*models.RunningAggregator) []string {
+		ids := make([]string, 0, len(aggregators))
+		for _, a := range aggregators {
+			ids = append(ids, a.ID())
+		}
+		return ids
+	}
+	if !equalIgnoringOrder(aggregatorIDs(c.Aggregators), aggregatorIDs(updated.Aggregators)) {
+		d.Incompatible = append(d.Incompatible, "aggregators changed")
+	}
+
+	// Match the inputs by ID. Identically configured plugins share the same ID
+	// so we need to count the instances to detect added or removed duplicates.
+	inputs := make(map[string][]*models.RunningInput, len(c.Inputs))
+	for _, input := range c.Inputs {
+		inputs[input.ID()] = append(inputs[input.ID()], input)
+	}
+	for _, input := range updated.Inputs {
+		id := input.ID()
+		if len(inputs[id]) == 0 {
+			d.AddedInputs = append(d.AddedInputs, input)
+			continue
+		}
+		inputs[id] = inputs[id][1:]
+	}
+	for _, input := range c.Inputs {
+		if remaining := inputs[input.ID()]; len(remaining) > 0 && remaining[0] == input {
+			d.RemovedInputs = append(d.RemovedInputs, input)
+			inputs[input.ID()] = remaining[1:]
+		}
+	}
+
+	// Do the same for the outputs but keep track of the unused instances
+	outputs := make(map[string][]*models.RunningOutput, len(c.Outputs))
+	for _, output := range c.Outputs {
+		outputs[output.ID()] = append(outputs[output.ID()], output)
+	}
+	for _, output := range updated.Outputs {
+		id := output.ID()
+		if len(outputs[id]) == 0 {
+			d.AddedOutputs = append(d.AddedOutputs, output)
+			continue
+		}
+		outputs[id] = outputs[id][1:]
+		d.UnchangedOutputs = append(d.UnchangedOutputs, output)
+	}
+	for _, output := range c.Outputs {
+		if remaining := outputs[output.ID()]; len(remaining) > 0 && remaining[0] == output {
+			d.RemovedOutputs = append(d.RemovedOutputs, output)
+			outputs[output.ID()] = remaining[1:]
+		}
+	}
+
+	return d
+}
+
+// equalAgentConfig compares the agent settings ignoring defaults filled in by
+// the running agent.
+func equalAgentConfig(a, b *AgentConfig) bool {
+	ca, cb := *a, *b
+	skipA := ca.SkipProcessorsAfterAggregators != nil && *ca.SkipProcessorsAfterAggregators
+	skipB := cb.SkipProcessorsAfterAggregators != nil && *cb.SkipProcessorsAfterAggregators
+	ca.SkipProcessorsAfterAggregators, cb.SkipProcessorsAfterAggregators = nil, nil
+	return skipA == skipB && reflect.DeepEqual(ca, cb)
+}
+
+// equalIgnoringOrder compares the given plugin IDs ignoring the order.
+func equalIgnoringOrder(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sa, sb := slices.Clone(a), slices.Clone(b)
+	slices.Sort(sa)
+	slices.Sort(sb)
+	return slices.Equal(sa, sb)
+}
@@ -0,0 +1,167 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestDiff(t *testing.T) {
+	current := config.NewConfig()
+	require.NoError(t, current.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  servers = ["localhost"]
+[[inputs.memcached]]
+  servers = ["localhost"]
+[[inputs.procstat]]
+  pid_file = "/var/run/grafana-server.pid"
+[[outputs.http]]
+  url = "http://localhost:8080"
+[[outputs.http]]
+  url = "http://localhost:8081"
+`), config.EmptySourcePath))
+
+	updated := config.NewConfig()
+	require.NoError(t, updated.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  servers = ["localhost"]
+[[inputs.procstat]]
+  pid_file = "/var/run/telegraf.pid"
+[[outputs.http]]
+  url = "http://localhost:8080"
+[[outputs.http]]
+  url = "http://localhost:8082"
+`), config.EmptySourcePath))
+
+	diff := current.Diff(updated)
+	require.False(t, diff.Empty())
+	require.False(t, diff.RequiresRestart())
+
+	// The duplicate memcached input and the modified procstat input are removed
+	currentMemcached := findInputs(current.Inputs, "memcached")
+	require.Len(t, currentMemcached, 2)
+	currentProcstat := findInputs(current.Inputs, "procstat")
+	require.Len(t, currentProcstat, 1)
+	require.ElementsMatch(t, []*models.RunningInput{currentMemcached[1], currentProcstat[0]}, diff.RemovedInputs)
+	updatedProcstat := findInputs(updated.Inputs, "procstat")
+	require.Len(t, updatedProcstat, 1)
+	require.Len(t, diff.AddedInputs, 1)
+	require.Same(t, updatedProcstat[0], diff.AddedInputs[0])
+
+	// The output with a modified URL is replaced, the other one is unchanged
+	require.Len(t, diff.RemovedOutputs, 1)
+	require.Same(t, findOutput(t, current.Outputs, "http://localhost:8081"), diff.RemovedOutputs[0])
+	require.Len(t, diff.AddedOutputs, 1)
+	require.Same(t, findOutput(t, updated.Outputs, "http://localhost:8082"), diff.AddedOutputs[0])
+	require.Len(t, diff.UnchangedOutputs, 1)
+	require.Same(t, findOutput(t, updated.Outputs, "http://localhost:8080"), diff.UnchangedOutputs[0])
+}
+
+// findInputs returns the inputs with the given plugin name keeping their order
+// as the loader does not guarantee the order of different plugins
+func findInputs(inputs []*models.RunningInput, name string) []*models.RunningInput {
+	var found []*models.RunningInput
+	for _, input := range inputs {
+		if input.Config.Name == name {
+			found = append(found, input)
+		}
+	}
+	return found
+}
+
+// findOutput returns the output with the given URL
+func findOutput(t *testing.T, outputs []*models.RunningOutput, url string) *models.RunningOutput {
+	t.Helper()
+	for _, output := range outputs {
+		if plugin, ok := output.Output.(*MockupOutputPlugin); ok && plugin.URL == url {
+			return output
+		}
+	}
+	require.Failf(t, "output not found", "no output with URL %q", url)
+	return nil
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	cfg := []byte(`
+[global_tags]
+  dc = "us-east-1"
+[[inputs.memcached]]
+  servers = ["localhost"]
+[[outputs.http]]
+  url = "http://localhost:8080"
+`)
+	current := config.NewConfig()
+	require.NoError(t, current.LoadConfigData(cfg, config.EmptySourcePath))
+	skip := false
+	current.Agent.SkipProcessorsAfterAggregators = &skip
+
+	updated := config.NewConfig()
+	require.NoError(t, updated.LoadConfigData(cfg, config.EmptySourcePath))
+
+	diff := current.Diff(updated)
+	require.True(t, diff.Empty())
+	require.Len(t, diff.UnchangedOutputs, 1)
+}
+
+func TestDiffRequiresRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		updated  string
+		expected []string
+	}{
+		{
+			name: "agent settings",
+			updated: `
+[agent]
+  interval = "1s"
+`,
+			expected: []string{"agent settings changed"},
+		},
+		{
+			name: "global tags",
+			updated: `
+[global_tags]
+  dc = "us-west-1"
+`,
+			expected: []string{"global tags changed"},
+		},
+		{
+			name: "processors",
+			updated: `
+[[processors.processor]]
+`,
+			expected: []string{"processors changed"},
+		},
+		{
+			name: "routes",
+			updated: `
+[[routes]]
+  output = "http"
+`,
+			expected: []string{"routes changed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := config.NewConfig()
+			require.NoError(t, current.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  servers = ["localhost"]
+`), config.EmptySourcePath))
+
+			updated := config.NewConfig()
+			require.NoError(t, updated.LoadConfigData([]byte(tt.updated+`
+[[inputs.memcached]]
+  servers = ["localhost"]
+`), config.EmptySourcePath))
+
+			diff := current.Diff(updated)
+			require.True(t, diff.RequiresRestart())
+			require.Equal(t, tt.expected, diff.Incompatible)
+		})
+	}
+}
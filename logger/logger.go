@@ -36,6 +36,10 @@ type logger struct {
 	prefix     string
 	onError    []func()
 	attributes map[string]interface{}
+
+	lastError     string
+	lastErrorTime time.Time
+	lastErrorLock sync.Mutex
 }
 
 // New creates a new logging instance to be used in models
@@ -94,7 +98,14 @@ func (l *logger) Errorf(format string, args ...interface{}) {
 }
 
 func (l *logger) Error(args ...interface{}) {
-	l.Print(telegraf.Error, time.Now(), args...)
+	ts := time.Now()
+	l.Print(telegraf.Error, ts, args...)
+
+	l.lastErrorLock.Lock()
+	l.lastError = fmt.Sprint(args...)
+	l.lastErrorTime = ts
+	l.lastErrorLock.Unlock()
+
 	for _, f := range l.onError {
 		f()
 	}
@@ -177,6 +188,14 @@ func (l *logger) RegisterErrorCallback(f func()) {
 	l.onError = append(l.onError, f)
 }
 
+// LastError returns the last error message logged and the time it occurred.
+// The message is empty if no error was logged so far.
+func (l *logger) LastError() (string, time.Time) {
+	l.lastErrorLock.Lock()
+	defer l.lastErrorLock.Unlock()
+	return l.lastError, l.lastErrorTime
+}
+
 type Config struct {
 	// will set the log level to DEBUG
 	Debug bool
@@ -37,3 +37,17 @@ func TestErrorCounting(t *testing.T) {
 
 	require.Equal(t, int64(2), reg.Get())
 }
+
+func TestLastError(t *testing.T) {
+	iLog := New("inputs", "test", "")
+	msg, ts := iLog.LastError()
+	require.Empty(t, msg)
+	require.True(t, ts.IsZero())
+
+	iLog.Error("something went wrong")
+	iLog.Errorf("%s went wrong again", "something")
+
+	msg, ts = iLog.LastError()
+	require.Equal(t, "something went wrong again", msg)
+	require.False(t, ts.IsZero())
+}
@@ -71,6 +71,8 @@ type Buffer interface {
 	// Batch starts a transaction by returning a slice of metrics up to the
 	// given batch-size starting from the oldest metric in the buffer. Metrics
 	// are ordered from oldest to newest and must not be modified by the plugin.
+	// Multiple transactions might be outstanding at the same time, each
+	// containing metrics not being part of any other outstanding transaction.
 	BeginTransaction(batchSize int) *Transaction
 
 	// Flush ends a metric and persists the buffer state
@@ -95,7 +97,7 @@ type BufferStats struct {
 }
 
 // NewBuffer returns a new empty Buffer with the given capacity.
-func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buffer, error) {
+func NewBuffer(name, id, alias string, capacity int, strategy string, disk DiskBufferConfig) (Buffer, error) {
 	registerGob()
 
 	bs := NewBufferStats(name, alias, capacity)
@@ -104,11 +106,38 @@ func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buf
 	case "", "memory":
 		return NewMemoryBuffer(capacity, bs)
 	case "disk":
-		return NewDiskBuffer(name, id, path, bs)
+		return NewDiskBuffer(name, id, disk, bs)
+	case "overflow":
+		return NewOverflowBuffer(name, id, capacity, disk, bs)
 	}
 	return nil, fmt.Errorf("invalid buffer strategy %q", strategy)
 }
 
+// ValidateBufferConfig checks the given buffer strategy and settings without
+// creating the buffer.
+func ValidateBufferConfig(strategy string, disk DiskBufferConfig) error {
+	switch strategy {
+	case "", "memory":
+		return nil
+	case "disk", "overflow":
+	default:
+		return fmt.Errorf("invalid buffer strategy %q", strategy)
+	}
+
+	switch disk.Compression {
+	case "", "none", "zstd", "snappy":
+	default:
+		return fmt.Errorf("invalid buffer compression %q", disk.Compression)
+	}
+	if disk.MaxSize < 0 {
+		return fmt.Errorf("invalid buffer size %d, must not be negative", disk.MaxSize)
+	}
+	if strategy == "overflow" && (disk.OverflowWatermark < 0 || disk.OverflowWatermark > 1) {
+		return fmt.Errorf("invalid overflow watermark %v, must be between zero and one", disk.OverflowWatermark)
+	}
+	return nil
+}
+
 func NewBufferStats(name, alias string, capacity int) BufferStats {
 	tags := map[string]string{"output": name}
 	if alias != "" {
@@ -1,20 +1,57 @@
 package models
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/golang/snappy"
 	"github.com/tidwall/wal"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 )
 
+// Stored entries are either plain serialized metrics or compressed data
+// prefixed by a zero byte and the compression algorithm. Serialized metrics
+// never start with a zero byte so both kinds can be distinguished, allowing
+// to change the compression setting of an existing buffer.
+const (
+	entryCompressed byte = 0x00
+
+	compressionNone   byte = 0x00
+	compressionZstd   byte = 0x01
+	compressionSnappy byte = 0x02
+)
+
+// DiskBufferConfig contains the settings of the disk buffer
+type DiskBufferConfig struct {
+	// Directory to store the buffer files in
+	Directory string
+
+	// Maximum size of the stored metrics in bytes, zero means unlimited
+	MaxSize int64
+
+	// Compression algorithm for stored metrics, "zstd", "snappy" or empty
+	Compression string
+
+	// Fraction of the memory buffer capacity after which metrics are spilled
+	// to disk when using the "overflow" strategy
+	OverflowWatermark float64
+}
+
+// DiskBuffer stores metrics in a write-ahead log on disk. Metrics remain at
+// their position until being accepted or rejected, so the order of kept
+// metrics is preserved independent of the order transactions end in.
 type DiskBuffer struct {
 	BufferStats
 	sync.Mutex
@@ -22,9 +59,6 @@ type DiskBuffer struct {
 	file *wal.Log
 	path string
 
-	batchFirst uint64 // Index of the first metric in the batch
-	batchSize  uint64 // Number of metrics currently in the batch
-
 	// Ending point of metrics read from disk on telegraf launch.
 	// Used to know whether to discard tracking metrics.
 	originalEnd uint64
@@ -38,11 +72,63 @@ type DiskBuffer struct {
 	// transaction. Metrics at those offsets should not be contained in new
 	// batches.
 	mask []int
+
+	// Indices of the metrics contained in outstanding transactions. Those
+	// metrics must neither be part of another batch nor be dropped.
+	inflight map[uint64]bool
+
+	// Maximum and current size of the entries in the WAL file in bytes. The
+	// size includes masked entries as long as they are not truncated.
+	maxSize int64
+	size    int64
+
+	// Sizes of the masked entries by their index, used to update the size
+	// when truncating the entries from the WAL file
+	maskedSizes map[uint64]int64
+
+	compression byte
+	encoder     *internal.ZstdEncoder
+	decoder     *internal.ZstdDecoder
 }
 
-func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error) {
-	filePath := filepath.Join(path, id)
-	walFile, err := wal.Open(filePath, nil)
+// diskTransaction holds the absolute indices of the metrics of a batch in the
+// WAL file and the sizes of their stored entries
+type diskTransaction struct {
+	indices []uint64
+	sizes   []int64
+}
+
+func NewDiskBuffer(name, id string, cfg DiskBufferConfig, stats BufferStats) (*DiskBuffer, error) {
+	filePath := filepath.Join(cfg.Directory, id)
+	buf := &DiskBuffer{
+		BufferStats: stats,
+		path:        filePath,
+		inflight:    make(map[uint64]bool),
+		maxSize:     cfg.MaxSize,
+		maskedSizes: make(map[uint64]int64),
+	}
+
+	switch cfg.Compression {
+	case "", "none":
+		buf.compression = compressionNone
+	case "zstd":
+		encoder, err := internal.NewZstdEncoder()
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd encoder failed: %w", err)
+		}
+		buf.compression = compressionZstd
+		buf.encoder = encoder
+	case "snappy":
+		buf.compression = compressionSnappy
+	default:
+		return nil, fmt.Errorf("invalid buffer compression %q", cfg.Compression)
+	}
+
+	walFile, size, err := buf.open()
+	if errors.Is(err, wal.ErrCorrupt) {
+		log.Printf("W! WAL file for plugin outputs.%s (%s) is corrupt, trying to repair: %v", name, id, err)
+		walFile, size, err = buf.repair(name, id)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open wal file: %w", err)
 	}
@@ -53,17 +139,105 @@ func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error
 			"this can safely be ignored if you added this plugin instance for the first time", name, id)
 	}
 
-	buf := &DiskBuffer{
-		BufferStats: stats,
-		file:        walFile,
-		path:        filePath,
-	}
+	buf.file = walFile
+	buf.size = size
 	if buf.length() > 0 {
 		buf.originalEnd = buf.writeIndex()
 	}
 	return buf, nil
 }
 
+// open opens the WAL file and determines the size of the stored metrics
+func (b *DiskBuffer) open() (*wal.Log, int64, error) {
+	walFile, err := wal.Open(b.path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Only the last segment is checked when opening the file so read all
+	// entries to detect corrupted segments early
+	first, err := walFile.FirstIndex()
+	if err != nil || first == 0 {
+		return walFile, 0, err
+	}
+	last, err := walFile.LastIndex()
+	if err != nil {
+		return walFile, 0, err
+	}
+	var size int64
+	for index := first; index <= last; index++ {
+		data, err := walFile.Read(index)
+		if err != nil {
+			walFile.Close()
+			return nil, 0, err
+		}
+		size += int64(len(data))
+	}
+	return walFile, size, nil
+}
+
+// repair moves the corrupted WAL file to a quarantine directory and creates
+// a new WAL file containing all metrics still readable from the old file.
+func (b *DiskBuffer) repair(name, id string) (*wal.Log, int64, error) {
+	quarantine := b.path + ".corrupt-" + time.Now().UTC().Format("20060102T150405Z")
+	if err := os.Rename(b.path, quarantine); err != nil {
+		return nil, 0, fmt.Errorf("moving corrupted wal file failed: %w", err)
+	}
+
+	walFile, err := wal.Open(b.path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries, err := os.ReadDir(quarantine)
+	if err != nil {
+		walFile.Close()
+		return nil, 0, fmt.Errorf("reading corrupted wal file failed: %w", err)
+	}
+
+	var index uint64
+	var size int64
+	var lost int
+	for _, entry := range entries {
+		// Only consider segment files named by their first index
+		if _, err := strconv.ParseUint(entry.Name(), 10, 64); err != nil || len(entry.Name()) != 20 {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(quarantine, entry.Name()))
+		if err != nil {
+			walFile.Close()
+			return nil, 0, fmt.Errorf("reading segment %q failed: %w", entry.Name(), err)
+		}
+
+		// Segments consist of the length of an entry followed by its data,
+		// salvage all entries up to the first inconsistency
+		for len(data) > 0 {
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				lost++
+				break
+			}
+			raw := data[n : n+int(length)]
+			data = data[n+int(length):]
+
+			if _, err := b.decode(raw); err != nil && !errors.Is(err, metric.ErrSkipTracking) {
+				lost++
+				continue
+			}
+			index++
+			if err := walFile.Write(index, raw); err != nil {
+				walFile.Close()
+				return nil, 0, fmt.Errorf("writing salvaged metric failed: %w", err)
+			}
+			size += int64(len(raw))
+		}
+	}
+
+	log.Printf("W! Repaired WAL file for plugin outputs.%s (%s): salvaged %d metrics, found %d damaged entries; "+
+		"moved the original file to %q", name, id, index, lost, quarantine)
+	return walFile, size, nil
+}
+
 func (b *DiskBuffer) Len() int {
 	b.Lock()
 	defer b.Unlock()
@@ -109,9 +283,7 @@ func (b *DiskBuffer) Add(metrics ...telegraf.Metric) int {
 
 	dropped := 0
 	for _, m := range metrics {
-		if !b.addSingleMetric(m) {
-			dropped++
-		}
+		dropped += b.addSingleMetric(m)
 		// as soon as a new metric is added, if this was empty, try to flush the "empty" metric out
 		b.handleEmptyFile()
 	}
@@ -119,17 +291,133 @@ func (b *DiskBuffer) Add(metrics ...telegraf.Metric) int {
 	return dropped
 }
 
-func (b *DiskBuffer) addSingleMetric(m telegraf.Metric) bool {
-	data, err := metric.ToBytes(m)
+// addSingleMetric stores the metric and returns the number of dropped metrics
+func (b *DiskBuffer) addSingleMetric(m telegraf.Metric) int {
+	data, err := b.encode(m)
 	if err != nil {
 		panic(err)
 	}
+
+	// Make room for the new metric by dropping the oldest ones if the size
+	// limit is exceeded. If this is not possible, drop the new metric.
+	var dropped int
+	size := int64(len(data))
+	if b.maxSize > 0 && b.used()+size > b.maxSize {
+		if size <= b.maxSize {
+			dropped = b.dropOldest(b.used() + size - b.maxSize)
+		}
+		if b.used()+size > b.maxSize {
+			b.metricDropped(m)
+			return dropped + 1
+		}
+	}
+
 	err = b.file.Write(b.writeIndex(), data)
-	if err == nil {
-		b.metricAdded()
-		return true
+	if err != nil {
+		return dropped + 1
 	}
-	return false
+	b.size += size
+	b.metricAdded()
+	return dropped
+}
+
+// used returns the number of bytes used by the WAL file, not counting the
+// remaining entry of an empty file as it is removed on the next write.
+func (b *DiskBuffer) used() int64 {
+	if b.isEmpty {
+		return 0
+	}
+	return b.size
+}
+
+// dropOldest removes the oldest metrics until the given number of bytes is
+// freed and returns the number of dropped metrics. Only the metrics in front
+// of the first outstanding transaction can be truncated from the file, so no
+// metric is dropped if this does not free enough space.
+func (b *DiskBuffer) dropOldest(required int64) int {
+	first := b.readIndex()
+	var offsets []int
+	var entries [][]byte
+	var freed int64
+	for offset := 0; offset < b.entries() && freed < required; offset++ {
+		index := first + uint64(offset)
+		if b.inflight[index] {
+			break
+		}
+		if size, found := b.maskedSizes[index]; found {
+			freed += size
+			continue
+		}
+		data, err := b.file.Read(index)
+		if err != nil {
+			panic(err)
+		}
+		offsets = append(offsets, offset)
+		entries = append(entries, data)
+		freed += int64(len(data))
+	}
+	if freed < required {
+		return 0
+	}
+
+	for i, offset := range offsets {
+		if m, err := b.decode(entries[i]); err == nil {
+			b.metricDropped(m)
+		}
+		b.mask = append(b.mask, offset)
+		b.maskedSizes[first+uint64(offset)] = int64(len(entries[i]))
+	}
+	sort.Ints(b.mask)
+
+	// Free the disk space right away
+	b.truncate()
+	return len(offsets)
+}
+
+// encode serializes the metric and compresses the data if configured
+func (b *DiskBuffer) encode(m telegraf.Metric) ([]byte, error) {
+	data, err := metric.ToBytes(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var compressed []byte
+	switch b.compression {
+	case compressionZstd:
+		compressed, err = b.encoder.Encode(data)
+		if err != nil {
+			return nil, fmt.Errorf("compressing metric failed: %w", err)
+		}
+	case compressionSnappy:
+		compressed = snappy.Encode(nil, data)
+	default:
+		return data, nil
+	}
+	return append([]byte{entryCompressed, b.compression}, compressed...), nil
+}
+
+// decode decompresses the data if necessary and deserializes the metric
+func (b *DiskBuffer) decode(data []byte) (telegraf.Metric, error) {
+	if len(data) > 1 && data[0] == entryCompressed {
+		var err error
+		switch data[1] {
+		case compressionZstd:
+			if b.decoder == nil {
+				if b.decoder, err = internal.NewZstdDecoder(); err != nil {
+					return nil, fmt.Errorf("creating zstd decoder failed: %w", err)
+				}
+			}
+			data, err = b.decoder.Decode(data[2:])
+		case compressionSnappy:
+			data, err = snappy.Decode(nil, data[2:])
+		default:
+			err = fmt.Errorf("unknown compression %d", data[1])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decompressing metric failed: %w", err)
+		}
+	}
+	return metric.FromBytes(data)
 }
 
 func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
@@ -139,12 +427,15 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 	if b.length() == 0 {
 		return &Transaction{}
 	}
-	b.batchFirst = b.readIndex()
-	b.batchSize = 0
 
+	// Use the absolute indices of the metrics as the transaction state as
+	// the file might be truncated while the transaction is outstanding
 	metrics := make([]telegraf.Metric, 0, batchSize)
-	offsets := make([]int, 0, batchSize)
-	readIndex := b.batchFirst
+	state := diskTransaction{
+		indices: make([]uint64, 0, batchSize),
+		sizes:   make([]int64, 0, batchSize),
+	}
+	readIndex := b.readIndex()
 	endIndex := b.writeIndex()
 	for offset := 0; batchSize > 0 && readIndex < endIndex; offset++ {
 		data, err := b.file.Read(readIndex)
@@ -157,6 +448,10 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 			// Metric is masked by a previous write and is scheduled for removal
 			continue
 		}
+		if b.inflight[readIndex-1] {
+			// Metric is part of another outstanding transaction
+			continue
+		}
 
 		// Validate that a tracking metric is from this instance of telegraf and skip ones from older instances.
 		// A tracking metric can be skipped here because metric.Accept() is only called once data is successfully
@@ -166,15 +461,17 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 		// - ErrSkipTracking:  means that the tracking information was unable to be found for a tracking ID.
 		// - Outside of range: means that the metric was guaranteed to be left over from the previous instance
 		//                     as it was here when we opened the wal file in this instance.
-		m, err := metric.FromBytes(data)
+		m, err := b.decode(data)
 		if err != nil {
 			if errors.Is(err, metric.ErrSkipTracking) {
 				// could not look up tracking information for metric, skip
 				continue
 			}
-			// non-recoverable error in deserialization, abort
-			log.Printf("E! raw metric data: %v", data)
-			panic(err)
+			// unreadable metric, e.g. due to data corruption, so remove it
+			log.Printf("E! Dropping unreadable metric at index %d: %v", readIndex-1, err)
+			b.mask = append(b.mask, offset)
+			b.maskedSizes[readIndex-1] = int64(len(data))
+			continue
 		}
 		if _, ok := m.(telegraf.TrackingMetric); ok && readIndex < b.originalEnd {
 			// tracking metric left over from previous instance, skip
@@ -182,11 +479,13 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 		}
 
 		metrics = append(metrics, m)
-		offsets = append(offsets, offset)
-		b.batchSize++
+		state.indices = append(state.indices, readIndex-1)
+		state.sizes = append(state.sizes, int64(len(data)))
+		b.inflight[readIndex-1] = true
 		batchSize--
 	}
-	return &Transaction{Batch: metrics, valid: true, state: offsets}
+	sort.Ints(b.mask)
+	return &Transaction{Batch: metrics, valid: true, state: state}
 }
 
 func (b *DiskBuffer) EndTransaction(tx *Transaction) {
@@ -200,27 +499,41 @@ func (b *DiskBuffer) EndTransaction(tx *Transaction) {
 	}
 	tx.valid = false
 
-	// Get the metric offsets from the transaction
-	offsets := tx.state.([]int)
+	// Get the metric indices and sizes from the transaction
+	state := tx.state.(diskTransaction)
 
 	b.Lock()
 	defer b.Unlock()
 
+	// Release the metrics of the transaction
+	for _, index := range state.indices {
+		delete(b.inflight, index)
+	}
+
 	// Mark metrics which should be removed in the internal mask
 	remove := make([]int, 0, len(tx.Accept)+len(tx.Reject))
 	for _, idx := range tx.Accept {
 		b.metricWritten(tx.Batch[idx])
-		remove = append(remove, offsets[idx])
+		remove = append(remove, idx)
 	}
 	for _, idx := range tx.Reject {
 		b.metricRejected(tx.Batch[idx])
-		remove = append(remove, offsets[idx])
+		remove = append(remove, idx)
+	}
+	first := b.readIndex()
+	for _, idx := range remove {
+		b.mask = append(b.mask, int(state.indices[idx]-first))
+		b.maskedSizes[state.indices[idx]] = state.sizes[idx]
 	}
-	b.mask = append(b.mask, remove...)
 	sort.Ints(b.mask)
 
-	// Remove the metrics that are marked for removal from the front of the
-	// WAL file. All other metrics must be kept.
+	b.truncate()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// truncate removes the metrics that are marked for removal from the front of
+// the WAL file. All other metrics must be kept.
+func (b *DiskBuffer) truncate() {
 	if len(b.mask) == 0 || b.mask[0] != 0 {
 		// Mask is empty or the first index is not the front of the file, so
 		// exit early as there is nothing to remove
@@ -235,37 +548,46 @@ func (b *DiskBuffer) EndTransaction(tx *Transaction) {
 		}
 		correction = offset
 	}
-	// The 'correction' denotes the offset to subtract from the remaining mask
-	// (if any) and the 'removalIdx' denotes the index to use when truncating
-	// the file and mask. Keep them separate to be able to handle the special
-	// "the file cannot be empty" property of the WAL file.
+	// The 'correction' denotes the offset of the last removable entry and the
+	// 'removalIdx' denotes the index to use when truncating the file and mask.
+	// Keep them separate to be able to handle the special "the file cannot be
+	// empty" property of the WAL file.
 	removeIdx := correction + 1
 
 	// Remove the metrics in front from the WAL file
+	first := b.readIndex()
 	b.isEmpty = b.entries()-removeIdx <= 0
 	if b.isEmpty {
 		// WAL files cannot be fully empty but need to contain at least one
 		// item to not throw an error
 		removeIdx--
 	}
-	if err := b.file.TruncateFront(b.batchFirst + uint64(removeIdx)); err != nil {
-		log.Printf("E! batch length: %d, first: %d, size: %d", len(tx.Batch), b.batchFirst, b.batchSize)
+	for index := first; index < first+uint64(removeIdx); index++ {
+		b.size -= b.maskedSizes[index]
+		delete(b.maskedSizes, index)
+	}
+	if err := b.file.TruncateFront(first + uint64(removeIdx)); err != nil {
+		log.Printf("E! first: %d, remove: %d, outstanding: %d", first, removeIdx, len(b.inflight))
 		panic(err)
 	}
 
-	// Truncate the mask and update the relative offsets
+	// Truncate the mask and update the relative offsets. The remaining offsets
+	// are relative to the old front of the file, so shift them by the number of
+	// removed entries. In the "file cannot be empty" case the single remaining
+	// entry is the last masked one and ends up at offset zero.
 	b.mask = b.mask[removeIdx:]
+	shift := removeIdx
+	if b.isEmpty {
+		shift = correction
+	}
 	for i := range b.mask {
-		b.mask[i] -= correction
+		b.mask[i] -= shift
 	}
 
 	// check if the original end index is still valid, clear if not
 	if b.originalEnd < b.readIndex() {
 		b.originalEnd = 0
 	}
-
-	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
 }
 
 func (b *DiskBuffer) Stats() BufferStats {
@@ -276,11 +598,6 @@ func (b *DiskBuffer) Close() error {
 	return b.file.Close()
 }
 
-func (b *DiskBuffer) resetBatch() {
-	b.batchFirst = 0
-	b.batchSize = 0
-}
-
 // This is very messy and not ideal, but serves as the only way I can find currently
 // to actually treat the walfile as empty if needed, since Truncate() calls require
 // that at least one entry remains in them otherwise they return an error.
@@ -289,9 +606,14 @@ func (b *DiskBuffer) handleEmptyFile() {
 	if !b.isEmpty {
 		return
 	}
-	if err := b.file.TruncateFront(b.readIndex() + 1); err != nil {
+	index := b.readIndex()
+	b.size -= b.maskedSizes[index]
+	delete(b.maskedSizes, index)
+	if err := b.file.TruncateFront(index + 1); err != nil {
 		log.Printf("E! readIndex: %d, buffer len: %d", b.readIndex(), b.length())
 		panic(err)
 	}
+	// the removed entry is the only masked one so clear the mask
+	b.mask = b.mask[:0]
 	b.isEmpty = false
 }
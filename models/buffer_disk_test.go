@@ -1,6 +1,7 @@
 package models
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -19,7 +20,7 @@ func TestDiskBufferRetainsTrackingInformation(t *testing.T) {
 	var delivered int
 	mm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) { delivered++ })
 
-	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir())
+	buf, err := NewBuffer("test", "123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir()})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -78,7 +79,7 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 	walfile.Close()
 
 	// Create a buffer
-	buf, err := NewBuffer("123", "123", "", 0, "disk", path)
+	buf, err := NewBuffer("123", "123", "", 0, "disk", DiskBufferConfig{Directory: path})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -98,7 +99,7 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 // https://github.com/influxdata/telegraf/issues/16696
 func TestDiskBufferTruncate(t *testing.T) {
 	// Create a disk buffer
-	buf, err := NewBuffer("test", "id123", "", 0, "disk", t.TempDir())
+	buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir()})
 	require.NoError(t, err)
 	defer buf.Close()
 	diskBuf, ok := buf.(*DiskBuffer)
@@ -152,3 +153,183 @@ func TestDiskBufferTruncate(t *testing.T) {
 	tx = buf.BeginTransaction(4)
 	require.Empty(t, tx.Batch)
 }
+
+func TestDiskBufferAddAfterEmpty(t *testing.T) {
+	buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	defer buf.Close()
+
+	// Empty the buffer completely
+	buf.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)))
+	tx := buf.BeginTransaction(4)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 0, buf.Len())
+
+	// Metrics added afterwards must be available
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0))
+	buf.Add(m)
+	require.Equal(t, 1, buf.Len())
+	tx = buf.BeginTransaction(4)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, tx.Batch)
+}
+
+func TestDiskBufferMaxSize(t *testing.T) {
+	metrics := make([]telegraf.Metric, 0, 10)
+	for i := range 10 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		metrics = append(metrics, m)
+	}
+
+	// Determine the size of a single stored metric
+	registerGob()
+	data, err := metric.ToBytes(metrics[0])
+	require.NoError(t, err)
+	entrySize := int64(len(data))
+
+	// Create a buffer being able to hold four metrics
+	buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir(), MaxSize: 4 * entrySize})
+	require.NoError(t, err)
+	buf.Stats().MetricsDropped.Set(0)
+	defer buf.Close()
+	diskBuf, ok := buf.(*DiskBuffer)
+	require.True(t, ok, "buffer is not a disk buffer")
+
+	// The oldest metrics must be dropped when exceeding the size
+	require.Equal(t, 2, buf.Add(metrics[:6]...))
+	require.Equal(t, 4, buf.Len())
+	require.Equal(t, 4, diskBuf.entries())
+	require.Equal(t, 4*entrySize, diskBuf.size)
+	require.Equal(t, int64(2), buf.Stats().MetricsDropped.Get())
+
+	// Metrics of a running transaction must be kept and block freeing the
+	// space of newer metrics, so the new metrics are dropped instead
+	tx := buf.BeginTransaction(2)
+	testutil.RequireMetricsEqual(t, metrics[2:4], tx.Batch)
+	require.Equal(t, 2, buf.Add(metrics[6:8]...))
+	require.Equal(t, 4, buf.Len())
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 2, buf.Len())
+	require.Equal(t, 2*entrySize, diskBuf.size)
+
+	tx = buf.BeginTransaction(4)
+	testutil.RequireMetricsEqual(t, metrics[4:6], tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 0, buf.Len())
+	require.Zero(t, diskBuf.used())
+	require.Equal(t, int64(4), buf.Stats().MetricsDropped.Get())
+}
+
+func TestDiskBufferMaxSizeMaskedEntries(t *testing.T) {
+	metrics := make([]telegraf.Metric, 0, 6)
+	for i := range 6 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		metrics = append(metrics, m)
+	}
+
+	// Determine the size of a single stored metric
+	registerGob()
+	data, err := metric.ToBytes(metrics[0])
+	require.NoError(t, err)
+	entrySize := int64(len(data))
+
+	buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir(), MaxSize: 4 * entrySize})
+	require.NoError(t, err)
+	buf.Stats().MetricsDropped.Set(0)
+	defer buf.Close()
+	diskBuf, ok := buf.(*DiskBuffer)
+	require.True(t, ok, "buffer is not a disk buffer")
+	require.Zero(t, buf.Add(metrics[:4]...))
+
+	// Accepted metrics behind an outstanding transaction cannot be removed
+	// from the file and must still count against the size limit
+	tx1 := buf.BeginTransaction(1)
+	tx2 := buf.BeginTransaction(3)
+	testutil.RequireMetricsEqual(t, metrics[1:4], tx2.Batch)
+	tx2.AcceptAll()
+	buf.EndTransaction(tx2)
+	require.Equal(t, 1, buf.Len())
+	require.Equal(t, 4, diskBuf.entries())
+	require.Equal(t, 4*entrySize, diskBuf.size)
+	require.Equal(t, 1, buf.Add(metrics[4]))
+	require.Equal(t, 4, diskBuf.entries())
+
+	// Finishing the outstanding transaction frees the space
+	tx1.AcceptAll()
+	buf.EndTransaction(tx1)
+	require.Zero(t, buf.Len())
+	require.Zero(t, buf.Add(metrics[4:6]...))
+	require.Equal(t, 2, diskBuf.entries())
+	require.Equal(t, 2*entrySize, diskBuf.size)
+
+	tx := buf.BeginTransaction(4)
+	testutil.RequireMetricsEqual(t, metrics[4:6], tx.Batch)
+	require.Equal(t, int64(1), buf.Stats().MetricsDropped.Get())
+}
+
+func TestDiskBufferCompression(t *testing.T) {
+	path := t.TempDir()
+
+	expected := make([]telegraf.Metric, 0, 6)
+	for _, compression := range []string{"zstd", "snappy", ""} {
+		buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: path, Compression: compression})
+		require.NoError(t, err)
+		for i := range 2 {
+			m := metric.New("test", map[string]string{"compression": compression}, map[string]interface{}{"value": i}, time.Unix(0, 0))
+			buf.Add(m)
+			expected = append(expected, m)
+		}
+		require.NoError(t, buf.Close())
+	}
+
+	// Metrics written with any compression setting must be readable
+	buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: path, Compression: "zstd"})
+	require.NoError(t, err)
+	defer buf.Close()
+	tx := buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+}
+
+func TestDiskBufferInvalidCompression(t *testing.T) {
+	_, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: t.TempDir(), Compression: "lz4"})
+	require.ErrorContains(t, err, `invalid buffer compression "lz4"`)
+}
+
+func TestDiskBufferRepair(t *testing.T) {
+	path := t.TempDir()
+
+	expected := make([]telegraf.Metric, 0, 5)
+	buf, err := NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: path})
+	require.NoError(t, err)
+	for i := range 5 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(0, 0))
+		buf.Add(m)
+		expected = append(expected, m)
+	}
+	require.NoError(t, buf.Close())
+
+	// Simulate a partially written entry at the end of the segment
+	segment := filepath.Join(path, "id123", "00000000000000000001")
+	f, err := os.OpenFile(segment, os.O_APPEND|os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0xff, 0x01, 0x00})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = wal.Open(filepath.Join(path, "id123"), nil)
+	require.ErrorIs(t, err, wal.ErrCorrupt)
+
+	// The buffer must contain all readable metrics
+	buf, err = NewBuffer("test", "id123", "", 0, "disk", DiskBufferConfig{Directory: path})
+	require.NoError(t, err)
+	defer buf.Close()
+	require.Equal(t, 5, buf.Len())
+	tx := buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+
+	// The corrupted file must be kept for inspection
+	quarantined, err := filepath.Glob(filepath.Join(path, "id123.corrupt-*"))
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+}
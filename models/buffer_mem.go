@@ -6,7 +6,10 @@ import (
 	"github.com/influxdata/telegraf"
 )
 
-// MemoryBuffer stores metrics in a circular buffer.
+// MemoryBuffer stores metrics in a circular buffer. Metrics kept at the end
+// of a transaction are returned to the front of the buffer, so with multiple
+// outstanding transactions, the metrics of a later batch might precede the
+// ones of an earlier batch if the transactions end out of order.
 type MemoryBuffer struct {
 	sync.Mutex
 	BufferStats
@@ -17,8 +20,7 @@ type MemoryBuffer struct {
 	size  int // number of metrics currently in the buffer
 	cap   int // the capacity of the buffer
 
-	batchFirst int // index of the first metric in the batch
-	batchSize  int // number of metrics currently in the batch
+	batchSize int // number of metrics currently in outstanding batches
 }
 
 func NewMemoryBuffer(capacity int, stats BufferStats) (*MemoryBuffer, error) {
@@ -60,9 +62,7 @@ func (b *MemoryBuffer) BeginTransaction(batchSize int) *Transaction {
 		return &Transaction{}
 	}
 
-	b.batchFirst = b.first
-	b.batchSize = outLen
-	batchIndex := b.batchFirst
+	batchIndex := b.first
 	batch := make([]telegraf.Metric, outLen)
 	for i := range batch {
 		batch[i] = b.buf[batchIndex]
@@ -70,8 +70,9 @@ func (b *MemoryBuffer) BeginTransaction(batchSize int) *Transaction {
 		batchIndex = b.next(batchIndex)
 	}
 
-	b.first = b.nextby(b.first, b.batchSize)
+	b.first = b.nextby(b.first, outLen)
 	b.size -= outLen
+	b.batchSize += outLen
 	return &Transaction{Batch: batch, valid: true}
 }
 
@@ -115,7 +116,7 @@ func (b *MemoryBuffer) EndTransaction(tx *Transaction) {
 		}
 	}
 
-	b.resetBatch()
+	b.batchSize = max(b.batchSize-len(tx.Batch), 0)
 	b.BufferSize.Set(int64(b.length()))
 }
 
@@ -140,7 +141,6 @@ func (b *MemoryBuffer) addMetric(m telegraf.Metric) int {
 
 		if b.batchSize > 0 {
 			b.batchSize--
-			b.batchFirst = b.next(b.batchFirst)
 		}
 	}
 
@@ -183,8 +183,3 @@ func (b *MemoryBuffer) prevby(index, count int) int {
 	index %= b.cap
 	return index
 }
-
-func (b *MemoryBuffer) resetBatch() {
-	b.batchFirst = 0
-	b.batchSize = 0
-}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// DefaultOverflowWatermark is the default fraction of the memory buffer
+// capacity after which metrics are spilled to disk
+const DefaultOverflowWatermark = 1.0
+
+// OverflowBuffer keeps metrics in memory and spills them to disk once the
+// memory buffer reaches its watermark. Metrics on disk are always newer than
+// the ones in memory, so new metrics are stored on disk until all spilled
+// metrics are written to preserve the order. Kept metrics are returned to the
+// buffer they originate from with the ordering of the respective buffer.
+type OverflowBuffer struct {
+	BufferStats
+	sync.Mutex
+
+	memory *MemoryBuffer
+	disk   *DiskBuffer
+
+	watermark int // number of metrics in memory before spilling to disk
+}
+
+// overflowTransaction holds the transactions of the underlying buffers
+// contributing to a batch, with the memory metrics preceding the disk ones.
+type overflowTransaction struct {
+	memory *Transaction
+	disk   *Transaction
+}
+
+func NewOverflowBuffer(name, id string, capacity int, cfg DiskBufferConfig, stats BufferStats) (*OverflowBuffer, error) {
+	fraction := cfg.OverflowWatermark
+	if fraction == 0 {
+		fraction = DefaultOverflowWatermark
+	}
+	if fraction < 0 || fraction > 1 {
+		return nil, fmt.Errorf("invalid overflow watermark %v, must be between zero and one", fraction)
+	}
+
+	memory, err := NewMemoryBuffer(capacity, stats)
+	if err != nil {
+		return nil, err
+	}
+	disk, err := NewDiskBuffer(name, id, cfg, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &OverflowBuffer{
+		BufferStats: stats,
+		memory:      memory,
+		disk:        disk,
+		watermark:   max(int(fraction*float64(capacity)), 1),
+	}
+	buf.BufferSize.Set(int64(buf.length()))
+	return buf, nil
+}
+
+func (b *OverflowBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.length()
+}
+
+func (b *OverflowBuffer) length() int {
+	return b.memory.Len() + b.disk.Len()
+}
+
+func (b *OverflowBuffer) Add(metrics ...telegraf.Metric) int {
+	b.Lock()
+	defer b.Unlock()
+
+	// Metrics stay in memory until reaching the watermark. Afterwards they
+	// are spilled to disk until all metrics on disk are written.
+	var dropped int
+	for i, m := range metrics {
+		if b.disk.Len() > 0 || b.memory.Len() >= b.watermark {
+			dropped += b.disk.Add(metrics[i:]...)
+			break
+		}
+		dropped += b.memory.Add(m)
+	}
+
+	b.BufferSize.Set(int64(b.length()))
+	return dropped
+}
+
+func (b *OverflowBuffer) BeginTransaction(batchSize int) *Transaction {
+	b.Lock()
+	defer b.Unlock()
+
+	// Fill the batch with the older metrics in memory first and use the
+	// metrics on disk for the remaining space
+	var state overflowTransaction
+	var batch []telegraf.Metric
+	if b.memory.Len() > 0 {
+		state.memory = b.memory.BeginTransaction(batchSize)
+		batch = append(batch, state.memory.Batch...)
+	}
+	if remaining := batchSize - len(batch); remaining > 0 && b.disk.Len() > 0 {
+		state.disk = b.disk.BeginTransaction(remaining)
+		batch = append(batch, state.disk.Batch...)
+	}
+
+	if len(batch) == 0 {
+		return &Transaction{}
+	}
+	return &Transaction{Batch: batch, valid: true, state: state}
+}
+
+func (b *OverflowBuffer) EndTransaction(tx *Transaction) {
+	b.Lock()
+	defer b.Unlock()
+
+	// Ignore invalid transactions and make sure they can only be finished once
+	if !tx.valid {
+		return
+	}
+	tx.valid = false
+
+	// Split the accepted and rejected metrics among the underlying buffers
+	state := tx.state.(overflowTransaction)
+	var offset int
+	if state.memory != nil {
+		offset = len(state.memory.Batch)
+	}
+	for _, idx := range tx.Accept {
+		if idx < offset {
+			state.memory.Accept = append(state.memory.Accept, idx)
+		} else {
+			state.disk.Accept = append(state.disk.Accept, idx-offset)
+		}
+	}
+	for _, idx := range tx.Reject {
+		if idx < offset {
+			state.memory.Reject = append(state.memory.Reject, idx)
+		} else {
+			state.disk.Reject = append(state.disk.Reject, idx-offset)
+		}
+	}
+
+	if state.memory != nil {
+		b.memory.EndTransaction(state.memory)
+	}
+	if state.disk != nil {
+		b.disk.EndTransaction(state.disk)
+	}
+
+	b.BufferSize.Set(int64(b.length()))
+}
+
+func (b *OverflowBuffer) Stats() BufferStats {
+	return b.BufferStats
+}
+
+func (b *OverflowBuffer) Close() error {
+	return b.disk.Close()
+}
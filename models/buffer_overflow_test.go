@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestOverflowBufferSpill(t *testing.T) {
+	metrics := make([]telegraf.Metric, 0, 10)
+	for i := range 10 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		metrics = append(metrics, m)
+	}
+
+	cfg := DiskBufferConfig{
+		Directory:         t.TempDir(),
+		OverflowWatermark: 0.5,
+	}
+	buf, err := NewBuffer("test", "id123", "", 8, "overflow", cfg)
+	require.NoError(t, err)
+	defer buf.Close()
+	overflowBuf, ok := buf.(*OverflowBuffer)
+	require.True(t, ok, "buffer is not an overflow buffer")
+
+	// Metrics exceeding the watermark must be spilled to disk
+	require.Zero(t, buf.Add(metrics[:6]...))
+	require.Equal(t, 6, buf.Len())
+	require.Equal(t, 4, overflowBuf.memory.Len())
+	require.Equal(t, 2, overflowBuf.disk.Len())
+
+	// Batches contain the metrics in order across both buffers, kept metrics
+	// must be returned to the buffer they originate from
+	tx := buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, metrics[:5], tx.Batch)
+	tx.Accept = []int{0, 1, 2}
+	tx.Reject = []int{4}
+	buf.EndTransaction(tx)
+	require.Equal(t, 2, buf.Len())
+	require.Equal(t, 1, overflowBuf.memory.Len())
+	require.Equal(t, 1, overflowBuf.disk.Len())
+
+	// New metrics must be stored on disk until the disk is drained
+	require.Zero(t, buf.Add(metrics[6]))
+	require.Equal(t, 2, overflowBuf.disk.Len())
+
+	tx = buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{metrics[3], metrics[5], metrics[6]}, tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 0, buf.Len())
+
+	// Once drained, metrics are kept in memory again
+	require.Zero(t, buf.Add(metrics[7:]...))
+	require.Equal(t, 3, overflowBuf.memory.Len())
+	require.Equal(t, 0, overflowBuf.disk.Len())
+}
+
+func TestOverflowBufferInvalidWatermark(t *testing.T) {
+	cfg := DiskBufferConfig{
+		Directory:         t.TempDir(),
+		OverflowWatermark: 1.5,
+	}
+	_, err := NewBuffer("test", "id123", "", 8, "overflow", cfg)
+	require.ErrorContains(t, err, "invalid overflow watermark")
+}
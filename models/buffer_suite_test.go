@@ -24,7 +24,7 @@ func (s *BufferSuiteTest) SetupTest() {
 	switch s.bufferType {
 	case "", "memory":
 		s.hasMaxCapacity = true
-	case "disk":
+	case "disk", "overflow":
 		path, err := os.MkdirTemp("", "*-buffer-test")
 		s.Require().NoError(err)
 		s.bufferPath = path
@@ -47,9 +47,13 @@ func TestDiskBufferSuite(t *testing.T) {
 	suite.Run(t, &BufferSuiteTest{bufferType: "disk"})
 }
 
+func TestOverflowBufferSuite(t *testing.T) {
+	suite.Run(t, &BufferSuiteTest{bufferType: "overflow"})
+}
+
 func (s *BufferSuiteTest) newTestBuffer(capacity int) Buffer {
 	s.T().Helper()
-	buf, err := NewBuffer("test", "123", "", capacity, s.bufferType, s.bufferPath)
+	buf, err := NewBuffer("test", "123", "", capacity, s.bufferType, DiskBufferConfig{Directory: s.bufferPath})
 	s.Require().NoError(err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -234,6 +238,88 @@ func (s *BufferSuiteTest) TestBufferMultipleBatch() {
 	buf.EndTransaction(tx)
 }
 
+func (s *BufferSuiteTest) TestBufferConcurrentTransactions() {
+	buf := s.newTestBuffer(10)
+	defer buf.Close()
+
+	metrics := make([]telegraf.Metric, 0, 6)
+	for i := range 6 {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(int64(i), 0))
+		metrics = append(metrics, m)
+	}
+	buf.Add(metrics...)
+
+	// Outstanding transactions must not share metrics
+	tx1 := buf.BeginTransaction(2)
+	testutil.RequireMetricsEqual(s.T(), metrics[0:2], tx1.Batch)
+	tx2 := buf.BeginTransaction(2)
+	testutil.RequireMetricsEqual(s.T(), metrics[2:4], tx2.Batch)
+	s.Equal(6, buf.Len())
+
+	// End the transactions out of order keeping the metrics of the first one
+	tx2.AcceptAll()
+	buf.EndTransaction(tx2)
+	tx1.KeepAll()
+	buf.EndTransaction(tx1)
+	s.Equal(4, buf.Len())
+
+	tx := buf.BeginTransaction(10)
+	expected := []telegraf.Metric{metrics[0], metrics[1], metrics[4], metrics[5]}
+	testutil.RequireMetricsEqual(s.T(), expected, tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	s.Equal(0, buf.Len())
+}
+
+func (s *BufferSuiteTest) TestBufferConcurrentTransactionsOutOfOrder() {
+	buf := s.newTestBuffer(20)
+	defer buf.Close()
+
+	metrics := make([]telegraf.Metric, 0, 20)
+	for i := range 20 {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(int64(i), 0))
+		metrics = append(metrics, m)
+	}
+	buf.Add(metrics...)
+
+	tx1 := buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(s.T(), metrics[0:5], tx1.Batch)
+	tx2 := buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(s.T(), metrics[5:10], tx2.Batch)
+	tx3 := buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(s.T(), metrics[10:15], tx3.Batch)
+
+	// End the transactions out of order leaving gaps in between the accepted
+	// metrics and keeping the metrics of the transaction in the middle
+	tx3.AcceptAll()
+	buf.EndTransaction(tx3)
+	s.Equal(15, buf.Len())
+	tx1.AcceptAll()
+	buf.EndTransaction(tx1)
+	s.Equal(10, buf.Len())
+	tx2.KeepAll()
+	buf.EndTransaction(tx2)
+	s.Equal(10, buf.Len())
+
+	tx := buf.BeginTransaction(20)
+	expected := append(append([]telegraf.Metric{}, metrics[5:10]...), metrics[15:20]...)
+	testutil.RequireMetricsEqual(s.T(), expected, tx.Batch)
+
+	// Accept part of the batch and make sure the remaining metrics are kept
+	tx.Accept = []int{0, 1, 5, 6}
+	buf.EndTransaction(tx)
+	s.Equal(6, buf.Len())
+
+	tx = buf.BeginTransaction(20)
+	expected = []telegraf.Metric{metrics[7], metrics[8], metrics[9], metrics[17], metrics[18], metrics[19]}
+	testutil.RequireMetricsEqual(s.T(), expected, tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	s.Equal(0, buf.Len())
+
+	s.Equal(int64(20), buf.Stats().MetricsWritten.Get(), "metrics written")
+}
+
 func (s *BufferSuiteTest) TestBufferRejectWithRoom() {
 	buf := s.newTestBuffer(5)
 	defer buf.Close()
@@ -2,8 +2,10 @@ package models
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 // logName returns the log-friendly name/type.
@@ -14,6 +16,25 @@ func logName(pluginType, name, alias string) string {
 	return pluginType + "." + name + "::" + alias
 }
 
+// statistics returns a snapshot of the internal statistics registered for the
+// plugin under the given measurement.
+func statistics(measurement, pluginType, name, alias string) map[string]int64 {
+	tags := map[string]string{pluginType: name}
+	if alias != "" {
+		tags["alias"] = alias
+	}
+	return selfstat.Snapshot(measurement, tags)
+}
+
+// lastError returns the last error logged via the given logger if the logger
+// keeps track of errors.
+func lastError(logger telegraf.Logger) (string, time.Time) {
+	if l, ok := logger.(interface{ LastError() (string, time.Time) }); ok {
+		return l.LastError()
+	}
+	return "", time.Time{}
+}
+
 func SetLoggerOnPlugin(i interface{}, logger telegraf.Logger) {
 	valI := reflect.ValueOf(i)
 
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// DeadLetterConfig configures the sink receiving the metrics rejected by an
+// output. Either a file or an output must be set.
+type DeadLetterConfig struct {
+	// File to append the rejected metrics to using the serializer
+	File       string
+	Serializer telegraf.Serializer
+
+	// Output to forward the rejected metrics to, identified by its alias or,
+	// for outputs without alias, by its name
+	Output string
+}
+
+// DeadLetterSink receives the metrics rejected by an output.
+type DeadLetterSink interface {
+	// Add hands over the rejected metrics to the sink. The metrics are owned
+	// by the sink afterwards.
+	Add(metrics []telegraf.Metric) error
+
+	// Close releases all resources of the sink
+	Close() error
+}
+
+// deadLetterFile appends the rejected metrics to a file. The file is only
+// created when the first metrics are rejected.
+type deadLetterFile struct {
+	path       string
+	serializer telegraf.Serializer
+	file       *os.File
+	sync.Mutex
+}
+
+func newDeadLetterFile(path string, serializer telegraf.Serializer) *deadLetterFile {
+	return &deadLetterFile{
+		path:       path,
+		serializer: serializer,
+	}
+}
+
+func (d *deadLetterFile) Add(metrics []telegraf.Metric) error {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.file == nil {
+		f, err := os.OpenFile(d.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+		if err != nil {
+			return fmt.Errorf("opening dead-letter file failed: %w", err)
+		}
+		d.file = f
+	}
+
+	buf, err := d.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return fmt.Errorf("serializing metrics failed: %w", err)
+	}
+	if _, err := d.file.Write(buf); err != nil {
+		return fmt.Errorf("writing dead-letter file failed: %w", err)
+	}
+	return nil
+}
+
+func (d *deadLetterFile) Close() error {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.file == nil {
+		return nil
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+// untrackedCopy returns a copy of the metric not being subject to delivery
+// tracking, as the original metric is already rejected.
+func untrackedCopy(m telegraf.Metric) telegraf.Metric {
+	if um, ok := m.(telegraf.UnwrappableMetric); ok {
+		return um.Unwrap().Copy()
+	}
+	return m.Copy()
+}
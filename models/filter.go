@@ -142,12 +142,7 @@ func (f *Filter) Select(metric telegraf.Metric) (bool, error) {
 	}
 
 	if f.metricFilter != nil {
-		result, _, err := f.metricFilter.Eval(map[string]interface{}{
-			"name":   metric.Name(),
-			"tags":   metric.Tags(),
-			"fields": metric.Fields(),
-			"time":   metric.Time(),
-		})
+		result, _, err := f.metricFilter.Eval(CELActivation(metric))
 		if err != nil {
 			return true, err
 		}
@@ -246,6 +241,33 @@ func (f *Filter) compileMetricFilter() error {
 	}
 
 	// Declare the computation environment for the filter including custom functions
+	env, err := NewCELEnvironment()
+	if err != nil {
+		return err
+	}
+
+	// Compile the program
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return issues.Err()
+	}
+	// Check if we got a boolean expression needed for filtering
+	if ast.OutputType() != cel.BoolType {
+		return errors.New("expression needs to return a boolean")
+	}
+
+	// Get the final program
+	options := cel.EvalOptions(
+		cel.OptOptimize,
+	)
+	f.metricFilter, err = env.Program(ast, options)
+	return err
+}
+
+// NewCELEnvironment returns the computation environment for CEL expressions
+// on metrics including custom functions. The metric is accessible through the
+// "name", "tags", "fields" and "time" variables set by CELActivation.
+func NewCELEnvironment() (*cel.Env, error) {
 	env, err := cel.NewEnv(
 		cel.VariableDecls(
 			decls.NewVariable("name", types.StringType),
@@ -263,25 +285,20 @@ func (f *Filter) compileMetricFilter() error {
 		ext.Strings(),
 	)
 	if err != nil {
-		return fmt.Errorf("creating environment failed: %w", err)
+		return nil, fmt.Errorf("creating environment failed: %w", err)
 	}
+	return env, nil
+}
 
-	// Compile the program
-	ast, issues := env.Compile(expression)
-	if issues.Err() != nil {
-		return issues.Err()
+// CELActivation returns the variables of the given metric for evaluating
+// programs compiled in the environment of NewCELEnvironment.
+func CELActivation(metric telegraf.Metric) map[string]interface{} {
+	return map[string]interface{}{
+		"name":   metric.Name(),
+		"tags":   metric.Tags(),
+		"fields": metric.Fields(),
+		"time":   metric.Time(),
 	}
-	// Check if we got a boolean expression needed for filtering
-	if ast.OutputType() != cel.BoolType {
-		return errors.New("expression needs to return a boolean")
-	}
-
-	// Get the final program
-	options := cel.EvalOptions(
-		cel.OptOptimize,
-	)
-	f.metricFilter, err = env.Program(ast, options)
-	return err
 }
 
 func ShouldPassFilters(include, exclude filter.Filter, key string) bool {
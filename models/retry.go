@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const (
+	// Default upper bound of the backoff between two write attempts
+	DefaultRetryMaxInterval = 5 * time.Minute
+
+	// Default time the circuit-breaker stays open before the next attempt
+	DefaultCircuitBreakerTimeout = time.Minute
+)
+
+// Circuit-breaker states as reported by the "circuit_breaker_state" statistic
+const (
+	circuitClosed int64 = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// RetryConfig configures how an output retries failed writes. The zero value
+// retries on every flush without any limit.
+type RetryConfig struct {
+	// Backoff after the first failed write, doubled on every consecutive
+	// failure up to the maximum interval. Zero disables the backoff.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// Maximum random time added to each backoff interval
+	Jitter time.Duration
+
+	// Number of consecutive failed writes and time since the first failed
+	// write after which the failing metrics are rejected. Zero means no limit.
+	MaxAttempts int
+	MaxAge      time.Duration
+
+	// Number of consecutive failed writes opening the circuit-breaker and
+	// the time until the next write is attempted. Zero threshold disables the
+	// circuit-breaker.
+	CircuitBreakerThreshold int
+	CircuitBreakerTimeout   time.Duration
+}
+
+// retryPolicy keeps track of consecutive write failures of an output and
+// decides when to attempt the next write.
+type retryPolicy struct {
+	cfg RetryConfig
+
+	// Consecutive failed writes driving the backoff and circuit-breaker
+	failures    int
+	nextAttempt time.Time
+
+	// Failed writes and time of the first failure since the retry limits
+	// were last exceeded
+	attempts     int
+	firstFailure time.Time
+
+	Retries          selfstat.Stat
+	RetriesSkipped   selfstat.Stat
+	RetriesExhausted selfstat.Stat
+	CircuitState     selfstat.Stat
+}
+
+func newRetryPolicy(cfg RetryConfig, tags map[string]string) *retryPolicy {
+	if cfg.InitialInterval > 0 && cfg.MaxInterval == 0 {
+		cfg.MaxInterval = DefaultRetryMaxInterval
+	}
+	if cfg.CircuitBreakerThreshold > 0 && cfg.CircuitBreakerTimeout == 0 {
+		cfg.CircuitBreakerTimeout = DefaultCircuitBreakerTimeout
+	}
+
+	return &retryPolicy{
+		cfg:              cfg,
+		Retries:          selfstat.Register("write", "retries", tags),
+		RetriesSkipped:   selfstat.Register("write", "retries_skipped", tags),
+		RetriesExhausted: selfstat.Register("write", "retries_exhausted", tags),
+		CircuitState:     selfstat.Register("write", "circuit_breaker_state", tags),
+	}
+}
+
+// allow returns true if a write should be attempted at the given time. An
+// open circuit-breaker transitions to half-open once its timeout elapsed.
+func (p *retryPolicy) allow(now time.Time) bool {
+	if now.Before(p.nextAttempt) {
+		p.RetriesSkipped.Incr(1)
+		return false
+	}
+	if p.CircuitState.Get() == circuitOpen {
+		p.CircuitState.Set(circuitHalfOpen)
+	}
+	return true
+}
+
+// succeeded resets the policy after a successful write.
+func (p *retryPolicy) succeeded() {
+	p.failures = 0
+	p.nextAttempt = time.Time{}
+	p.attempts = 0
+	p.firstFailure = time.Time{}
+	p.CircuitState.Set(circuitClosed)
+}
+
+// failed records a failed write at the given time and returns true if the
+// retry limits are exceeded and the failing metrics should be given up.
+func (p *retryPolicy) failed(now time.Time) bool {
+	if p.attempts == 0 {
+		p.firstFailure = now
+	}
+	p.failures++
+	p.attempts++
+	p.Retries.Incr(1)
+
+	// Compute the next attempt based on the circuit-breaker state or the
+	// exponential backoff
+	switch {
+	case p.cfg.CircuitBreakerThreshold > 0 &&
+		(p.failures >= p.cfg.CircuitBreakerThreshold || p.CircuitState.Get() == circuitHalfOpen):
+		p.CircuitState.Set(circuitOpen)
+		p.nextAttempt = now.Add(p.cfg.CircuitBreakerTimeout + internal.RandomDuration(p.cfg.Jitter))
+	case p.cfg.InitialInterval > 0:
+		backoff := p.cfg.InitialInterval
+		for i := 1; i < p.failures && backoff < p.cfg.MaxInterval; i++ {
+			backoff *= 2
+		}
+		backoff = min(backoff, p.cfg.MaxInterval)
+		p.nextAttempt = now.Add(backoff + internal.RandomDuration(p.cfg.Jitter))
+	}
+
+	exhausted := p.cfg.MaxAttempts > 0 && p.attempts >= p.cfg.MaxAttempts
+	exhausted = exhausted || p.cfg.MaxAge > 0 && now.Sub(p.firstFailure) >= p.cfg.MaxAge
+	if exhausted {
+		// Start over with the limits for the remaining metrics but keep the
+		// backoff as the output is still failing
+		p.attempts = 0
+	}
+	return exhausted
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyDisabled(t *testing.T) {
+	p := newRetryPolicy(RetryConfig{}, map[string]string{"output": "retry_disabled"})
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		require.True(t, p.allow(now))
+		require.False(t, p.failed(now))
+	}
+	require.Equal(t, circuitClosed, p.CircuitState.Get())
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	cfg := RetryConfig{
+		InitialInterval: time.Second,
+		MaxInterval:     5 * time.Second,
+	}
+	p := newRetryPolicy(cfg, map[string]string{"output": "retry_backoff_policy"})
+
+	now := time.Unix(0, 0)
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for _, backoff := range expected {
+		require.True(t, p.allow(now))
+		require.False(t, p.failed(now))
+		require.Equal(t, now.Add(backoff), p.nextAttempt)
+		require.False(t, p.allow(now.Add(backoff-time.Nanosecond)))
+		now = now.Add(backoff)
+	}
+
+	// A successful write resets the backoff
+	p.succeeded()
+	require.True(t, p.allow(now))
+	require.False(t, p.failed(now))
+	require.Equal(t, now.Add(time.Second), p.nextAttempt)
+}
+
+func TestRetryPolicyMaxAttemptsKeepsBackoff(t *testing.T) {
+	cfg := RetryConfig{
+		InitialInterval: time.Second,
+		MaxInterval:     time.Minute,
+		MaxAttempts:     2,
+	}
+	p := newRetryPolicy(cfg, map[string]string{"output": "retry_max_attempts_backoff"})
+
+	now := time.Unix(0, 0)
+	require.False(t, p.failed(now))
+	require.Equal(t, now.Add(time.Second), p.nextAttempt)
+	now = now.Add(time.Second)
+	require.True(t, p.failed(now))
+	require.Equal(t, now.Add(2*time.Second), p.nextAttempt)
+
+	// The attempts start over after giving up but the backoff continues
+	now = now.Add(2 * time.Second)
+	require.False(t, p.failed(now))
+	require.Equal(t, now.Add(4*time.Second), p.nextAttempt)
+	now = now.Add(4 * time.Second)
+	require.True(t, p.failed(now))
+	require.Equal(t, now.Add(8*time.Second), p.nextAttempt)
+}
+
+func TestRetryPolicyMaxAge(t *testing.T) {
+	p := newRetryPolicy(RetryConfig{MaxAge: time.Minute}, map[string]string{"output": "retry_max_age"})
+
+	now := time.Unix(0, 0)
+	require.False(t, p.failed(now))
+	require.False(t, p.failed(now.Add(30*time.Second)))
+	require.True(t, p.failed(now.Add(time.Minute)))
+
+	// The age starts over after giving up
+	require.False(t, p.failed(now.Add(90*time.Second)))
+}
+
+func TestRetryPolicyCircuitBreaker(t *testing.T) {
+	cfg := RetryConfig{
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerTimeout:   time.Minute,
+	}
+	p := newRetryPolicy(cfg, map[string]string{"output": "retry_circuit_breaker"})
+
+	// The circuit opens after reaching the threshold
+	now := time.Unix(0, 0)
+	require.False(t, p.failed(now))
+	require.Equal(t, circuitClosed, p.CircuitState.Get())
+	require.True(t, p.allow(now))
+	require.False(t, p.failed(now))
+	require.Equal(t, circuitOpen, p.CircuitState.Get())
+	require.False(t, p.allow(now.Add(30*time.Second)))
+
+	// After the timeout the circuit is half-open and a failure opens it again
+	now = now.Add(time.Minute)
+	require.True(t, p.allow(now))
+	require.Equal(t, circuitHalfOpen, p.CircuitState.Get())
+	require.False(t, p.failed(now))
+	require.Equal(t, circuitOpen, p.CircuitState.Get())
+
+	// A successful write in half-open state closes the circuit
+	now = now.Add(time.Minute)
+	require.True(t, p.allow(now))
+	require.Equal(t, circuitHalfOpen, p.CircuitState.Get())
+	p.succeeded()
+	require.Equal(t, circuitClosed, p.CircuitState.Get())
+	require.True(t, p.allow(now))
+}
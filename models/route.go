@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Route sends the metrics selected by its filter exclusively to one output.
+// Routes are evaluated in order and a metric is only sent along the first
+// matching route.
+type Route struct {
+	ID     string
+	Filter Filter
+
+	// Output receiving the selected metrics and the output receiving the
+	// batches of the former if its writes fail for longer than the given
+	// duration. Outputs are identified by their alias or, for outputs without
+	// alias, by their name.
+	Output        string
+	Fallback      string
+	FallbackAfter time.Duration
+}
+
+// Match returns true if the metric is selected by the route
+func (r *Route) Match(metric telegraf.Metric) (bool, error) {
+	return r.Filter.Select(metric)
+}
+
+// FallbackConfig configures the output receiving the batches of an output
+// failing for longer than the given duration.
+type FallbackConfig struct {
+	Output string
+	After  time.Duration
+}
@@ -18,6 +18,10 @@ type RunningAggregator struct {
 	periodEnd   time.Time
 	log         telegraf.Logger
 
+	// Metrics of the sliding window, only used if the window is longer than
+	// the period
+	window []telegraf.Metric
+
 	MetricsPushed   selfstat.Stat
 	MetricsFiltered selfstat.Stat
 	MetricsDropped  selfstat.Stat
@@ -79,6 +83,10 @@ type AggregatorConfig struct {
 	Grace        time.Duration
 	LogLevel     string
 
+	// Length of the sliding window to aggregate on every period. Zero uses
+	// tumbling windows with the length of the period.
+	Window time.Duration
+
 	NameOverride      string
 	MeasurementPrefix string
 	MeasurementSuffix string
@@ -111,6 +119,20 @@ func (r *RunningAggregator) Period() time.Duration {
 	return r.Config.Period
 }
 
+// sliding returns true if the aggregator uses sliding windows
+func (r *RunningAggregator) sliding() bool {
+	return r.Config.Window > r.Config.Period
+}
+
+// windowStart returns the start of the aggregation window ending with the
+// current period
+func (r *RunningAggregator) windowStart() time.Time {
+	if r.sliding() {
+		return r.periodEnd.Add(-r.Config.Window)
+	}
+	return r.periodStart
+}
+
 func (r *RunningAggregator) EndPeriod() time.Time {
 	return r.periodEnd
 }
@@ -160,13 +182,20 @@ func (r *RunningAggregator) Add(m telegraf.Metric) bool {
 	r.Lock()
 	defer r.Unlock()
 
-	if m.Time().Before(r.periodStart.Add(-r.Config.Grace)) || m.Time().After(r.periodEnd.Add(r.Config.Delay)) {
+	if m.Time().Before(r.windowStart().Add(-r.Config.Grace)) || m.Time().After(r.periodEnd.Add(r.Config.Delay)) {
 		r.log.Debugf("Metric is outside aggregation window; discarding. %s: m: %s e: %s g: %s",
-			m.Time(), r.periodStart, r.periodEnd, r.Config.Grace)
+			m.Time(), r.windowStart(), r.periodEnd, r.Config.Grace)
 		r.MetricsDropped.Incr(1)
 		return r.Config.DropOriginal
 	}
 
+	// Keep the metrics of sliding windows as they are aggregated in
+	// multiple periods
+	if r.sliding() {
+		r.window = append(r.window, m)
+		return r.Config.DropOriginal
+	}
+
 	r.Aggregator.Add(m)
 	return r.Config.DropOriginal
 }
@@ -175,6 +204,7 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.Lock()
 	defer r.Unlock()
 
+	end := r.periodEnd
 	since := r.periodEnd
 	until := r.periodEnd.Add(r.Config.Period)
 
@@ -191,12 +221,47 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.UpdateWindow(since, until)
 
 	start := time.Now()
+	if r.sliding() {
+		r.addWindow(end)
+	}
 	r.Aggregator.Push(acc)
 	elapsed := time.Since(start)
 	r.PushTime.Incr(elapsed.Nanoseconds())
 	r.Aggregator.Reset()
 }
 
+// addWindow adds the metrics of the sliding window ending at the given time
+// to the aggregator and removes the metrics not being part of the next window.
+func (r *RunningAggregator) addWindow(end time.Time) {
+	start := end.Add(-r.Config.Window)
+	for _, m := range r.window {
+		if !m.Time().Before(start) {
+			r.Aggregator.Add(m)
+		}
+	}
+
+	next := r.windowStart().Add(-r.Config.Grace)
+	keep := r.window[:0]
+	for _, m := range r.window {
+		if !m.Time().Before(next) {
+			keep = append(keep, m)
+		}
+	}
+	clear(r.window[len(keep):])
+	r.window = keep
+}
+
 func (r *RunningAggregator) Log() telegraf.Logger {
 	return r.log
 }
+
+// Statistics returns a snapshot of the internal statistics of the plugin.
+func (r *RunningAggregator) Statistics() map[string]int64 {
+	return statistics("aggregate", "aggregator", r.Config.Name, r.Config.Alias)
+}
+
+// LastError returns the last error logged by the plugin and the time it
+// occurred. The message is empty if no error was logged.
+func (r *RunningAggregator) LastError() (string, time.Time) {
+	return lastError(r.log)
+}
@@ -239,6 +239,56 @@ func TestRunningAggregatorAddDoesNotModifyMetric(t *testing.T) {
 	testutil.RequireMetricEqual(t, expected, m)
 }
 
+func TestRunningAggregatorSlidingWindow(t *testing.T) {
+	a := &mockAggregator{}
+	ra := NewRunningAggregator(a, &AggregatorConfig{
+		Name: "TestRunningAggregator",
+		Filter: Filter{
+			NamePass: []string{"*"},
+		},
+		Period: time.Minute,
+		Window: 3 * time.Minute,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+
+	// Windows are computed from the end of the current period
+	end := time.Now().Truncate(time.Minute).Add(time.Minute)
+	ra.UpdateWindow(end.Add(-time.Minute), end)
+
+	// Metrics within the window are accepted even if before the period
+	for i, offset := range []time.Duration{-150 * time.Second, -90 * time.Second, -30 * time.Second} {
+		m := testutil.MustMetric("RITest",
+			map[string]string{},
+			map[string]interface{}{"value": int64(1) << i},
+			end.Add(offset),
+			telegraf.Untyped)
+		require.False(t, ra.Add(m))
+	}
+
+	// Metrics before the window are dropped
+	m := testutil.MustMetric("RITest",
+		map[string]string{},
+		map[string]interface{}{"value": int64(8)},
+		end.Add(-4*time.Minute),
+		telegraf.Untyped)
+	require.False(t, ra.Add(m))
+
+	// Every push aggregates the metrics of the window ending with the period
+	// and metrics leave the window over time. Set the period explicitly as
+	// push would realign the window to the current time.
+	var acc testutil.Accumulator
+	for i := range 4 {
+		until := end.Add(time.Duration(i) * time.Minute)
+		ra.UpdateWindow(until.Add(-time.Minute), until)
+		ra.Push(&acc)
+	}
+	require.Len(t, acc.Metrics, 4)
+	require.Equal(t, int64(7), acc.Metrics[0].Fields["sum"])
+	require.Equal(t, int64(6), acc.Metrics[1].Fields["sum"])
+	require.Equal(t, int64(4), acc.Metrics[2].Fields["sum"])
+	require.Equal(t, int64(0), acc.Metrics[3].Fields["sum"])
+}
+
 type mockAggregator struct {
 	sum int64
 }
@@ -272,6 +272,17 @@ func (r *RunningInput) Log() telegraf.Logger {
 	return r.log
 }
 
+// Statistics returns a snapshot of the internal statistics of the plugin.
+func (r *RunningInput) Statistics() map[string]int64 {
+	return statistics("gather", "input", r.Config.Name, r.Config.Alias)
+}
+
+// LastError returns the last error logged by the plugin and the time it
+// occurred. The message is empty if no error was logged.
+func (r *RunningInput) LastError() (string, time.Time) {
+	return lastError(r.log)
+}
+
 func (r *RunningInput) IncrGatherTimeouts() {
 	GlobalGatherTimeouts.Incr(1)
 	r.GatherTimeouts.Incr(1)
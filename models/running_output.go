@@ -39,8 +39,19 @@ type OutputConfig struct {
 	NamePrefix   string
 	NameSuffix   string
 
-	BufferStrategy  string
-	BufferDirectory string
+	BufferStrategy          string
+	BufferDirectory         string
+	BufferDiskMaxSize       int64
+	BufferDiskCompression   string
+	BufferOverflowWatermark float64
+
+	DeadLetter *DeadLetterConfig
+	Fallback   *FallbackConfig
+	Retry      RetryConfig
+
+	// Maximum number of batches written concurrently, values below two
+	// disable concurrent writes
+	MaxInflightBatches int
 
 	LogLevel string
 }
@@ -56,19 +67,29 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
-	MetricsFiltered selfstat.Stat
-	WriteTime       selfstat.Stat
-	StartupErrors   selfstat.Stat
+	MetricsFiltered     selfstat.Stat
+	MetricsDeadLettered selfstat.Stat
+	MetricsFallback     selfstat.Stat
+	WriteTime           selfstat.Stat
+	StartupErrors       selfstat.Stat
 
 	BatchReady chan time.Time
 
-	buffer Buffer
-	log    telegraf.Logger
+	buffer     Buffer
+	deadLetter DeadLetterSink
+	fallback   DeadLetterSink
+	retry      *retryPolicy
+	log        telegraf.Logger
+
+	// Start of the consecutive write failures used to hand over batches to
+	// the fallback output
+	failingSince time.Time
 
 	started bool
 	retries uint64
 
-	aggMutex sync.Mutex
+	aggMutex   sync.Mutex
+	retryMutex sync.Mutex
 }
 
 func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, bufferLimit int) *RunningOutput {
@@ -100,13 +121,7 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		batchSize = DefaultMetricBatchSize
 	}
 
-	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory)
-	if err != nil {
-		panic(err)
-	}
-
 	ro := &RunningOutput{
-		buffer:            b,
 		BatchReady:        make(chan time.Time, 1),
 		Output:            output,
 		Config:            config,
@@ -117,6 +132,16 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"metrics_filtered",
 			tags,
 		),
+		MetricsDeadLettered: selfstat.Register(
+			"write",
+			"metrics_dead_lettered",
+			tags,
+		),
+		MetricsFallback: selfstat.Register(
+			"write",
+			"metrics_fallback",
+			tags,
+		),
 		WriteTime: selfstat.RegisterTiming(
 			"write",
 			"write_time_ns",
@@ -127,12 +152,40 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"startup_errors",
 			tags,
 		),
-		log: logger,
+		retry: newRetryPolicy(config.Retry, tags),
+		log:   logger,
+	}
+	if config.DeadLetter != nil && config.DeadLetter.File != "" {
+		ro.deadLetter = newDeadLetterFile(config.DeadLetter.File, config.DeadLetter.Serializer)
+	}
+
+	// Buffers storing the metrics on disk are created when initializing the
+	// output to not hold on to the files of outputs never started, e.g. if
+	// the output is unchanged when reloading the configuration.
+	if config.BufferStrategy == "" || config.BufferStrategy == "memory" {
+		//nolint:errcheck // cannot error for memory buffers
+		ro.buffer, _ = NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, DiskBufferConfig{})
 	}
 
 	return ro
 }
 
+// diskBufferConfig returns the settings of buffers storing metrics on disk
+func (c *OutputConfig) diskBufferConfig() DiskBufferConfig {
+	return DiskBufferConfig{
+		Directory:   c.BufferDirectory,
+		MaxSize:     c.BufferDiskMaxSize,
+		Compression: c.BufferDiskCompression,
+
+		OverflowWatermark: c.BufferOverflowWatermark,
+	}
+}
+
+// ValidateBuffer checks the buffer settings without creating the buffer.
+func (c *OutputConfig) ValidateBuffer() error {
+	return ValidateBufferConfig(c.BufferStrategy, c.diskBufferConfig())
+}
+
 func (r *RunningOutput) LogName() string {
 	return logName("outputs", r.Config.Name, r.Config.Alias)
 }
@@ -162,9 +215,28 @@ func (r *RunningOutput) Init() error {
 			return err
 		}
 	}
+
+	if r.buffer == nil {
+		b, err := NewBuffer(r.Config.Name, r.Config.ID, r.Config.Alias, r.MetricBufferLimit, r.Config.BufferStrategy, r.Config.diskBufferConfig())
+		if err != nil {
+			return fmt.Errorf("creating buffer failed: %w", err)
+		}
+		r.buffer = b
+	}
+
+	if r.Config.MaxInflightBatches > 1 && !r.concurrentWrites() {
+		r.log.Warn("Plugin does not support concurrent writes, ignoring 'max_inflight_batches' setting")
+	}
 	return nil
 }
 
+// concurrentWrites returns true if the plugin allows to write multiple
+// batches concurrently.
+func (r *RunningOutput) concurrentWrites() bool {
+	p, ok := r.Output.(telegraf.ConcurrentOutput)
+	return ok && p.SupportsConcurrentWrites()
+}
+
 func (r *RunningOutput) Connect() error {
 	// Try to connect and exit early on success
 	err := r.Output.Connect()
@@ -201,6 +273,46 @@ func (r *RunningOutput) Close() {
 		r.log.Errorf("Error closing output: %v", err)
 	}
 
+	if r.buffer != nil {
+		if err := r.buffer.Close(); err != nil {
+			r.log.Errorf("Error closing output buffer: %v", err)
+		}
+	}
+
+	if r.deadLetter != nil {
+		if err := r.deadLetter.Close(); err != nil {
+			r.log.Errorf("Error closing dead-letter sink: %v", err)
+		}
+	}
+}
+
+// SetDeadLetterSink sets the sink receiving the metrics rejected by the
+// output.
+func (r *RunningOutput) SetDeadLetterSink(sink DeadLetterSink) {
+	r.deadLetter = sink
+}
+
+// SetFallbackSink sets the sink receiving the batches of the output if its
+// writes fail for longer than configured.
+func (r *RunningOutput) SetFallbackSink(sink DeadLetterSink) {
+	r.fallback = sink
+}
+
+// HasName returns true if the output is identified by the given name, i.e.
+// by its alias or, if no alias is set, by its plugin name.
+func (r *RunningOutput) HasName(name string) bool {
+	if r.Config.Alias != "" {
+		return r.Config.Alias == name
+	}
+	return r.Config.Name == name
+}
+
+// Discard releases the resources of an output that was never connected, e.g.
+// because an identical output is already running.
+func (r *RunningOutput) Discard() {
+	if r.buffer == nil {
+		return
+	}
 	if err := r.buffer.Close(); err != nil {
 		r.log.Errorf("Error closing output buffer: %v", err)
 	}
@@ -302,25 +414,73 @@ func (r *RunningOutput) Write() error {
 
 	atomic.StoreInt64(&r.newMetricsCount, 0)
 
+	if !r.retry.allow(time.Now()) {
+		r.log.Debugf("Skipping write until %s due to previous failures", r.retry.nextAttempt.Format(time.RFC3339))
+		return nil
+	}
+
 	// Only process the metrics in the buffer now. Metrics added while we are
 	// writing will be sent on the next call.
 	nBuffer := r.buffer.Len()
 	nBatches := nBuffer/r.MetricBatchSize + 1
+	if r.Config.MaxInflightBatches > 1 && r.concurrentWrites() {
+		return r.writeConcurrently(nBatches)
+	}
 	for i := 0; i < nBatches; i++ {
 		tx := r.buffer.BeginTransaction(r.MetricBatchSize)
 		if len(tx.Batch) == 0 {
 			return nil
 		}
-		err := r.writeMetrics(tx.Batch)
-		r.updateTransaction(tx, err)
-		r.buffer.EndTransaction(tx)
-		if err != nil {
+		if err := r.writeTransaction(tx); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// writeConcurrently writes up to the given number of batches with at most
+// MaxInflightBatches transactions being outstanding at the same time. No new
+// batches are started after the first failed write.
+func (r *RunningOutput) writeConcurrently(nBatches int) error {
+	slots := make(chan struct{}, r.Config.MaxInflightBatches)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for i := 0; i < nBatches; i++ {
+		// Wait for a free slot before taking the metrics from the buffer to
+		// not hold them in outstanding transactions unnecessarily
+		slots <- struct{}{}
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		tx := r.buffer.BeginTransaction(r.MetricBatchSize)
+		if len(tx.Batch) == 0 {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := r.writeTransaction(tx); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
 // WriteBatch writes a single batch of metrics to the output.
 func (r *RunningOutput) WriteBatch() error {
 	// Try to connect if we are not yet started up
@@ -334,12 +494,32 @@ func (r *RunningOutput) WriteBatch() error {
 		r.log.Debugf("Successfully connected after %d attempts", r.retries)
 	}
 
+	if !r.retry.allow(time.Now()) {
+		r.log.Debugf("Skipping write until %s due to previous failures", r.retry.nextAttempt.Format(time.RFC3339))
+		return nil
+	}
+
 	tx := r.buffer.BeginTransaction(r.MetricBatchSize)
 	if len(tx.Batch) == 0 {
 		return nil
 	}
+	return r.writeTransaction(tx)
+}
+
+// writeTransaction writes the batch of the transaction to the output and
+// ends the transaction according to the result.
+func (r *RunningOutput) writeTransaction(tx *Transaction) error {
 	err := r.writeMetrics(tx.Batch)
 	r.updateTransaction(tx, err)
+
+	// Determine the failure before the fallback output accepts any metrics
+	// so the retry policy sees the actual result of the write. Writes keeping
+	// metrics count as failed even if some metrics were accepted, except for
+	// outputs deferring metrics due to their size limit.
+	failed := err != nil && len(tx.InferKeep()) > 0 && !errors.Is(err, internal.ErrSizeLimitReached)
+	r.updateFallback(tx, failed)
+	r.updateRetry(tx, failed)
+	r.forwardRejected(tx)
 	r.buffer.EndTransaction(tx)
 
 	return err
@@ -383,11 +563,99 @@ func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
 	tx.Reject = writeErr.MetricsReject
 }
 
+// updateRetry records the result of the write in the retry policy and
+// rejects the kept metrics of the transaction if the retry limits are exceeded.
+// Metrics already handed over to the fallback output are not rejected.
+func (r *RunningOutput) updateRetry(tx *Transaction, failed bool) {
+	r.retryMutex.Lock()
+	defer r.retryMutex.Unlock()
+
+	if !failed {
+		r.retry.succeeded()
+		return
+	}
+	if !r.retry.failed(time.Now()) {
+		return
+	}
+	keep := tx.InferKeep()
+	if len(keep) == 0 {
+		return
+	}
+
+	r.log.Errorf("Giving up on %d metrics after exceeding the retry limits", len(keep))
+	r.retry.RetriesExhausted.Incr(int64(len(keep)))
+	tx.Reject = append(tx.Reject, keep...)
+}
+
+// updateFallback hands over the kept metrics of the transaction to the
+// fallback output if the writes failed for longer than configured. The
+// metrics are accepted by this output as their delivery is now up to the
+// fallback output.
+func (r *RunningOutput) updateFallback(tx *Transaction, failed bool) {
+	if r.fallback == nil || r.Config.Fallback == nil {
+		return
+	}
+
+	r.retryMutex.Lock()
+	if !failed {
+		r.failingSince = time.Time{}
+		r.retryMutex.Unlock()
+		return
+	}
+	now := time.Now()
+	if r.failingSince.IsZero() {
+		r.failingSince = now
+	}
+	exceeded := now.Sub(r.failingSince) >= r.Config.Fallback.After
+	r.retryMutex.Unlock()
+	if !exceeded {
+		return
+	}
+	keep := tx.InferKeep()
+
+	// Copies keep the tracking information so the delivery of tracking
+	// metrics is only reported after the fallback output wrote them
+	metrics := make([]telegraf.Metric, 0, len(keep))
+	for _, idx := range keep {
+		metrics = append(metrics, tx.Batch[idx].Copy())
+	}
+	if err := r.fallback.Add(metrics); err != nil {
+		r.log.Errorf("Handing over %d metrics to fallback output failed: %v", len(metrics), err)
+		for _, m := range metrics {
+			m.Drop()
+		}
+		return
+	}
+	r.MetricsFallback.Incr(int64(len(metrics)))
+	tx.Accept = append(tx.Accept, keep...)
+}
+
+// forwardRejected hands over copies of the metrics rejected in the
+// transaction to the dead-letter sink if any.
+func (r *RunningOutput) forwardRejected(tx *Transaction) {
+	if r.deadLetter == nil || len(tx.Reject) == 0 {
+		return
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(tx.Reject))
+	for _, idx := range tx.Reject {
+		metrics = append(metrics, untrackedCopy(tx.Batch[idx]))
+	}
+	if err := r.deadLetter.Add(metrics); err != nil {
+		r.log.Errorf("Forwarding %d rejected metrics to dead-letter sink failed: %v", len(metrics), err)
+		return
+	}
+	r.MetricsDeadLettered.Incr(int64(len(metrics)))
+}
+
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
-	if r.Config.BufferStrategy == "disk" {
+	switch r.Config.BufferStrategy {
+	case "disk":
 		r.log.Debugf("Buffer fullness: %d metrics", nBuffer)
-	} else {
+	case "overflow":
+		r.log.Debugf("Buffer fullness: %d metrics, memory limit %d metrics", nBuffer, r.MetricBufferLimit)
+	default:
 		r.log.Debugf("Buffer fullness: %d / %d metrics", nBuffer, r.MetricBufferLimit)
 	}
 }
@@ -396,6 +664,17 @@ func (r *RunningOutput) Log() telegraf.Logger {
 	return r.log
 }
 
+// Statistics returns a snapshot of the internal statistics of the plugin.
+func (r *RunningOutput) Statistics() map[string]int64 {
+	return statistics("write", "output", r.Config.Name, r.Config.Alias)
+}
+
+// LastError returns the last error logged by the plugin and the time it
+// occurred. The message is empty if no error was logged.
+func (r *RunningOutput) LastError() (string, time.Time) {
+	return lastError(r.log)
+}
+
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
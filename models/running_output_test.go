@@ -2,7 +2,10 @@ package models
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -431,16 +434,22 @@ func TestRunningOutputInternalMetrics(t *testing.T) {
 				"alias":  "test_alias",
 			},
 			map[string]interface{}{
-				"buffer_limit":     10,
-				"buffer_size":      0,
-				"errors":           0,
-				"metrics_added":    0,
-				"metrics_rejected": 0,
-				"metrics_dropped":  0,
-				"metrics_filtered": 0,
-				"metrics_written":  0,
-				"write_time_ns":    0,
-				"startup_errors":   0,
+				"buffer_limit":          10,
+				"buffer_size":           0,
+				"circuit_breaker_state": 0,
+				"errors":                0,
+				"metrics_added":         0,
+				"metrics_rejected":      0,
+				"metrics_dead_lettered": 0,
+				"metrics_dropped":       0,
+				"metrics_fallback":      0,
+				"metrics_filtered":      0,
+				"metrics_written":       0,
+				"retries":               0,
+				"retries_exhausted":     0,
+				"retries_skipped":       0,
+				"write_time_ns":         0,
+				"startup_errors":        0,
 			},
 			time.Unix(0, 0),
 		),
@@ -864,6 +873,358 @@ func TestRunningOutputWriteBatchPartialSuccessAndLoss(t *testing.T) {
 	require.Zero(t, model.buffer.Len())
 }
 
+func TestRunningOutputDeadLetterSink(t *testing.T) {
+	lost := 0
+	plugin := &mockOutput{
+		batchAcceptSize:  4,
+		metricFatalIndex: &lost,
+	}
+	model := NewRunningOutput(plugin, &OutputConfig{Name: "dead_letter_sink"}, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	sink := &mockDeadLetterSink{}
+	model.SetDeadLetterSink(sink)
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// The rejected metric should end up in the sink
+	require.ErrorIs(t, model.Write(), internal.ErrSizeLimitReached)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{first5[0]}, sink.metrics)
+	require.Equal(t, int64(1), model.MetricsDeadLettered.Get())
+
+	// Failures of the sink must not affect the buffer handling
+	for _, metric := range next5 {
+		model.AddMetric(metric)
+	}
+	sink.err = errors.New("sink failed")
+	require.ErrorIs(t, model.Write(), internal.ErrSizeLimitReached)
+	require.Len(t, sink.metrics, 1)
+	require.Equal(t, int64(1), model.MetricsDeadLettered.Get())
+	require.Equal(t, 2, model.buffer.Len())
+}
+
+func TestRunningOutputDeadLetterFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rejected.out")
+
+	serializer := &mockSerializer{}
+	conf := &OutputConfig{
+		Name: "dead_letter_file",
+		DeadLetter: &DeadLetterConfig{
+			File:       filename,
+			Serializer: serializer,
+		},
+	}
+
+	lost := 1
+	plugin := &mockOutput{
+		batchAcceptSize:  4,
+		metricFatalIndex: &lost,
+	}
+	model := NewRunningOutput(plugin, conf, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+
+	// The file must only be created if metrics are rejected
+	require.NoFileExists(t, filename)
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+	require.ErrorIs(t, model.Write(), internal.ErrSizeLimitReached)
+	model.Close()
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, "metric2\n", string(buf))
+}
+
+func TestRunningOutputRetryMaxAttempts(t *testing.T) {
+	plugin := &mockOutput{batchAcceptSize: -1}
+	conf := &OutputConfig{
+		Name:  "retry_max_attempts",
+		Retry: RetryConfig{MaxAttempts: 2},
+	}
+	model := NewRunningOutput(plugin, conf, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	sink := &mockDeadLetterSink{}
+	model.SetDeadLetterSink(sink)
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// The metrics should be kept for the first attempt
+	require.Error(t, model.Write())
+	require.Equal(t, 5, model.buffer.Len())
+	require.Empty(t, sink.metrics)
+
+	// After the second attempt the metrics should be given up
+	require.Error(t, model.Write())
+	require.Zero(t, model.buffer.Len())
+	testutil.RequireMetricsEqual(t, first5, sink.metrics)
+	require.Equal(t, int64(2), model.retry.Retries.Get())
+	require.Equal(t, int64(5), model.retry.RetriesExhausted.Get())
+}
+
+func TestRunningOutputRetryBackoff(t *testing.T) {
+	plugin := &mockOutput{batchAcceptSize: -1}
+	conf := &OutputConfig{
+		Name:  "retry_backoff",
+		Retry: RetryConfig{InitialInterval: time.Hour},
+	}
+	model := NewRunningOutput(plugin, conf, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// The first write fails and the next one must be skipped due to backoff
+	require.Error(t, model.Write())
+	require.Equal(t, 1, plugin.writes)
+	require.NoError(t, model.Write())
+	require.Equal(t, 1, plugin.writes)
+	require.Equal(t, int64(1), model.retry.RetriesSkipped.Get())
+	require.Equal(t, 5, model.buffer.Len())
+}
+
+func TestRunningOutputFallback(t *testing.T) {
+	plugin := &mockOutput{batchAcceptSize: -1}
+	conf := &OutputConfig{
+		Name:     "fallback",
+		Fallback: &FallbackConfig{Output: "backup", After: time.Hour},
+	}
+	model := NewRunningOutput(plugin, conf, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	sink := &mockDeadLetterSink{}
+	model.SetFallbackSink(sink)
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// The metrics should be kept as long as the threshold is not exceeded
+	require.Error(t, model.Write())
+	require.Equal(t, 5, model.buffer.Len())
+	require.Empty(t, sink.metrics)
+
+	// Afterwards the batch should be handed over to the fallback
+	model.failingSince = time.Now().Add(-2 * time.Hour)
+	require.Error(t, model.Write())
+	require.Zero(t, model.buffer.Len())
+	testutil.RequireMetricsEqual(t, first5, sink.metrics)
+	require.Equal(t, int64(5), model.MetricsFallback.Get())
+
+	// A successful write must reset the failure time
+	plugin.batchAcceptSize = 0
+	model.AddMetric(next5[0])
+	require.NoError(t, model.Write())
+	require.True(t, model.failingSince.IsZero())
+}
+
+func TestRunningOutputRetryPartialWrite(t *testing.T) {
+	plugin := &mockOutput{
+		batchAcceptSize: 2,
+		partialWriteErr: errors.New("service unavailable"),
+	}
+	conf := &OutputConfig{
+		Name:     "retry_partial_write",
+		Retry:    RetryConfig{InitialInterval: time.Hour},
+		Fallback: &FallbackConfig{Output: "backup", After: time.Hour},
+	}
+	model := NewRunningOutput(plugin, conf, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+	model.SetFallbackSink(&mockDeadLetterSink{})
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// A write accepting some metrics but failing with a retryable error
+	// must engage the retry policy and the fallback timer
+	require.Error(t, model.Write())
+	require.Len(t, plugin.Metrics(), 2)
+	require.Equal(t, 3, model.buffer.Len())
+	require.Equal(t, int64(1), model.retry.Retries.Get())
+	require.False(t, model.failingSince.IsZero())
+
+	// The next write is skipped due to the backoff
+	require.NoError(t, model.Write())
+	require.Len(t, plugin.Metrics(), 2)
+	require.Equal(t, 3, model.buffer.Len())
+	require.Equal(t, int64(1), model.retry.RetriesSkipped.Get())
+}
+
+func TestRunningOutputFallbackWithRetryLimits(t *testing.T) {
+	plugin := &mockOutput{batchAcceptSize: -1}
+	conf := &OutputConfig{
+		Name:     "fallback_retry",
+		Retry:    RetryConfig{MaxAttempts: 2},
+		Fallback: &FallbackConfig{Output: "backup", After: time.Hour},
+	}
+	model := NewRunningOutput(plugin, conf, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	fallback := &mockDeadLetterSink{}
+	model.SetFallbackSink(fallback)
+	deadLetter := &mockDeadLetterSink{}
+	model.SetDeadLetterSink(deadLetter)
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// The metrics should be kept for the first attempt
+	require.Error(t, model.Write())
+	require.Equal(t, 5, model.buffer.Len())
+
+	// Exhausting the retries must not reject the metrics if the fallback
+	// takes over the batch in the same write
+	model.failingSince = time.Now().Add(-2 * time.Hour)
+	require.Error(t, model.Write())
+	require.Zero(t, model.buffer.Len())
+	testutil.RequireMetricsEqual(t, first5, fallback.metrics)
+	require.Empty(t, deadLetter.metrics)
+	require.Equal(t, int64(5), model.MetricsFallback.Get())
+	require.Equal(t, int64(2), model.retry.Retries.Get())
+	require.Zero(t, model.retry.RetriesExhausted.Get())
+}
+
+func TestRunningOutputMaxInflightBatches(t *testing.T) {
+	plugin := &blockingOutput{concurrent: true, release: make(chan struct{})}
+	conf := &OutputConfig{
+		Name:               "max_inflight_batches",
+		MaxInflightBatches: 3,
+	}
+	model := NewRunningOutput(plugin, conf, 5, 20)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	for _, metric := range append(first5, next5...) {
+		model.AddMetric(metric)
+	}
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+
+	// All batches must be written concurrently
+	done := make(chan error)
+	go func() {
+		done <- model.Write()
+	}()
+	require.Eventually(t, func() bool {
+		return plugin.active.Load() == 3
+	}, 3*time.Second, 10*time.Millisecond)
+	close(plugin.release)
+	require.NoError(t, <-done)
+
+	require.Len(t, plugin.Metrics(), 15)
+	require.Zero(t, model.buffer.Len())
+}
+
+func TestRunningOutputMaxInflightBatchesFailure(t *testing.T) {
+	plugin := &concurrentMockOutput{&mockOutput{batchAcceptSize: -1}}
+	conf := &OutputConfig{
+		Name:               "max_inflight_batches_failure",
+		MaxInflightBatches: 2,
+	}
+	model := NewRunningOutput(plugin, conf, 5, 20)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	for _, metric := range append(first5, next5...) {
+		model.AddMetric(metric)
+	}
+
+	// Failed batches must be kept in the buffer
+	require.Error(t, model.Write())
+	require.Equal(t, 10, model.buffer.Len())
+	require.Empty(t, plugin.Metrics())
+}
+
+func TestRunningOutputMaxInflightBatchesNotSupported(t *testing.T) {
+	plugin := &blockingOutput{release: make(chan struct{})}
+	close(plugin.release)
+	conf := &OutputConfig{
+		Name:               "max_inflight_batches_not_supported",
+		MaxInflightBatches: 3,
+	}
+	model := NewRunningOutput(plugin, conf, 5, 20)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	for _, metric := range append(first5, next5...) {
+		model.AddMetric(metric)
+	}
+
+	// Plugins not supporting concurrent writes must be written sequentially
+	require.NoError(t, model.Write())
+	require.Len(t, plugin.Metrics(), 10)
+	require.Equal(t, int64(1), plugin.maxActive.Load())
+	require.Zero(t, model.buffer.Len())
+}
+
+func TestRunningOutputDiskBufferCreatedOnInit(t *testing.T) {
+	dir := t.TempDir()
+	conf := &OutputConfig{
+		Name:            "disk_buffer_on_init",
+		ID:              "disk-buffer-on-init",
+		BufferStrategy:  "disk",
+		BufferDirectory: dir,
+	}
+
+	// Outputs never started must not touch the buffer files
+	discarded := NewRunningOutput(&mockOutput{}, conf, 5, 10)
+	discarded.Discard()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	model := NewRunningOutput(&mockOutput{}, conf, 5, 10)
+	require.NoError(t, model.Init())
+	defer model.Close()
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	model.AddMetric(first5[0])
+	require.Equal(t, 1, model.BufferLength())
+}
+
+func TestRunningOutputInvalidBufferSettings(t *testing.T) {
+	conf := &OutputConfig{
+		Name:                  "invalid_buffer_settings",
+		ID:                    "invalid-buffer-settings",
+		BufferStrategy:        "disk",
+		BufferDirectory:       t.TempDir(),
+		BufferDiskCompression: "lz4",
+	}
+	require.ErrorContains(t, conf.ValidateBuffer(), `invalid buffer compression "lz4"`)
+
+	model := NewRunningOutput(&mockOutput{}, conf, 5, 10)
+	require.ErrorContains(t, model.Init(), `invalid buffer compression "lz4"`)
+	model.Discard()
+}
+
 // Benchmark adding metrics.
 func BenchmarkRunningOutputAddWrite(b *testing.B) {
 	conf := &OutputConfig{
@@ -914,6 +1275,7 @@ type mockOutput struct {
 	// Failing output simulation
 	batchAcceptSize  int
 	metricFatalIndex *int
+	partialWriteErr  error
 
 	// Startup error simulation
 	startupError      error
@@ -940,11 +1302,11 @@ func (*mockOutput) SampleConfig() string {
 }
 
 func (m *mockOutput) Write(metrics []telegraf.Metric) error {
-	m.writes++
-
 	m.Lock()
 	defer m.Unlock()
 
+	m.writes++
+
 	// Simulate a failed write
 	if m.batchAcceptSize < 0 {
 		return errors.New("failed write")
@@ -958,6 +1320,9 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 
 	// Simulate a partially successful write
 	werr := &internal.PartialWriteError{Err: internal.ErrSizeLimitReached}
+	if m.partialWriteErr != nil {
+		werr.Err = m.partialWriteErr
+	}
 	for i, x := range metrics {
 		if m.metricFatalIndex != nil && i == *m.metricFatalIndex {
 			werr.MetricsReject = append(werr.MetricsReject, i)
@@ -975,6 +1340,65 @@ func (m *mockOutput) Metrics() []telegraf.Metric {
 	return m.metrics
 }
 
+// concurrentMockOutput is a mockOutput allowing concurrent writes
+type concurrentMockOutput struct {
+	*mockOutput
+}
+
+func (*concurrentMockOutput) SupportsConcurrentWrites() bool {
+	return true
+}
+
+// blockingOutput blocks all writes until released
+type blockingOutput struct {
+	sync.Mutex
+
+	metrics    []telegraf.Metric
+	active     atomic.Int64
+	maxActive  atomic.Int64
+	release    chan struct{}
+	concurrent bool
+}
+
+func (m *blockingOutput) SupportsConcurrentWrites() bool {
+	return m.concurrent
+}
+
+func (*blockingOutput) Connect() error {
+	return nil
+}
+
+func (*blockingOutput) Close() error {
+	return nil
+}
+
+func (*blockingOutput) SampleConfig() string {
+	return ""
+}
+
+func (m *blockingOutput) Write(metrics []telegraf.Metric) error {
+	active := m.active.Add(1)
+	defer m.active.Add(-1)
+	for {
+		current := m.maxActive.Load()
+		if active <= current || m.maxActive.CompareAndSwap(current, active) {
+			break
+		}
+	}
+	<-m.release
+
+	m.Lock()
+	defer m.Unlock()
+	m.metrics = append(m.metrics, metrics...)
+	return nil
+}
+
+func (m *blockingOutput) Metrics() []telegraf.Metric {
+	m.Lock()
+	defer m.Unlock()
+	return m.metrics
+}
+
 type perfOutput struct {
 	// if true, mock write failure
 	failWrite bool
@@ -998,3 +1422,38 @@ func (m *perfOutput) Write([]telegraf.Metric) error {
 	}
 	return nil
 }
+
+type mockDeadLetterSink struct {
+	metrics []telegraf.Metric
+	err     error
+}
+
+func (m *mockDeadLetterSink) Add(metrics []telegraf.Metric) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.metrics = append(m.metrics, metrics...)
+	return nil
+}
+
+func (*mockDeadLetterSink) Close() error {
+	return nil
+}
+
+type mockSerializer struct{}
+
+func (*mockSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return []byte(metric.Name() + "\n"), nil
+}
+
+func (m *mockSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, metric := range metrics {
+		b, err := m.Serialize(metric)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
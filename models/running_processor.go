@@ -2,6 +2,7 @@ package models
 
 import (
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	logging "github.com/influxdata/telegraf/logger"
@@ -80,6 +81,17 @@ func (rp *RunningProcessor) Log() telegraf.Logger {
 	return rp.log
 }
 
+// Statistics returns a snapshot of the internal statistics of the plugin.
+func (rp *RunningProcessor) Statistics() map[string]int64 {
+	return statistics("process", "processor", rp.Config.Name, rp.Config.Alias)
+}
+
+// LastError returns the last error logged by the plugin and the time it
+// occurred. The message is empty if no error was logged.
+func (rp *RunningProcessor) LastError() (string, time.Time) {
+	return lastError(rp.log)
+}
+
 func (rp *RunningProcessor) LogName() string {
 	return logName("processors", rp.Config.Name, rp.Config.Alias)
 }
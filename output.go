@@ -27,3 +27,14 @@ type AggregatingOutput interface {
 	// Reset signals that the aggregator period is completed.
 	Reset()
 }
+
+// ConcurrentOutput is an Output supporting concurrent calls to its Write
+// function. Only outputs implementing this interface write multiple batches
+// at the same time if the output is configured with `max_inflight_batches`.
+type ConcurrentOutput interface {
+	Output
+
+	// SupportsConcurrentWrites returns true if Write is safe to be called
+	// concurrently for different batches of metrics.
+	SupportsConcurrentWrites() bool
+}
@@ -4,15 +4,35 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 )
 
+// StateFileVersion is the version of the state file format written by the
+// persister. State files of older versions are migrated when loading.
+const StateFileVersion = 1
+
+// stateFile is the on-disk representation of the states. Unversioned files
+// written by older Telegraf releases consist of the states map only.
+type stateFile struct {
+	Version int               `json:"version"`
+	Created time.Time         `json:"created"`
+	States  map[string][]byte `json:"states"`
+}
+
 type Persister struct {
 	Filename string
 
+	// Interval for periodically storing the states in addition to storing
+	// them on shutdown, zero disables the checkpoints.
+	Interval time.Duration
+
 	register map[string]telegraf.StatefulPlugin
+	sync.Mutex
 }
 
 func (p *Persister) Init() error {
@@ -22,6 +42,9 @@ func (p *Persister) Init() error {
 }
 
 func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
+	p.Lock()
+	defer p.Unlock()
+
 	if _, found := p.register[id]; found {
 		return fmt.Errorf("plugin with ID %q already registered", id)
 	}
@@ -30,7 +53,17 @@ func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
 	return nil
 }
 
+func (p *Persister) Unregister(id string) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.register, id)
+}
+
 func (p *Persister) Load() error {
+	p.Lock()
+	defer p.Unlock()
+
 	// Read the states from disk
 	in, err := os.ReadFile(p.Filename)
 	if err != nil {
@@ -38,9 +71,9 @@ func (p *Persister) Load() error {
 	}
 
 	// Unmarshal the id to serialized states map
-	var states map[string][]byte
-	if err := json.Unmarshal(in, &states); err != nil {
-		return fmt.Errorf("unmarshalling states failed: %w", err)
+	states, err := decode(in)
+	if err != nil {
+		return err
 	}
 
 	// Get the initialized state as blueprint for unmarshalling
@@ -70,6 +103,9 @@ func (p *Persister) Load() error {
 }
 
 func (p *Persister) Store() error {
+	p.Lock()
+	defer p.Unlock()
+
 	states := make(map[string][]byte)
 
 	// Collect the states and serialize the individual data chunks
@@ -83,21 +119,70 @@ func (p *Persister) Store() error {
 	}
 
 	// Serialize the states
-	serialized, err := json.Marshal(states)
+	serialized, err := json.Marshal(stateFile{
+		Version: StateFileVersion,
+		Created: time.Now().UTC(),
+		States:  states,
+	})
 	if err != nil {
 		return fmt.Errorf("marshalling states failed: %w", err)
 	}
 
-	// Write the states to disk
-	f, err := os.Create(p.Filename)
+	return writeAtomic(p.Filename, serialized)
+}
+
+// decode unmarshals the states of the given state file content and migrates
+// the states of older file versions.
+func decode(in []byte) (map[string][]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(in, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+
+	// Files without version are the plain states map of older releases.
+	// Plugin IDs are hashes so they cannot collide with the version key.
+	if _, found := raw["version"]; !found {
+		var states map[string][]byte
+		if err := json.Unmarshal(in, &states); err != nil {
+			return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+		}
+		return states, nil
+	}
+
+	var file stateFile
+	if err := json.Unmarshal(in, &file); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+	if file.Version < 1 || file.Version > StateFileVersion {
+		return nil, fmt.Errorf("unsupported states file version %d", file.Version)
+	}
+	return file.States, nil
+}
+
+// writeAtomic writes the data to a temporary file next to the given file and
+// renames it afterwards, so a crash never leaves a partially written file.
+func writeAtomic(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	f, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp-*")
 	if err != nil {
-		return fmt.Errorf("creating states file %q failed: %w", p.Filename, err)
+		return fmt.Errorf("creating temporary states file failed: %w", err)
 	}
-	defer f.Close()
+	tmpfile := f.Name()
+	defer os.Remove(tmpfile)
 
-	if _, err := f.Write(serialized); err != nil {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
 		return fmt.Errorf("writing states failed: %w", err)
 	}
-
-	return nil
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing states failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing states file failed: %w", err)
+	}
+	if err := os.Rename(tmpfile, filename); err != nil {
+		return fmt.Errorf("renaming states file failed: %w", err)
+	}
+	return syncDir(dir)
 }
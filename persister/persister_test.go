@@ -0,0 +1,83 @@
+package persister
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockState struct {
+	Offset uint64 `json:"offset"`
+}
+
+type mockPlugin struct {
+	state mockState
+}
+
+func (m *mockPlugin) GetState() interface{} {
+	return m.state
+}
+
+func (m *mockPlugin) SetState(state interface{}) error {
+	m.state = state.(mockState)
+	return nil
+}
+
+func TestStoreLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	store := &Persister{Filename: filename}
+	require.NoError(t, store.Init())
+	require.NoError(t, store.Register("a", &mockPlugin{state: mockState{Offset: 42}}))
+	require.NoError(t, store.Store())
+
+	// Storing again must replace the file without leaving temporary files
+	require.NoError(t, store.Store())
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// The file must contain the current version
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	var file stateFile
+	require.NoError(t, json.Unmarshal(buf, &file))
+	require.Equal(t, StateFileVersion, file.Version)
+
+	plugin := &mockPlugin{}
+	load := &Persister{Filename: filename}
+	require.NoError(t, load.Init())
+	require.NoError(t, load.Register("a", plugin))
+	require.NoError(t, load.Load())
+	require.Equal(t, mockState{Offset: 42}, plugin.state)
+}
+
+func TestLoadUnversioned(t *testing.T) {
+	// Files of older releases only contain the map of serialized states
+	state, err := json.Marshal(mockState{Offset: 23})
+	require.NoError(t, err)
+	buf, err := json.Marshal(map[string][]byte{"a": state})
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(filename, buf, 0600))
+
+	plugin := &mockPlugin{}
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", plugin))
+	require.NoError(t, p.Load())
+	require.Equal(t, mockState{Offset: 23}, plugin.state)
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"version": 999, "states": {}}`), 0600))
+
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.ErrorContains(t, p.Load(), "unsupported states file version 999")
+}
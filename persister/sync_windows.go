@@ -0,0 +1,8 @@
+//go:build windows
+
+package persister
+
+// syncDir is a no-op as directories cannot be synced on Windows
+func syncDir(string) error {
+	return nil
+}
@@ -50,6 +50,8 @@ type StatefulPlugin interface {
 	// your plugin.
 	// Note: This function has to be callable directly after the
 	// plugin's Init() function if there is any!
+	// Note: If the state is stored periodically, this function is called
+	// concurrently to the other functions of the plugin!
 	GetState() interface{}
 
 	// SetState is called by the Persister once after loading and
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.exponential_histogram
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/exponential_histogram" // register plugin
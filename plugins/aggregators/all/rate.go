@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.rate
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/rate" // register plugin
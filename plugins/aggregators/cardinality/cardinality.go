@@ -0,0 +1,128 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cardinality
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"slices"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Cardinality struct {
+	Tags      []string        `toml:"tags"`
+	Fields    []string        `toml:"fields"`
+	Precision uint8           `toml:"precision"`
+	Suffix    string          `toml:"suffix"`
+	Log       telegraf.Logger `toml:"-"`
+
+	seed  maphash.Seed
+	cache map[uint64]*aggregate
+}
+
+// aggregate holds the estimators of a series, i.e. the metrics with the same
+// name and tags excluding the counted tags.
+type aggregate struct {
+	name       string
+	tags       map[string]string
+	estimators map[string]*hyperLogLog
+}
+
+func (*Cardinality) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Cardinality) Init() error {
+	if len(c.Tags) == 0 && len(c.Fields) == 0 {
+		return errors.New("no tags or fields to count given")
+	}
+	if c.Precision < 4 || c.Precision > 16 {
+		return fmt.Errorf("invalid precision %d, must be between 4 and 16", c.Precision)
+	}
+
+	c.seed = maphash.MakeSeed()
+	c.cache = make(map[uint64]*aggregate)
+
+	return nil
+}
+
+func (c *Cardinality) Add(in telegraf.Metric) {
+	// Identify the series by the metric name and all tags not being counted
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+	h.WriteString(in.Name())
+	tags := make(map[string]string, len(in.TagList()))
+	for _, tag := range in.TagList() {
+		if slices.Contains(c.Tags, tag.Key) {
+			continue
+		}
+		h.WriteByte(0)
+		h.WriteString(tag.Key)
+		h.WriteByte(0)
+		h.WriteString(tag.Value)
+		tags[tag.Key] = tag.Value
+	}
+	id := h.Sum64()
+
+	a, found := c.cache[id]
+	if !found {
+		a = &aggregate{
+			name:       in.Name(),
+			tags:       tags,
+			estimators: make(map[string]*hyperLogLog),
+		}
+		c.cache[id] = a
+	}
+
+	for _, key := range c.Tags {
+		if value, found := in.GetTag(key); found {
+			c.add(a, key, value)
+		}
+	}
+	for _, key := range c.Fields {
+		if value, found := in.GetField(key); found {
+			c.add(a, key, fmt.Sprint(value))
+		}
+	}
+}
+
+func (c *Cardinality) add(a *aggregate, key, value string) {
+	estimator, found := a.estimators[key]
+	if !found {
+		estimator = newHyperLogLog(c.Precision)
+		a.estimators[key] = estimator
+	}
+	estimator.add(maphash.String(c.seed, value))
+}
+
+func (c *Cardinality) Push(acc telegraf.Accumulator) {
+	for _, a := range c.cache {
+		if len(a.estimators) == 0 {
+			continue
+		}
+		fields := make(map[string]interface{}, len(a.estimators))
+		for key, estimator := range a.estimators {
+			fields[key+c.Suffix] = estimator.estimate()
+		}
+		acc.AddFields(a.name, fields, a.tags)
+	}
+}
+
+func (c *Cardinality) Reset() {
+	c.cache = make(map[uint64]*aggregate)
+}
+
+func init() {
+	aggregators.Add("cardinality", func() telegraf.Aggregator {
+		return &Cardinality{
+			Precision: 14,
+			Suffix:    "_distinct",
+		}
+	})
+}
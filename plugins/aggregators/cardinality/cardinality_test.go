@@ -0,0 +1,96 @@
+package cardinality
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Cardinality{Precision: 14}
+	require.ErrorContains(t, plugin.Init(), "no tags or fields to count given")
+
+	plugin = &Cardinality{Tags: []string{"client"}, Precision: 20}
+	require.ErrorContains(t, plugin.Init(), "invalid precision 20")
+}
+
+func TestCardinality(t *testing.T) {
+	plugin := &Cardinality{
+		Tags:      []string{"client"},
+		Fields:    []string{"user"},
+		Precision: 14,
+		Suffix:    "_distinct",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Counted tags must not be used for identifying the series
+	for i := range 100 {
+		for _, server := range []string{"a", "b"} {
+			plugin.Add(metric.New(
+				"requests",
+				map[string]string{"server": server, "client": "10.0.0." + strconv.Itoa(i)},
+				map[string]interface{}{"user": int64(i % 10)},
+				time.Unix(0, 0),
+			))
+		}
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Len(t, acc.Metrics, 2)
+
+	// Small cardinalities are estimated almost exactly but hash collisions
+	// might still occur
+	for _, m := range acc.Metrics {
+		require.Equal(t, "requests", m.Measurement)
+		require.Len(t, m.Tags, 1)
+		require.Contains(t, []string{"a", "b"}, m.Tags["server"])
+		require.InDelta(t, 100, m.Fields["client_distinct"], 2)
+		require.InDelta(t, 10, m.Fields["user_distinct"], 1)
+	}
+	require.NotEqual(t, acc.Metrics[0].Tags, acc.Metrics[1].Tags)
+
+	// The estimation starts over in the next period
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestCardinalityEstimate(t *testing.T) {
+	plugin := &Cardinality{
+		Tags:      []string{"ip"},
+		Precision: 12,
+		Suffix:    "_distinct",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Add each value multiple times
+	const distinct = 100000
+	for range 3 {
+		for i := range distinct {
+			plugin.Add(metric.New(
+				"flow",
+				map[string]string{"ip": strconv.Itoa(i)},
+				map[string]interface{}{"bytes": int64(1)},
+				time.Unix(0, 0),
+			))
+		}
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+
+	// The error must be within three standard errors, i.e. about 5%
+	actual, found := acc.Metrics[0].Fields["ip_distinct"]
+	require.True(t, found)
+	require.InEpsilon(t, distinct, actual, 0.05)
+}
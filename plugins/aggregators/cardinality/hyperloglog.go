@@ -0,0 +1,67 @@
+package cardinality
+
+import (
+	"math"
+	"math/bits"
+)
+
+// hyperLogLog estimates the number of distinct hashed values using the
+// HyperLogLog algorithm with 2^precision registers. The standard error of the
+// estimate is about 1.04/sqrt(2^precision).
+type hyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+func newHyperLogLog(precision uint8) *hyperLogLog {
+	return &hyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// add records the given 64-bit hash of a value
+func (h *hyperLogLog) add(hash uint64) {
+	// The first bits select the register and the position of the first set
+	// bit in the remaining bits determines the value. Setting the lowest bit
+	// limits the value for hashes with all remaining bits being zero.
+	idx := hash >> (64 - h.precision)
+	rank := uint8(bits.LeadingZeros64(hash<<h.precision|1<<(h.precision-1)) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// estimate returns the estimated number of distinct values
+func (h *hyperLogLog) estimate() uint64 {
+	m := float64(len(h.registers))
+
+	var sum float64
+	var zeros int
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	estimate := alpha * m * m / sum
+
+	// Use linear counting for small cardinalities. With 64-bit hashes no
+	// correction for large cardinalities is required.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
@@ -0,0 +1,121 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package exponential_histogram
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/common/exphistogram"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ExponentialHistogram struct {
+	Fields          []string        `toml:"fields"`
+	MaxBuckets      int             `toml:"max_buckets"`
+	MaxScale        int32           `toml:"max_scale"`
+	ZeroThreshold   float64         `toml:"zero_threshold"`
+	ResetHistograms bool            `toml:"reset"`
+	Log             telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]*series
+}
+
+// series holds the histograms of the fields of a single metric series
+type series struct {
+	name       string
+	tags       map[string]string
+	histograms map[string]*exphistogram.Histogram
+}
+
+func (*ExponentialHistogram) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *ExponentialHistogram) Init() error {
+	if e.MaxBuckets < 1 {
+		return errors.New("max_buckets must be positive")
+	}
+	if e.MaxScale < exphistogram.MinSchema || e.MaxScale > exphistogram.MaxSchema {
+		return fmt.Errorf("invalid max_scale %d, must be between %d and %d", e.MaxScale, exphistogram.MinSchema, exphistogram.MaxSchema)
+	}
+	if e.ZeroThreshold < 0 {
+		return errors.New("zero_threshold must not be negative")
+	}
+
+	f, err := filter.Compile(e.Fields)
+	if err != nil {
+		return err
+	}
+	e.fieldFilter = f
+	e.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (e *ExponentialHistogram) Add(in telegraf.Metric) {
+	id := in.HashID()
+	s, found := e.cache[id]
+	if !found {
+		s = &series{
+			name:       in.Name(),
+			tags:       in.Tags(),
+			histograms: make(map[string]*exphistogram.Histogram),
+		}
+		e.cache[id] = s
+	}
+
+	for _, field := range in.FieldList() {
+		if e.fieldFilter != nil && !e.fieldFilter.Match(field.Key) {
+			continue
+		}
+
+		var value float64
+		switch v := field.Value.(type) {
+		case int64:
+			value = float64(v)
+		case uint64:
+			value = float64(v)
+		case float64:
+			value = v
+		default:
+			continue
+		}
+
+		h, found := s.histograms[field.Key]
+		if !found {
+			h = exphistogram.New(e.MaxScale, e.ZeroThreshold)
+			s.histograms[field.Key] = h
+		}
+		h.Add(value, e.MaxBuckets)
+	}
+}
+
+func (e *ExponentialHistogram) Push(acc telegraf.Accumulator) {
+	for _, s := range e.cache {
+		for key, h := range s.histograms {
+			acc.AddHistogram(s.name+"_"+key, h.Fields(), s.tags)
+		}
+	}
+}
+
+func (e *ExponentialHistogram) Reset() {
+	if e.ResetHistograms {
+		e.cache = make(map[uint64]*series)
+	}
+}
+
+func init() {
+	aggregators.Add("exponential_histogram", func() telegraf.Aggregator {
+		return &ExponentialHistogram{
+			MaxBuckets: 160,
+			MaxScale:   8,
+		}
+	})
+}
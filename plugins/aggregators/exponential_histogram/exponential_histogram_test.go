@@ -0,0 +1,136 @@
+package exponential_histogram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newExponentialHistogram() *ExponentialHistogram {
+	return &ExponentialHistogram{
+		MaxBuckets: 160,
+		MaxScale:   8,
+		Log:        testutil.Logger{},
+	}
+}
+
+func newMetric(fields map[string]interface{}) telegraf.Metric {
+	return metric.New("http", map[string]string{"path": "/"}, fields, time.Unix(0, 0))
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *ExponentialHistogram
+		expected string
+	}{
+		{
+			name:     "no buckets",
+			plugin:   &ExponentialHistogram{MaxScale: 8},
+			expected: "max_buckets must be positive",
+		},
+		{
+			name:     "scale too large",
+			plugin:   &ExponentialHistogram{MaxBuckets: 160, MaxScale: 21},
+			expected: "invalid max_scale 21",
+		},
+		{
+			name:     "negative zero threshold",
+			plugin:   &ExponentialHistogram{MaxBuckets: 160, ZeroThreshold: -1},
+			expected: "zero_threshold must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	plugin := newExponentialHistogram()
+	plugin.MaxScale = 0
+	plugin.Fields = []string{"latency"}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(newMetric(map[string]interface{}{"latency": 1.5, "size": int64(10)}))
+	plugin.Add(newMetric(map[string]interface{}{"latency": int64(3), "method": "GET"}))
+	plugin.Add(newMetric(map[string]interface{}{"latency": uint64(0)}))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"http_latency",
+			map[string]string{"path": "/"},
+			map[string]interface{}{
+				"count":                  float64(3),
+				"sum":                    float64(4.5),
+				"schema":                 int64(0),
+				"counter_reset_hint":     uint64(0),
+				"zero_threshold":         float64(0),
+				"zero_count":             float64(1),
+				"positive_span_0_offset": int64(1),
+				"positive_span_0_length": uint64(2),
+				"positive_bucket_0":      float64(1),
+				"positive_bucket_1":      float64(1),
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestDownscale(t *testing.T) {
+	plugin := newExponentialHistogram()
+	plugin.MaxBuckets = 4
+	require.NoError(t, plugin.Init())
+
+	for _, v := range []float64{0.01, 1, 100, 10000} {
+		plugin.Add(newMetric(map[string]interface{}{"latency": v}))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	fields := metrics[0].Fields()
+	require.Less(t, fields["schema"], int64(8))
+	require.LessOrEqual(t, fields["positive_span_0_length"], uint64(4))
+	require.InDelta(t, float64(4), fields["count"], 0)
+}
+
+func TestReset(t *testing.T) {
+	for _, reset := range []bool{false, true} {
+		plugin := newExponentialHistogram()
+		plugin.ResetHistograms = reset
+		require.NoError(t, plugin.Init())
+
+		var acc testutil.Accumulator
+		plugin.Add(newMetric(map[string]interface{}{"latency": 1.0}))
+		plugin.Push(&acc)
+		plugin.Reset()
+		plugin.Add(newMetric(map[string]interface{}{"latency": 2.0}))
+		plugin.Push(&acc)
+		plugin.Reset()
+
+		metrics := acc.GetTelegrafMetrics()
+		require.Len(t, metrics, 2)
+		count, found := metrics[1].GetField("count")
+		require.True(t, found)
+		if reset {
+			require.InDelta(t, float64(1), count, 0)
+		} else {
+			require.InDelta(t, float64(2), count, 0)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rate
+
+import (
+	_ "embed"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Rate struct {
+	Fields        []string        `toml:"fields"`
+	Suffix        string          `toml:"suffix"`
+	Unit          config.Duration `toml:"unit"`
+	CounterMax    uint64          `toml:"counter_max"`
+	SeriesTimeout config.Duration `toml:"series_timeout"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]*series
+}
+
+// series holds the counters of a single metric series
+type series struct {
+	name     string
+	tags     map[string]string
+	counters map[string]*counter
+	lastSeen time.Time
+}
+
+// counter tracks the increase of a single counter field since the baseline,
+// i.e. the last sample of the previous period.
+type counter struct {
+	value    float64
+	unsigned uint64
+	isUint   bool
+	time     time.Time
+
+	baseline time.Time
+	increase float64
+	updated  bool
+}
+
+func (*Rate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rate) Init() error {
+	if r.Unit <= 0 {
+		return errors.New("unit must be positive")
+	}
+
+	f, err := filter.Compile(r.Fields)
+	if err != nil {
+		return err
+	}
+	r.fieldFilter = f
+	r.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (r *Rate) Add(in telegraf.Metric) {
+	id := in.HashID()
+	s, found := r.cache[id]
+	if !found {
+		s = &series{
+			name:     in.Name(),
+			tags:     in.Tags(),
+			counters: make(map[string]*counter),
+		}
+		r.cache[id] = s
+	}
+	s.lastSeen = time.Now()
+
+	for _, field := range in.FieldList() {
+		if r.fieldFilter != nil && !r.fieldFilter.Match(field.Key) {
+			continue
+		}
+
+		var sample counter
+		switch v := field.Value.(type) {
+		case int64:
+			sample.value = float64(v)
+		case uint64:
+			sample.value = float64(v)
+			sample.unsigned = v
+			sample.isUint = true
+		case float64:
+			sample.value = v
+		default:
+			continue
+		}
+		sample.time = in.Time()
+
+		c, found := s.counters[field.Key]
+		if !found {
+			sample.baseline = sample.time
+			s.counters[field.Key] = &sample
+			continue
+		}
+		r.update(s.name, field.Key, c, &sample)
+	}
+}
+
+// update adds the increase from the last to the given sample to the counter
+func (r *Rate) update(name, field string, c, sample *counter) {
+	// Ignore samples out of order
+	if !sample.time.After(c.time) {
+		return
+	}
+
+	unsigned := sample.isUint && c.isUint
+	var increase float64
+	switch {
+	case unsigned && sample.unsigned >= c.unsigned:
+		increase = float64(sample.unsigned - c.unsigned)
+	case !unsigned && sample.value >= c.value:
+		increase = sample.value - c.value
+	case unsigned && r.rollover(c.unsigned, sample.unsigned):
+		increase = float64(r.counterMax() - c.unsigned + sample.unsigned + 1)
+	default:
+		// The counter was reset, so it increased from zero to the current
+		// value while keeping the increase accumulated in this period
+		r.Log.Debugf("Counter %q of %q was reset", field, name)
+		increase = max(sample.value, 0)
+	}
+
+	c.value, c.unsigned, c.isUint, c.time = sample.value, sample.unsigned, sample.isUint, sample.time
+	c.increase += increase
+	c.updated = true
+}
+
+// rollover returns true if the decrease from the previous to the current
+// value of an unsigned counter is caused by wrapping around at the maximum
+// value instead of a counter reset. This is assumed if the counter wrapped by
+// less than half of the counter range.
+func (r *Rate) rollover(previous, current uint64) bool {
+	limit := r.counterMax()
+	if previous > limit || current > limit {
+		return false
+	}
+	return limit-previous+current < limit/2
+}
+
+func (r *Rate) counterMax() uint64 {
+	if r.CounterMax == 0 {
+		return math.MaxUint64
+	}
+	return r.CounterMax
+}
+
+func (r *Rate) Push(acc telegraf.Accumulator) {
+	for _, s := range r.cache {
+		fields := make(map[string]interface{}, len(s.counters))
+		for key, c := range s.counters {
+			if !c.updated {
+				continue
+			}
+			elapsed := c.time.Sub(c.baseline)
+			if elapsed <= 0 {
+				continue
+			}
+			fields[key+r.Suffix] = c.increase / elapsed.Seconds() * time.Duration(r.Unit).Seconds()
+		}
+		if len(fields) > 0 {
+			acc.AddFields(s.name, fields, s.tags)
+		}
+	}
+}
+
+func (r *Rate) Reset() {
+	for id, s := range r.cache {
+		// Evict series not receiving any metrics for too long
+		if r.SeriesTimeout > 0 && time.Since(s.lastSeen) > time.Duration(r.SeriesTimeout) {
+			r.Log.Debugf("Removing stale series of %q", s.name)
+			delete(r.cache, id)
+			continue
+		}
+
+		// Continue the rate computation from the last sample
+		for _, c := range s.counters {
+			if c.updated {
+				c.baseline = c.time
+				c.increase = 0
+				c.updated = false
+			}
+		}
+	}
+}
+
+func init() {
+	aggregators.Add("rate", func() telegraf.Aggregator {
+		return &Rate{
+			Suffix:        "_rate",
+			Unit:          config.Duration(time.Second),
+			SeriesTimeout: config.Duration(5 * time.Minute),
+		}
+	})
+}
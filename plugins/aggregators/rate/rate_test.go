@@ -0,0 +1,194 @@
+package rate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newRate() *Rate {
+	return &Rate{
+		Suffix:        "_rate",
+		Unit:          config.Duration(time.Second),
+		SeriesTimeout: config.Duration(5 * time.Minute),
+		Log:           testutil.Logger{},
+	}
+}
+
+func newMetric(fields map[string]interface{}, seconds int64) telegraf.Metric {
+	return metric.New("net", map[string]string{"interface": "eth0"}, fields, time.Unix(seconds, 0))
+}
+
+func TestRate(t *testing.T) {
+	plugin := newRate()
+	plugin.Unit = config.Duration(time.Minute)
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(newMetric(map[string]interface{}{"bytes_recv": int64(0), "name": "eth0"}, 0))
+	plugin.Add(newMetric(map[string]interface{}{"bytes_recv": int64(100)}, 10))
+	plugin.Add(newMetric(map[string]interface{}{"bytes_recv": int64(300)}, 20))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv_rate": float64(900)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRateContinuesAcrossPeriods(t *testing.T) {
+	plugin := newRate()
+	require.NoError(t, plugin.Init())
+
+	// The first sample only initializes the series
+	var acc testutil.Accumulator
+	plugin.Add(newMetric(map[string]interface{}{"packets": uint64(100)}, 0))
+	plugin.Push(&acc)
+	plugin.Reset()
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Subsequent periods compute the rate from the last sample of the
+	// previous period
+	plugin.Add(newMetric(map[string]interface{}{"packets": uint64(200)}, 10))
+	plugin.Push(&acc)
+	plugin.Reset()
+	plugin.Add(newMetric(map[string]interface{}{"packets": uint64(400)}, 20))
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	// Periods without samples do not produce any rate
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	expected := []telegraf.Metric{
+		metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"packets_rate": float64(10)}, time.Unix(0, 0)),
+		metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"packets_rate": float64(20)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRateCounterReset(t *testing.T) {
+	plugin := newRate()
+	require.NoError(t, plugin.Init())
+
+	// The value after the reset counts as the increase from zero and the
+	// increase before the reset is kept
+	plugin.Add(newMetric(map[string]interface{}{"ops": int64(1000)}, 0))
+	plugin.Add(newMetric(map[string]interface{}{"ops": int64(2000)}, 10))
+	plugin.Add(newMetric(map[string]interface{}{"ops": int64(200)}, 20))
+	plugin.Add(newMetric(map[string]interface{}{"ops": int64(800)}, 30))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	// A reset as the only sample of a period still produces a rate
+	plugin.Add(newMetric(map[string]interface{}{"ops": int64(400)}, 40))
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"ops_rate": float64(60)}, time.Unix(0, 0)),
+		metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"ops_rate": float64(40)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRateRollover(t *testing.T) {
+	tests := []struct {
+		name       string
+		counterMax uint64
+		first      uint64
+		second     uint64
+		expected   []telegraf.Metric
+	}{
+		{
+			name:     "64-bit rollover",
+			first:    math.MaxUint64 - 9,
+			second:   10,
+			expected: []telegraf.Metric{metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes_rate": float64(2)}, time.Unix(0, 0))},
+		},
+		{
+			name:       "32-bit rollover",
+			counterMax: math.MaxUint32,
+			first:      math.MaxUint32 - 4,
+			second:     15,
+			expected:   []telegraf.Metric{metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes_rate": float64(2)}, time.Unix(0, 0))},
+		},
+		{
+			name:       "reset",
+			counterMax: math.MaxUint32,
+			first:      1000,
+			second:     15,
+			expected:   []telegraf.Metric{metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes_rate": float64(1.5)}, time.Unix(0, 0))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newRate()
+			plugin.CounterMax = tt.counterMax
+			require.NoError(t, plugin.Init())
+
+			plugin.Add(newMetric(map[string]interface{}{"bytes": tt.first}, 0))
+			plugin.Add(newMetric(map[string]interface{}{"bytes": tt.second}, 10))
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestRateFields(t *testing.T) {
+	plugin := newRate()
+	plugin.Fields = []string{"bytes_*"}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(newMetric(map[string]interface{}{"bytes_sent": int64(0), "errors": int64(0)}, 0))
+	plugin.Add(newMetric(map[string]interface{}{"bytes_sent": int64(10), "errors": int64(10)}, 10))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes_sent_rate": float64(1)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRateSeriesTimeout(t *testing.T) {
+	plugin := newRate()
+	plugin.SeriesTimeout = config.Duration(time.Nanosecond)
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(newMetric(map[string]interface{}{"bytes": int64(0)}, 0))
+	time.Sleep(time.Millisecond)
+	plugin.Reset()
+	require.Empty(t, plugin.cache)
+
+	// The series starts over after being evicted
+	var acc testutil.Accumulator
+	plugin.Add(newMetric(map[string]interface{}{"bytes": int64(10)}, 10))
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestRateInvalidUnit(t *testing.T) {
+	plugin := newRate()
+	plugin.Unit = 0
+	require.ErrorContains(t, plugin.Init(), "unit must be positive")
+}
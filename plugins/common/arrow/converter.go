@@ -0,0 +1,199 @@
+// Package arrow converts metrics to Apache Arrow records for columnar data
+// formats.
+package arrow
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/schema"
+)
+
+// ColumnTypeKey is the metadata key of Arrow fields holding the metric
+// property stored in the column, i.e. "measurement", "timestamp", "tag" or
+// "field"
+const ColumnTypeKey = "telegraf.column_type"
+
+// Converter creates Arrow schemas and records from metrics. Each tag and field
+// is stored in a column of the same name, where fields take precedence over
+// tags of the same name. Values of missing tags and fields are null.
+type Converter struct {
+	// MeasurementColumn is the name of the column holding the metric name.
+	// The column is omitted if empty.
+	MeasurementColumn string
+	// TimestampColumn is the name of the column holding the metric time. The
+	// column is omitted if empty.
+	TimestampColumn string
+	// TimestampType is the type of the timestamp column, either an Int64 or a
+	// Timestamp type. Defaults to Int64 holding nanoseconds since epoch.
+	TimestampType arrow.DataType
+	// SkipInvalid writes null for values that cannot be converted to the
+	// column type instead of failing
+	SkipInvalid bool
+}
+
+// Schema infers the schema from the given metrics. Conflicting field types
+// are resolved following the promotion rules of the schema package.
+func (c *Converter) Schema(metrics []telegraf.Metric) *arrow.Schema {
+	return c.ArrowSchema(schema.Infer("", metrics))
+}
+
+// ArrowSchema converts the given schema. The measurement column comes first,
+// followed by the tag and field columns sorted by name and the timestamp
+// column.
+func (c *Converter) ArrowSchema(s *schema.Schema) *arrow.Schema {
+	columns := slices.Clone(s.Columns)
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+
+	fields := make([]arrow.Field, 0, len(columns)+2)
+	if c.MeasurementColumn != "" {
+		fields = append(fields, column(c.MeasurementColumn, arrow.BinaryTypes.String, "measurement"))
+	}
+	for _, col := range columns {
+		if col.Name == c.MeasurementColumn || col.Name == c.TimestampColumn {
+			continue
+		}
+		columnType := "field"
+		if col.Tag {
+			columnType = "tag"
+		}
+		fields = append(fields, column(col.Name, DataType(col.Type), columnType))
+	}
+	if c.TimestampColumn != "" {
+		fields = append(fields, column(c.TimestampColumn, c.timestampType(), "timestamp"))
+	}
+
+	return arrow.NewSchema(fields, nil)
+}
+
+// Record creates a record from the given metrics using the builder's schema.
+// Values are converted to the column type if possible.
+func (c *Converter) Record(builder *array.RecordBuilder, metrics []telegraf.Metric) (arrow.Record, error) {
+	for index, col := range builder.Schema().Fields() {
+		b := builder.Field(index)
+		for _, m := range metrics {
+			switch col.Name {
+			case c.MeasurementColumn:
+				b.(*array.StringBuilder).Append(m.Name())
+				continue
+			case c.TimestampColumn:
+				if err := appendTimestamp(b, m); err != nil {
+					return nil, fmt.Errorf("column %q: %w", col.Name, err)
+				}
+				continue
+			}
+
+			// Try to get the value from a field first, then from a tag.
+			value, ok := m.GetField(col.Name)
+			if !ok {
+				value, ok = m.GetTag(col.Name)
+			}
+
+			// If neither field nor tag exists, append a null value
+			if !ok {
+				b.AppendNull()
+				continue
+			}
+
+			if err := appendValue(b, value); err != nil {
+				if c.SkipInvalid {
+					b.AppendNull()
+					continue
+				}
+				return nil, fmt.Errorf("column %q: %w", col.Name, err)
+			}
+		}
+	}
+
+	return builder.NewRecord(), nil
+}
+
+func (c *Converter) timestampType() arrow.DataType {
+	if c.TimestampType == nil {
+		return arrow.PrimitiveTypes.Int64
+	}
+	return c.TimestampType
+}
+
+func column(name string, dataType arrow.DataType, columnType string) arrow.Field {
+	return arrow.Field{
+		Name:     name,
+		Type:     dataType,
+		Nullable: true,
+		Metadata: arrow.NewMetadata([]string{ColumnTypeKey}, []string{columnType}),
+	}
+}
+
+func appendTimestamp(b array.Builder, m telegraf.Metric) error {
+	switch tb := b.(type) {
+	case *array.Int64Builder:
+		tb.Append(m.Time().UnixNano())
+	case *array.TimestampBuilder:
+		unit := tb.Type().(*arrow.TimestampType).Unit
+		ts, err := arrow.TimestampFromTime(m.Time(), unit)
+		if err != nil {
+			return err
+		}
+		tb.Append(ts)
+	default:
+		return fmt.Errorf("unsupported timestamp type %s", b.Type())
+	}
+	return nil
+}
+
+func appendValue(b array.Builder, value interface{}) error {
+	var t schema.Type
+	switch b.(type) {
+	case *array.BooleanBuilder:
+		t = schema.Boolean
+	case *array.Int64Builder:
+		t = schema.Integer
+	case *array.Uint64Builder:
+		t = schema.Unsigned
+	case *array.Float64Builder:
+		t = schema.Float
+	case *array.StringBuilder:
+		t = schema.String
+	default:
+		return fmt.Errorf("unsupported type %s", b.Type())
+	}
+
+	v, ok := schema.Convert(value, t)
+	if !ok {
+		return fmt.Errorf("value %v of type %T does not match column type %s", value, value, b.Type())
+	}
+
+	switch vb := b.(type) {
+	case *array.BooleanBuilder:
+		vb.Append(v.(bool))
+	case *array.Int64Builder:
+		vb.Append(v.(int64))
+	case *array.Uint64Builder:
+		vb.Append(v.(uint64))
+	case *array.Float64Builder:
+		vb.Append(v.(float64))
+	case *array.StringBuilder:
+		vb.Append(v.(string))
+	}
+	return nil
+}
+
+// DataType returns the Arrow type for the given column type
+func DataType(t schema.Type) arrow.DataType {
+	switch t {
+	case schema.Boolean:
+		return arrow.FixedWidthTypes.Boolean
+	case schema.Integer:
+		return arrow.PrimitiveTypes.Int64
+	case schema.Unsigned:
+		return arrow.PrimitiveTypes.Uint64
+	case schema.Float:
+		return arrow.PrimitiveTypes.Float64
+	}
+	return arrow.BinaryTypes.String
+}
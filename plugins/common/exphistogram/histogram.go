@@ -0,0 +1,274 @@
+// Package exphistogram implements base-2 exponential histograms as used by
+// OpenTelemetry (ExponentialHistogram) and Prometheus (native histograms).
+//
+// Histograms are represented in metrics of type telegraf.Histogram with the
+// same fields as used by the Prometheus remote-write parser and serializer:
+// "count", "sum", "schema", "counter_reset_hint", "zero_threshold",
+// "zero_count", the "positive_span_<n>_offset" and "positive_span_<n>_length"
+// fields describing the populated buckets and the "positive_bucket_<n>" fields
+// holding the absolute bucket counts. The same fields exist for negative
+// values with the "negative" prefix.
+package exphistogram
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// MinSchema is the lowest schema supported by Prometheus native histograms
+	MinSchema = -4
+	// MaxSchema is the highest scale supported by OpenTelemetry
+	MaxSchema = 20
+)
+
+// Histogram is an exponential histogram with the base of 2^(2^-Schema). The
+// bucket indices follow the Prometheus convention, i.e. the bucket with index
+// i covers values in the range (base^(i-1), base^i]. The OpenTelemetry index
+// of the same bucket is i-1.
+type Histogram struct {
+	Schema           int32
+	ZeroThreshold    float64
+	ZeroCount        float64
+	Count            float64
+	Sum              float64
+	CounterResetHint uint64
+	Positive         Buckets
+	Negative         Buckets
+}
+
+// Buckets holds the counts of consecutive buckets starting at the index given
+// by the offset.
+type Buckets struct {
+	Offset int32
+	Counts []float64
+}
+
+// New creates an empty histogram with the given schema and zero threshold
+func New(schema int32, zeroThreshold float64) *Histogram {
+	return &Histogram{
+		Schema:        schema,
+		ZeroThreshold: zeroThreshold,
+	}
+}
+
+// Add inserts the value into the histogram. If the value would cause the
+// positive or negative buckets to exceed the given maximum number of buckets,
+// the histogram is downscaled until the value fits or the minimum schema is
+// reached. Non-finite values are ignored.
+func (h *Histogram) Add(v float64, maxBuckets int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	h.Count++
+	h.Sum += v
+
+	if math.Abs(v) <= h.ZeroThreshold {
+		h.ZeroCount++
+		return
+	}
+
+	b := &h.Positive
+	if v < 0 {
+		b = &h.Negative
+		v = -v
+	}
+	idx := index(v, h.Schema)
+	for h.Schema > MinSchema && b.span(idx) > maxBuckets {
+		h.downscale()
+		idx = index(v, h.Schema)
+	}
+	b.add(idx, 1)
+}
+
+// Fields returns the metric fields representing the histogram
+func (h *Histogram) Fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"count":              h.Count,
+		"sum":                h.Sum,
+		"schema":             int64(h.Schema),
+		"counter_reset_hint": h.CounterResetHint,
+		"zero_threshold":     h.ZeroThreshold,
+		"zero_count":         h.ZeroCount,
+	}
+	h.Positive.addFields(fields, "positive")
+	h.Negative.addFields(fields, "negative")
+	return fields
+}
+
+// FromFields parses the histogram from the given metric fields. The function
+// returns false if the fields do not describe a valid exponential histogram.
+func FromFields(fields map[string]interface{}) (*Histogram, bool) {
+	var h Histogram
+	var ok bool
+	if h.Count, ok = fields["count"].(float64); !ok {
+		return nil, false
+	}
+	if h.Sum, ok = fields["sum"].(float64); !ok {
+		return nil, false
+	}
+	schema, ok := fields["schema"].(int64)
+	if !ok || schema < MinSchema || schema > MaxSchema {
+		return nil, false
+	}
+	h.Schema = int32(schema)
+	if h.ZeroThreshold, ok = fields["zero_threshold"].(float64); !ok {
+		return nil, false
+	}
+	if h.ZeroCount, ok = fields["zero_count"].(float64); !ok {
+		return nil, false
+	}
+	// The hint is optional as it only carries Prometheus specific information
+	h.CounterResetHint, _ = fields["counter_reset_hint"].(uint64)
+
+	if h.Positive, ok = parseBuckets(fields, "positive"); !ok {
+		return nil, false
+	}
+	if h.Negative, ok = parseBuckets(fields, "negative"); !ok {
+		return nil, false
+	}
+	return &h, true
+}
+
+// downscale halves the resolution of the histogram by merging neighboring
+// buckets
+func (h *Histogram) downscale() {
+	h.Schema--
+	h.Positive.downscale()
+	h.Negative.downscale()
+}
+
+// index returns the index of the bucket containing the given positive value
+func index(v float64, schema int32) int32 {
+	frac, exp := math.Frexp(v)
+	// Powers of two are the upper bound of the bucket below the exponent
+	if frac == 0.5 {
+		exp--
+		if schema > 0 {
+			return int32(exp) << schema
+		}
+		return ((int32(exp) - 1) >> -schema) + 1
+	}
+	if schema > 0 {
+		return int32(math.Ceil(math.Log2(v) * float64(int32(1)<<schema)))
+	}
+	return ((int32(exp) - 1) >> -schema) + 1
+}
+
+// span returns the number of buckets required to also cover the given index
+func (b *Buckets) span(idx int32) int {
+	if len(b.Counts) == 0 {
+		return 1
+	}
+	first, last := b.Offset, b.Offset+int32(len(b.Counts))-1
+	return int(max(last, idx)-min(first, idx)) + 1
+}
+
+func (b *Buckets) add(idx int32, count float64) {
+	switch {
+	case len(b.Counts) == 0:
+		b.Offset = idx
+		b.Counts = []float64{0}
+	case idx < b.Offset:
+		counts := make([]float64, int(b.Offset-idx)+len(b.Counts))
+		copy(counts[b.Offset-idx:], b.Counts)
+		b.Offset, b.Counts = idx, counts
+	case idx >= b.Offset+int32(len(b.Counts)):
+		b.Counts = append(b.Counts, make([]float64, int(idx-b.Offset)-len(b.Counts)+1)...)
+	}
+	b.Counts[idx-b.Offset] += count
+}
+
+func (b *Buckets) downscale() {
+	if len(b.Counts) == 0 {
+		return
+	}
+	// The bucket i at the current schema is part of bucket ceil(i/2) at the
+	// schema below.
+	first := (b.Offset + 1) >> 1
+	last := (b.Offset + int32(len(b.Counts))) >> 1
+	counts := make([]float64, last-first+1)
+	for i, c := range b.Counts {
+		counts[((b.Offset+int32(i)+1)>>1)-first] += c
+	}
+	b.Offset, b.Counts = first, counts
+}
+
+// addFields adds the spans and buckets to the fields. Like the Prometheus
+// client, empty buckets are only included in a span if there are at most two
+// consecutive ones, otherwise a new span is started.
+func (b *Buckets) addFields(fields map[string]interface{}, prefix string) {
+	var span, bucket int
+	end := 0
+	addSpan := func(first, last int) {
+		offset := first - end
+		if span == 0 {
+			offset += int(b.Offset)
+		}
+		fields[fmt.Sprintf("%s_span_%d_offset", prefix, span)] = int64(offset)
+		fields[fmt.Sprintf("%s_span_%d_length", prefix, span)] = uint64(last - first + 1)
+		for _, c := range b.Counts[first : last+1] {
+			fields[fmt.Sprintf("%s_bucket_%d", prefix, bucket)] = c
+			bucket++
+		}
+		span++
+		end = last + 1
+	}
+
+	first, last := -1, -1
+	for i, c := range b.Counts {
+		if c == 0 {
+			continue
+		}
+		if first >= 0 && i-last > 3 {
+			addSpan(first, last)
+			first = -1
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	if first >= 0 {
+		addSpan(first, last)
+	}
+}
+
+// parseBuckets reads the spans and bucket counts with the given prefix. Gaps
+// between the spans are filled with empty buckets.
+func parseBuckets(fields map[string]interface{}, prefix string) (Buckets, bool) {
+	var b Buckets
+	var indices []int32
+	var idx int32
+	for i := 0; ; i++ {
+		offset, offsetFound := fields[fmt.Sprintf("%s_span_%d_offset", prefix, i)].(int64)
+		length, lengthFound := fields[fmt.Sprintf("%s_span_%d_length", prefix, i)].(uint64)
+		if !offsetFound || !lengthFound {
+			break
+		}
+		// The offset of the first span is the absolute index of its first
+		// bucket, all other offsets are relative to the end of the previous span.
+		if i > 0 && offset < 0 {
+			return Buckets{}, false
+		}
+		idx += int32(offset)
+		for j := uint64(0); j < length; j++ {
+			indices = append(indices, idx)
+			idx++
+		}
+	}
+	if len(indices) == 0 {
+		return b, true
+	}
+
+	b.Offset = indices[0]
+	b.Counts = make([]float64, indices[len(indices)-1]-indices[0]+1)
+	for i, idx := range indices {
+		count, found := fields[fmt.Sprintf("%s_bucket_%d", prefix, i)].(float64)
+		if !found || count < 0 {
+			return Buckets{}, false
+		}
+		b.Counts[idx-b.Offset] = count
+	}
+	return b, true
+}
@@ -0,0 +1,148 @@
+package exphistogram
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	tests := []struct {
+		value    float64
+		schema   int32
+		expected int32
+	}{
+		{value: 1, schema: 0, expected: 0},
+		{value: 1.5, schema: 0, expected: 1},
+		{value: 2, schema: 0, expected: 1},
+		{value: 3, schema: 0, expected: 2},
+		{value: 0.3, schema: 0, expected: -1},
+		{value: 3, schema: 1, expected: 4},
+		{value: 4, schema: 3, expected: 16},
+		{value: 5, schema: -1, expected: 2},
+		{value: 16, schema: -1, expected: 2},
+		{value: 17, schema: -1, expected: 3},
+		{value: 0.25, schema: -1, expected: -1},
+	}
+
+	for _, tt := range tests {
+		actual := index(tt.value, tt.schema)
+		require.Equalf(t, tt.expected, actual, "index of %v with schema %d", tt.value, tt.schema)
+
+		// Check the bucket boundaries
+		base := math.Pow(2, math.Pow(2, -float64(tt.schema)))
+		require.Greater(t, tt.value, math.Pow(base, float64(actual-1))*(1+1e-12))
+		require.LessOrEqual(t, tt.value, math.Pow(base, float64(actual))*(1+1e-12))
+	}
+}
+
+func TestAdd(t *testing.T) {
+	h := New(0, 0.001)
+	for _, v := range []float64{1, 1.5, 2, 3, -3, 0, 0.0005, math.NaN(), math.Inf(1)} {
+		h.Add(v, 160)
+	}
+
+	expected := &Histogram{
+		Schema:        0,
+		ZeroThreshold: 0.001,
+		ZeroCount:     2,
+		Count:         7,
+		Sum:           4.5005,
+		Positive:      Buckets{Offset: 0, Counts: []float64{1, 2, 1}},
+		Negative:      Buckets{Offset: 2, Counts: []float64{1}},
+	}
+	require.Equal(t, expected, h)
+}
+
+func TestDownscale(t *testing.T) {
+	h := New(2, 0)
+	values := []float64{0.001, 0.1, 1, 10, 1000, 1e6}
+	for _, v := range values {
+		h.Add(v, 8)
+	}
+	require.Less(t, h.Schema, int32(2))
+	require.LessOrEqual(t, len(h.Positive.Counts), 8)
+
+	// All values must still be within the bucket boundaries
+	var total float64
+	for _, c := range h.Positive.Counts {
+		total += c
+	}
+	require.InDelta(t, float64(len(values)), total, 0)
+	for _, v := range values {
+		idx := index(v, h.Schema)
+		require.GreaterOrEqual(t, idx, h.Positive.Offset)
+		require.Less(t, idx, h.Positive.Offset+int32(len(h.Positive.Counts)))
+		require.Positive(t, h.Positive.Counts[idx-h.Positive.Offset])
+	}
+}
+
+func TestFieldsRoundtrip(t *testing.T) {
+	h := New(3, 0)
+	for _, v := range []float64{0.5, 0.7, 1.2, 8, 100, -2, -2.5} {
+		h.Add(v, 160)
+	}
+
+	actual, ok := FromFields(h.Fields())
+	require.True(t, ok)
+	require.Equal(t, h, actual)
+}
+
+func TestFieldsSpans(t *testing.T) {
+	h := New(0, 0)
+	for _, v := range []float64{1, 3, 5, 1000} {
+		h.Add(v, 160)
+	}
+
+	expected := map[string]interface{}{
+		"count":                  float64(4),
+		"sum":                    float64(1009),
+		"schema":                 int64(0),
+		"counter_reset_hint":     uint64(0),
+		"zero_threshold":         float64(0),
+		"zero_count":             float64(0),
+		"positive_span_0_offset": int64(0),
+		"positive_span_0_length": uint64(4),
+		"positive_span_1_offset": int64(6),
+		"positive_span_1_length": uint64(1),
+		"positive_bucket_0":      float64(1),
+		"positive_bucket_1":      float64(0),
+		"positive_bucket_2":      float64(1),
+		"positive_bucket_3":      float64(1),
+		"positive_bucket_4":      float64(1),
+	}
+	require.Equal(t, expected, h.Fields())
+}
+
+func TestFromFieldsSpans(t *testing.T) {
+	fields := map[string]interface{}{
+		"count":                  float64(6),
+		"sum":                    float64(20),
+		"schema":                 int64(0),
+		"counter_reset_hint":     uint64(0),
+		"zero_threshold":         float64(0),
+		"zero_count":             float64(0),
+		"positive_span_0_offset": int64(1),
+		"positive_span_0_length": uint64(2),
+		"positive_span_1_offset": int64(2),
+		"positive_span_1_length": uint64(1),
+		"positive_bucket_0":      float64(1),
+		"positive_bucket_1":      float64(2),
+		"positive_bucket_2":      float64(3),
+	}
+
+	h, ok := FromFields(fields)
+	require.True(t, ok)
+	require.Equal(t, Buckets{Offset: 1, Counts: []float64{1, 2, 0, 0, 3}}, h.Positive)
+	require.Empty(t, h.Negative.Counts)
+
+	// Missing buckets are invalid
+	delete(fields, "positive_bucket_2")
+	_, ok = FromFields(fields)
+	require.False(t, ok)
+
+	// Classic histograms are not exponential histograms
+	_, ok = FromFields(map[string]interface{}{"sum": float64(1), "count": float64(1)})
+	require.False(t, ok)
+}
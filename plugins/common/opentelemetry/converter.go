@@ -0,0 +1,73 @@
+// Package opentelemetry converts Telegraf metrics to OpenTelemetry metrics
+// for plugins sending OTLP data.
+package opentelemetry
+
+import (
+	"github.com/influxdata/influxdb-observability/common"
+	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/exphistogram"
+)
+
+// MetricsConverter converts metrics using the line-protocol converter of the
+// influxdb-observability project and additionally supports exponential
+// histograms and metric units
+type MetricsConverter struct {
+	// UnitTag is the tag holding the unit of the metric, if set the tag is
+	// removed from the attributes and used as unit of the metric instead
+	UnitTag string
+
+	log       telegraf.Logger
+	converter *influx2otel.LineProtocolToOtelMetrics
+}
+
+func NewMetricsConverter(log telegraf.Logger) (*MetricsConverter, error) {
+	converter, err := influx2otel.NewLineProtocolToOtelMetrics(&Logger{Logger: log})
+	if err != nil {
+		return nil, err
+	}
+	return &MetricsConverter{log: log, converter: converter}, nil
+}
+
+// Convert returns the OpenTelemetry representation of the given metrics.
+// Metrics that cannot be converted are skipped with a warning.
+func (c *MetricsConverter) Convert(metrics []telegraf.Metric) pmetric.Metrics {
+	batch := c.converter.NewBatch()
+	exponential := newExponentialHistograms()
+	for _, metric := range metrics {
+		var vType common.InfluxMetricValueType
+		switch metric.Type() {
+		case telegraf.Gauge:
+			vType = common.InfluxMetricValueTypeGauge
+		case telegraf.Untyped:
+			vType = common.InfluxMetricValueTypeUntyped
+		case telegraf.Counter:
+			vType = common.InfluxMetricValueTypeSum
+		case telegraf.Histogram:
+			if h, ok := exphistogram.FromFields(metric.Fields()); ok {
+				exponential.add(metric, h)
+				continue
+			}
+			vType = common.InfluxMetricValueTypeHistogram
+		case telegraf.Summary:
+			vType = common.InfluxMetricValueTypeSummary
+		default:
+			c.log.Warnf("Unrecognized metric type %v", metric.Type())
+			continue
+		}
+		err := batch.AddPoint(metric.Name(), metric.Tags(), metric.Fields(), metric.Time(), vType)
+		if err != nil {
+			c.log.Warnf("Failed to add point: %v", err)
+			continue
+		}
+	}
+
+	data := batch.GetMetrics()
+	exponential.appendTo(data)
+	if c.UnitTag != "" {
+		moveUnitTag(data, c.UnitTag)
+	}
+	return data
+}
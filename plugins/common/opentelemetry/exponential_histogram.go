@@ -0,0 +1,121 @@
+package opentelemetry
+
+import (
+	"math"
+	"strings"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/exphistogram"
+)
+
+// Tags carrying the instrumentation scope, see the OpenTelemetry semantic
+// conventions v1.16.0 as used by the line-protocol converter.
+const (
+	tagScopeName    = "otel.library.name"
+	tagScopeVersion = "otel.library.version"
+)
+
+// exponentialHistograms converts exponential histograms, not supported by the
+// line-protocol converter, into OpenTelemetry ExponentialHistogram metrics.
+// Tags are mapped to resource, scope and data-point attributes in the same way
+// as the converter does for other metrics.
+type exponentialHistograms struct {
+	data    pmetric.Metrics
+	scopes  map[string]pmetric.ScopeMetrics
+	metrics map[string]pmetric.Metric
+}
+
+func newExponentialHistograms() *exponentialHistograms {
+	return &exponentialHistograms{
+		data:    pmetric.NewMetrics(),
+		scopes:  make(map[string]pmetric.ScopeMetrics),
+		metrics: make(map[string]pmetric.Metric),
+	}
+}
+
+func (e *exponentialHistograms) add(m telegraf.Metric, h *exphistogram.Histogram) {
+	var key strings.Builder
+	var scopeName, scopeVersion string
+	resource := pcommon.NewMap()
+	attributes := pcommon.NewMap()
+	delta := false
+	for _, tag := range m.TagList() {
+		switch {
+		case tag.Key == tagScopeName:
+			scopeName = tag.Value
+		case tag.Key == tagScopeVersion:
+			scopeVersion = tag.Value
+		case tag.Key == "temporality":
+			delta = tag.Value == "delta"
+		case common.ResourceNamespace.MatchString(tag.Key):
+			resource.PutStr(tag.Key, tag.Value)
+			key.WriteString(tag.Key + "=" + tag.Value + "\x00")
+		default:
+			attributes.PutStr(tag.Key, tag.Value)
+		}
+	}
+	key.WriteString("\x01" + scopeName + ":" + scopeVersion)
+	scopeKey := key.String()
+
+	scope, found := e.scopes[scopeKey]
+	if !found {
+		rm := e.data.ResourceMetrics().AppendEmpty()
+		resource.CopyTo(rm.Resource().Attributes())
+		scope = rm.ScopeMetrics().AppendEmpty()
+		scope.Scope().SetName(scopeName)
+		scope.Scope().SetVersion(scopeVersion)
+		e.scopes[scopeKey] = scope
+	}
+
+	metricKey := scopeKey + "\x02" + m.Name()
+	metric, found := e.metrics[metricKey]
+	if !found {
+		metric = scope.Metrics().AppendEmpty()
+		metric.SetName(m.Name())
+		histogram := metric.SetEmptyExponentialHistogram()
+		if delta {
+			histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		} else {
+			histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		}
+		e.metrics[metricKey] = metric
+	}
+
+	dp := metric.ExponentialHistogram().DataPoints().AppendEmpty()
+	attributes.CopyTo(dp.Attributes())
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(m.Time()))
+	dp.SetScale(h.Schema)
+	dp.SetCount(toCount(h.Count))
+	dp.SetSum(h.Sum)
+	dp.SetZeroThreshold(h.ZeroThreshold)
+	dp.SetZeroCount(toCount(h.ZeroCount))
+	setBuckets(dp.Positive(), h.Positive)
+	setBuckets(dp.Negative(), h.Negative)
+}
+
+// appendTo moves the collected metrics to the given metrics
+func (e *exponentialHistograms) appendTo(md pmetric.Metrics) {
+	e.data.ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+}
+
+// setBuckets converts the buckets to OpenTelemetry where the index of a bucket
+// is one less than the Prometheus index used by the histogram
+func setBuckets(dst pmetric.ExponentialHistogramDataPointBuckets, src exphistogram.Buckets) {
+	if len(src.Counts) == 0 {
+		return
+	}
+	dst.SetOffset(src.Offset - 1)
+	counts := make([]uint64, 0, len(src.Counts))
+	for _, c := range src.Counts {
+		counts = append(counts, toCount(c))
+	}
+	dst.BucketCounts().FromRaw(counts)
+}
+
+func toCount(v float64) uint64 {
+	return uint64(math.Round(v))
+}
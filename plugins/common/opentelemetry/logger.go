@@ -0,0 +1,21 @@
+package opentelemetry
+
+import (
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Logger adapts a Telegraf logger to the logger interface of the
+// influxdb-observability converters. Messages are dropped if no logger is set.
+type Logger struct {
+	telegraf.Logger
+}
+
+func (l Logger) Debug(msg string, kv ...interface{}) {
+	if l.Logger == nil {
+		return
+	}
+	format := msg + strings.Repeat(" %s=%q", len(kv)/2)
+	l.Logger.Debugf(format, kv...)
+}
@@ -0,0 +1,50 @@
+package opentelemetry
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// moveUnitTag sets the unit of each metric from the given data-point attribute
+// and removes the attribute. If the data points of a metric carry different
+// units, the first one wins.
+func moveUnitTag(data pmetric.Metrics, tag string) {
+	for i := 0; i < data.ResourceMetrics().Len(); i++ {
+		scopes := data.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < scopes.Len(); j++ {
+			metrics := scopes.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				m := metrics.At(k)
+				switch m.Type() {
+				case pmetric.MetricTypeGauge:
+					dps := m.Gauge().DataPoints()
+					moveUnit(m, tag, dps.Len(), func(i int) pcommon.Map { return dps.At(i).Attributes() })
+				case pmetric.MetricTypeSum:
+					dps := m.Sum().DataPoints()
+					moveUnit(m, tag, dps.Len(), func(i int) pcommon.Map { return dps.At(i).Attributes() })
+				case pmetric.MetricTypeHistogram:
+					dps := m.Histogram().DataPoints()
+					moveUnit(m, tag, dps.Len(), func(i int) pcommon.Map { return dps.At(i).Attributes() })
+				case pmetric.MetricTypeExponentialHistogram:
+					dps := m.ExponentialHistogram().DataPoints()
+					moveUnit(m, tag, dps.Len(), func(i int) pcommon.Map { return dps.At(i).Attributes() })
+				case pmetric.MetricTypeSummary:
+					dps := m.Summary().DataPoints()
+					moveUnit(m, tag, dps.Len(), func(i int) pcommon.Map { return dps.At(i).Attributes() })
+				}
+			}
+		}
+	}
+}
+
+func moveUnit(m pmetric.Metric, tag string, n int, attributes func(int) pcommon.Map) {
+	for i := 0; i < n; i++ {
+		attrs := attributes(i)
+		if v, found := attrs.Get(tag); found {
+			if m.Unit() == "" {
+				m.SetUnit(v.Str())
+			}
+			attrs.Remove(tag)
+		}
+	}
+}
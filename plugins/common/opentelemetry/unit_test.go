@@ -0,0 +1,24 @@
+package opentelemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestMoveUnitTag(t *testing.T) {
+	data := pmetric.NewMetrics()
+	metrics := data.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	m := metrics.AppendEmpty()
+	m.SetName("sensors_temp_input")
+	dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.Attributes().PutStr("chip", "coretemp")
+	dp.Attributes().PutStr("unit", "Cel")
+	dp.SetDoubleValue(42)
+
+	moveUnitTag(data, "unit")
+
+	require.Equal(t, "Cel", m.Unit())
+	require.Equal(t, map[string]interface{}{"chip": "coretemp"}, dp.Attributes().AsRaw())
+}
@@ -0,0 +1,75 @@
+// Package protobuf provides helpers for working with user-supplied
+// protocol-buffer definitions at runtime.
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Load compiles the given protocol-buffer definition files and returns a
+// registry containing all definitions. Imported files are looked up in the
+// given import paths.
+func Load(files, importPaths []string) (*protoregistry.Files, error) {
+	// Load the file descriptors from the given protocol-buffer definition
+	resolver := &protocompile.SourceResolver{ImportPaths: importPaths}
+	compiler := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(resolver),
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing protocol-buffer definition failed: %w", err)
+	}
+	if len(compiled) < 1 {
+		return nil, errors.New("files do not contain a file descriptor")
+	}
+
+	// Register all definitions in the file in the registry
+	var registry protoregistry.Files
+	for _, f := range compiled {
+		if err := registry.RegisterFile(f); err != nil {
+			return nil, fmt.Errorf("adding file %q to registry failed: %w", f.Path(), err)
+		}
+	}
+	return &registry, nil
+}
+
+// FindMessage looks up the message with the given fully qualified name in the
+// registry. If the message cannot be found, the known definitions are logged
+// to help the user with fixing the configuration.
+func FindMessage(registry *protoregistry.Files, name string, log telegraf.Logger) (protoreflect.MessageDescriptor, error) {
+	msgFullName := protoreflect.FullName(name)
+	descriptor, err := registry.FindDescriptorByName(msgFullName)
+	if err != nil {
+		log.Infof("Could not find %q... Known messages:", msgFullName)
+
+		var known []string
+		registry.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+			name := strings.TrimSpace(string(fd.FullName()))
+			if name != "" {
+				known = append(known, name)
+			}
+			return true
+		})
+		sort.Strings(known)
+		for _, name := range known {
+			log.Infof("  %s", name)
+		}
+		return nil, err
+	}
+
+	msgDesc, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message descriptor (%T)", msgFullName, descriptor)
+	}
+	return msgDesc, nil
+}
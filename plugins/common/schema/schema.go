@@ -0,0 +1,309 @@
+// Package schema infers and tracks the schemas of metrics for outputs storing
+// metrics in tables or columnar files. A schema holds one column per tag and
+// field of all metrics with the same name. When fields appear or change their
+// type, the schema evolves by adding columns or by promoting column types
+// following the promotion rules.
+package schema
+
+import (
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Type is the format-independent type of a column
+type Type int
+
+const (
+	Boolean Type = iota + 1
+	Integer
+	Unsigned
+	Float
+	String
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Integer:
+		return "integer"
+	case Unsigned:
+		return "unsigned"
+	case Float:
+		return "float"
+	case String:
+		return "string"
+	}
+	return "unknown"
+}
+
+// TypeOf returns the column type for the given value
+func TypeOf(value interface{}) (Type, bool) {
+	switch value.(type) {
+	case bool:
+		return Boolean, true
+	case int, int8, int16, int32, int64:
+		return Integer, true
+	case uint, uint8, uint16, uint32, uint64:
+		return Unsigned, true
+	case float32, float64:
+		return Float, true
+	case string:
+		return String, true
+	}
+	return 0, false
+}
+
+// Promote returns the type able to hold values of both given types. Integer
+// and unsigned values are promoted to integer, integer and unsigned values
+// mixed with floats are promoted to float. All other combinations of distinct
+// types conflict and cannot be promoted.
+func Promote(a, b Type) (Type, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case (a == Integer && b == Unsigned) || (a == Unsigned && b == Integer):
+		return Integer, true
+	case a == Float && (b == Integer || b == Unsigned), b == Float && (a == Integer || a == Unsigned):
+		return Float, true
+	}
+	return 0, false
+}
+
+// Convert converts the value to the Go type representing the given column
+// type, i.e. bool, int64, uint64, float64 or string. The function returns
+// false if the value cannot be represented in the column type.
+func Convert(value interface{}, t Type) (interface{}, bool) {
+	switch v := normalize(value).(type) {
+	case bool:
+		return v, t == Boolean
+	case int64:
+		switch t {
+		case Integer:
+			return v, true
+		case Unsigned:
+			return uint64(v), v >= 0
+		case Float:
+			return float64(v), true
+		}
+	case uint64:
+		switch t {
+		case Integer:
+			return int64(v), v <= math.MaxInt64
+		case Unsigned:
+			return v, true
+		case Float:
+			return float64(v), true
+		}
+	case float64:
+		return v, t == Float
+	case string:
+		return v, t == String
+	}
+	return nil, false
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
+
+// Column describes a column holding the tag or field of the same name
+type Column struct {
+	Name string
+	Type Type
+	Tag  bool
+}
+
+// Schema holds the columns for the tags and fields of metrics with the same
+// name in the order of their appearance
+type Schema struct {
+	Name    string
+	Columns []Column
+}
+
+// Column returns the column with the given name
+func (s *Schema) Column(name string) (Column, bool) {
+	for _, c := range s.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// Tags returns the tag columns of the schema
+func (s *Schema) Tags() []Column {
+	return slices.DeleteFunc(slices.Clone(s.Columns), func(c Column) bool { return !c.Tag })
+}
+
+// Fields returns the field columns of the schema
+func (s *Schema) Fields() []Column {
+	return slices.DeleteFunc(slices.Clone(s.Columns), func(c Column) bool { return c.Tag })
+}
+
+func (s *Schema) clone() *Schema {
+	return &Schema{Name: s.Name, Columns: slices.Clone(s.Columns)}
+}
+
+// Change describes the evolution of a schema
+type Change struct {
+	// Added holds the new columns with tag columns first
+	Added []Column
+	// Promoted holds the existing columns with their new type
+	Promoted []Column
+}
+
+// Empty returns true if the schema did not change
+func (c *Change) Empty() bool {
+	return len(c.Added) == 0 && len(c.Promoted) == 0
+}
+
+// Infer returns the schema for the given metrics
+func Infer(name string, metrics []telegraf.Metric) *Schema {
+	s, _ := merge(&Schema{Name: name}, metrics, false)
+	return s
+}
+
+// Tracker keeps the schema for each metric name
+type Tracker struct {
+	// Promote allows existing columns to change their type following the
+	// promotion rules. If disabled, only the types of new columns are promoted
+	// while existing columns keep their type, e.g. because the type of an
+	// existing table column cannot be changed.
+	Promote bool
+
+	schemas map[string]*Schema
+	sync.Mutex
+}
+
+// Update merges the given metrics with the given name into the tracked schema
+// and returns the resulting schema together with the changes.
+func (t *Tracker) Update(name string, metrics []telegraf.Metric) (*Schema, Change) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.schemas == nil {
+		t.schemas = make(map[string]*Schema)
+	}
+	current, found := t.schemas[name]
+	if !found {
+		current = &Schema{Name: name}
+	}
+
+	s, change := merge(current, metrics, t.Promote)
+	t.schemas[name] = s
+	return s.clone(), change
+}
+
+// Schema returns the tracked schema for the metric name
+func (t *Tracker) Schema(name string) (*Schema, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	s, found := t.schemas[name]
+	if !found {
+		return nil, false
+	}
+	return s.clone(), true
+}
+
+// Reset forgets the schema of the metric name, e.g. if applying the changes
+// failed, so the next update reports all columns as added
+func (t *Tracker) Reset(name string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.schemas, name)
+}
+
+// merge adds the columns of the metrics to a copy of the given schema. Columns
+// added during the merge are promoted freely and turn into field columns if a
+// field of the same name appears, while existing columns are only promoted if
+// requested.
+func merge(current *Schema, metrics []telegraf.Metric, promote bool) (*Schema, Change) {
+	s := current.clone()
+	index := make(map[string]int, len(s.Columns))
+	for i, c := range s.Columns {
+		index[c.Name] = i
+	}
+	existing := len(s.Columns)
+
+	update := func(name string, t Type, tag bool) {
+		i, found := index[name]
+		if !found {
+			index[name] = len(s.Columns)
+			s.Columns = append(s.Columns, Column{Name: name, Type: t, Tag: tag})
+			return
+		}
+
+		c := &s.Columns[i]
+		isNew := i >= existing
+		switch {
+		case tag:
+			// Fields take precedence over tags of the same name
+		case c.Tag && isNew:
+			c.Type, c.Tag = t, false
+		case isNew || promote:
+			if promoted, ok := Promote(c.Type, t); ok {
+				c.Type = promoted
+			}
+		}
+	}
+
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			update(tag.Key, String, true)
+		}
+		for _, field := range m.FieldList() {
+			if t, ok := TypeOf(field.Value); ok {
+				update(field.Key, t, false)
+			}
+		}
+	}
+
+	var change Change
+	for i, c := range s.Columns {
+		if i >= existing {
+			continue
+		}
+		if c.Type != current.Columns[i].Type {
+			change.Promoted = append(change.Promoted, c)
+		}
+	}
+	added := s.Columns[existing:]
+	for _, c := range added {
+		if c.Tag {
+			change.Added = append(change.Added, c)
+		}
+	}
+	for _, c := range added {
+		if !c.Tag {
+			change.Added = append(change.Added, c)
+		}
+	}
+
+	return s, change
+}
@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func newMetric(tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	return metric.New("test", tags, fields, time.Unix(0, 0))
+}
+
+func TestPromote(t *testing.T) {
+	tests := []struct {
+		a, b     Type
+		expected Type
+		ok       bool
+	}{
+		{a: Integer, b: Integer, expected: Integer, ok: true},
+		{a: Integer, b: Unsigned, expected: Integer, ok: true},
+		{a: Unsigned, b: Integer, expected: Integer, ok: true},
+		{a: Integer, b: Float, expected: Float, ok: true},
+		{a: Float, b: Unsigned, expected: Float, ok: true},
+		{a: String, b: Float},
+		{a: Boolean, b: Integer},
+		{a: String, b: Boolean},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a.String()+"_"+tt.b.String(), func(t *testing.T) {
+			actual, ok := Promote(tt.a, tt.b)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		typ      Type
+		expected interface{}
+		ok       bool
+	}{
+		{name: "int to float", value: int64(3), typ: Float, expected: float64(3), ok: true},
+		{name: "uint to float", value: uint64(3), typ: Float, expected: float64(3), ok: true},
+		{name: "uint to int", value: uint64(3), typ: Integer, expected: int64(3), ok: true},
+		{name: "large uint to int", value: uint64(math.MaxUint64), typ: Integer, expected: int64(-1)},
+		{name: "int to uint", value: int64(3), typ: Unsigned, expected: uint64(3), ok: true},
+		{name: "negative int to uint", value: int64(-3), typ: Unsigned, expected: uint64(math.MaxUint64 - 2)},
+		{name: "int32 to int", value: int32(3), typ: Integer, expected: int64(3), ok: true},
+		{name: "float to int", value: 1.5, typ: Integer},
+		{name: "float to float", value: 1.5, typ: Float, expected: 1.5, ok: true},
+		{name: "string to float", value: "1.5", typ: Float, expected: "1.5"},
+		{name: "bool to bool", value: true, typ: Boolean, expected: true, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := Convert(tt.value, tt.typ)
+			require.Equal(t, tt.ok, ok)
+			if ok {
+				require.Equal(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInfer(t *testing.T) {
+	metrics := []telegraf.Metric{
+		newMetric(map[string]string{"host": "a", "status": "up"}, map[string]interface{}{"value": int64(1)}),
+		newMetric(map[string]string{"host": "b"}, map[string]interface{}{"value": 1.5, "status": int64(2), "ok": true}),
+		newMetric(map[string]string{"region": "eu"}, map[string]interface{}{"count": uint64(2)}),
+		newMetric(map[string]string{}, map[string]interface{}{"count": int64(-1), "ok": "yes"}),
+	}
+
+	expected := &Schema{
+		Name: "test",
+		Columns: []Column{
+			{Name: "host", Type: String, Tag: true},
+			{Name: "status", Type: Integer},
+			{Name: "value", Type: Float},
+			{Name: "ok", Type: Boolean},
+			{Name: "region", Type: String, Tag: true},
+			{Name: "count", Type: Integer},
+		},
+	}
+	actual := Infer("test", metrics)
+	require.Equal(t, expected, actual)
+	require.Equal(t, []Column{expected.Columns[0], expected.Columns[4]}, actual.Tags())
+
+	c, found := actual.Column("value")
+	require.True(t, found)
+	require.Equal(t, Float, c.Type)
+}
+
+func TestTracker(t *testing.T) {
+	tracker := &Tracker{Promote: true}
+
+	s, change := tracker.Update("test", []telegraf.Metric{
+		newMetric(map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}),
+	})
+	require.Len(t, s.Columns, 2)
+	require.Equal(t, []Column{{Name: "host", Type: String, Tag: true}, {Name: "value", Type: Integer}}, change.Added)
+	require.Empty(t, change.Promoted)
+
+	// Same schema
+	_, change = tracker.Update("test", []telegraf.Metric{
+		newMetric(map[string]string{"host": "b"}, map[string]interface{}{"value": int64(2)}),
+	})
+	require.True(t, change.Empty())
+
+	// Added columns with tags first and promoted columns
+	s, change = tracker.Update("test", []telegraf.Metric{
+		newMetric(map[string]string{}, map[string]interface{}{"value": 2.5, "count": int64(1)}),
+		newMetric(map[string]string{"region": "eu"}, map[string]interface{}{"count": 1.5}),
+	})
+	require.Equal(t, []Column{{Name: "region", Type: String, Tag: true}, {Name: "count", Type: Float}}, change.Added)
+	require.Equal(t, []Column{{Name: "value", Type: Float}}, change.Promoted)
+	require.Len(t, s.Columns, 4)
+
+	// Modifying the returned schema must not alter the tracked one
+	s.Columns[0].Name = "modified"
+	tracked, found := tracker.Schema("test")
+	require.True(t, found)
+	require.Equal(t, "host", tracked.Columns[0].Name)
+
+	// Resetting reports all columns as added again
+	tracker.Reset("test")
+	_, change = tracker.Update("test", []telegraf.Metric{
+		newMetric(map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}),
+	})
+	require.Len(t, change.Added, 2)
+}
+
+func TestTrackerWithoutPromotion(t *testing.T) {
+	var tracker Tracker
+
+	tracker.Update("test", []telegraf.Metric{
+		newMetric(map[string]string{}, map[string]interface{}{"value": int64(1)}),
+	})
+	s, change := tracker.Update("test", []telegraf.Metric{
+		newMetric(map[string]string{}, map[string]interface{}{"value": 1.5, "count": int64(1)}),
+		newMetric(map[string]string{}, map[string]interface{}{"count": 1.5}),
+	})
+
+	// Existing columns keep their type while new columns are promoted
+	require.Empty(t, change.Promoted)
+	require.Equal(t, []Column{{Name: "count", Type: Float}}, change.Added)
+	require.Equal(t, []Column{{Name: "value", Type: Integer}, {Name: "count", Type: Float}}, s.Columns)
+}
@@ -0,0 +1,184 @@
+// Package schemaregistry implements a client for Confluent-compatible Avro
+// schema registries.
+package schemaregistry
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// Schema is a schema stored in the registry together with its codec
+type Schema struct {
+	Schema string
+	Codec  *goavro.Codec
+}
+
+type Registry struct {
+	url      string
+	username string
+	password string
+	cache    map[int]*Schema
+	client   *http.Client
+	mu       sync.RWMutex
+}
+
+const (
+	schemaByID      = "%s/schemas/ids/%d"
+	subjectVersions = "%s/subjects/%s/versions"
+	subject         = "%s/subjects/%s"
+)
+
+// New creates a registry client for the given URL which may contain username
+// and password for basic authentication
+func New(addr, caCertPath string) (*Registry, error) {
+	var client *http.Client
+	var tlsCfg *tls.Config
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tlsCfg = &tls.Config{
+			RootCAs: caCertPool,
+		}
+	}
+	client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			MaxIdleConns:    10,
+			IdleConnTimeout: 90 * time.Second,
+		},
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL failed: %w", err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	registry := &Registry{
+		url:      u.String(),
+		username: username,
+		password: password,
+		cache:    make(map[int]*Schema),
+		client:   client,
+	}
+
+	return registry, nil
+}
+
+// Helper function to make managing lock easier
+func (sr *Registry) getSchemaFromCache(id int) (*Schema, error) {
+	// Read-lock the cache map before access.
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	if v, ok := sr.cache[id]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("schema %d not in cache", id)
+}
+
+// SchemaByID returns the schema with the given ID
+func (sr *Registry) SchemaByID(id int) (*Schema, error) {
+	v, err := sr.getSchemaFromCache(id)
+	if err == nil {
+		return v, nil
+	}
+
+	var response struct {
+		Schema *string `json:"schema"`
+	}
+	if err := sr.do(http.MethodGet, fmt.Sprintf(schemaByID, sr.url, id), nil, &response); err != nil {
+		return nil, err
+	}
+	if response.Schema == nil {
+		return nil, errors.New("malformed response from schema registry: no 'schema' key")
+	}
+
+	codec, err := goavro.NewCodec(*response.Schema)
+	if err != nil {
+		return nil, err
+	}
+	retval := &Schema{Schema: *response.Schema, Codec: codec}
+	// Lock the cache map before update.
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.cache[id] = retval
+	return retval, nil
+}
+
+// Register registers the schema under the given subject and returns the ID of
+// the schema. If the schema is already registered, the existing ID is returned.
+func (sr *Registry) Register(subj, schema string) (int, error) {
+	return sr.submit(fmt.Sprintf(subjectVersions, sr.url, url.PathEscape(subj)), schema)
+}
+
+// Lookup returns the ID of the schema registered under the given subject
+func (sr *Registry) Lookup(subj, schema string) (int, error) {
+	return sr.submit(fmt.Sprintf(subject, sr.url, url.PathEscape(subj)), schema)
+}
+
+func (sr *Registry) submit(addr, schema string) (int, error) {
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		ID *int `json:"id"`
+	}
+	if err := sr.do(http.MethodPost, addr, body, &response); err != nil {
+		return 0, err
+	}
+	if response.ID == nil {
+		return 0, errors.New("malformed response from schema registry: no 'id' key")
+	}
+	return *response.ID, nil
+}
+
+func (sr *Registry) do(method, addr string, body []byte, response interface{}) error {
+	req, err := http.NewRequest(method, addr, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+
+	if sr.username != "" {
+		req.SetBasicAuth(sr.username, sr.password)
+	}
+
+	resp, err := sr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("schema registry returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -55,6 +55,10 @@ type Tail struct {
 	parserFunc telegraf.ParserFunc
 	wg         sync.WaitGroup
 
+	// Protects the tailers and offsets as the state might be requested
+	// while the plugin is running
+	mu sync.Mutex
+
 	acc telegraf.TrackingAccumulator
 
 	MultilineConfig multilineConfig `toml:"multiline"`
@@ -144,7 +148,9 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	t.mu.Lock()
 	t.tailers = make(map[string]*tail.Tail)
+	t.mu.Unlock()
 
 	err = t.tailNewFiles()
 	if err != nil {
@@ -189,7 +195,27 @@ func (t *Tail) getSeekInfo(file string) (*tail.SeekInfo, error) {
 }
 
 func (t *Tail) GetState() interface{} {
-	return t.offsets
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Use the current position of the running tailers falling back to the
+	// recorded offsets e.g. for files not tailed anymore
+	state := make(map[string]int64, len(t.offsets)+len(t.tailers))
+	for k, v := range t.offsets {
+		state[k] = v
+	}
+	if t.Pipe {
+		return state
+	}
+	for _, tailer := range t.tailers {
+		offset, err := tailer.Tell()
+		if err != nil {
+			t.Log.Debugf("Getting offset for %q failed: %v", tailer.Filename, err)
+			continue
+		}
+		state[tailer.Filename] = offset
+	}
+	return state
 }
 
 func (t *Tail) SetState(state interface{}) error {
@@ -197,6 +223,9 @@ func (t *Tail) SetState(state interface{}) error {
 	if !ok {
 		return errors.New("state has to be of type 'map[string]int64'")
 	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	for k, v := range offsetsState {
 		t.offsets[k] = v
 	}
@@ -208,6 +237,7 @@ func (t *Tail) Gather(_ telegraf.Accumulator) error {
 }
 
 func (t *Tail) Stop() {
+	t.mu.Lock()
 	for _, tailer := range t.tailers {
 		if !t.Pipe {
 			// store offset for resume
@@ -224,16 +254,21 @@ func (t *Tail) Stop() {
 			t.Log.Errorf("Stopping tail on %q: %s", tailer.Filename, err.Error())
 		}
 	}
+	// All tailers are stopped, so only use the recorded offsets from now on
+	t.tailers = make(map[string]*tail.Tail)
+	t.mu.Unlock()
 
 	t.cancel()
 	t.wg.Wait()
 
 	// persist offsets
+	t.mu.Lock()
 	offsetsMutex.Lock()
 	for k, v := range t.offsets {
 		offsets[k] = v
 	}
 	offsetsMutex.Unlock()
+	t.mu.Unlock()
 }
 
 func (t *Tail) tailNewFiles() error {
@@ -242,6 +277,9 @@ func (t *Tail) tailNewFiles() error {
 		poll = true
 	}
 
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	// Create a "tailer" for each file
 	for _, filepath := range t.Files {
 		g, err := globpath.Compile(filepath)
@@ -299,7 +337,9 @@ func (t *Tail) tailNewFiles() error {
 				if err := tailer.Err(); err != nil {
 					if strings.HasSuffix(err.Error(), "permission denied") {
 						t.Log.Errorf("Deleting tailer for %q due to: %v", tailer.Filename, err)
+						t.mu.Lock()
 						delete(t.tailers, tailer.Filename)
+						t.mu.Unlock()
 					} else {
 						t.Log.Errorf("Tailing %q: %s", tailer.Filename, err.Error())
 					}
@@ -798,6 +798,41 @@ func TestStatePersistence(t *testing.T) {
 	require.Equal(t, expectedState, actualState)
 }
 
+func TestStateWhileRunning(t *testing.T) {
+	lines := []string{
+		"metric,tag=value foo=1i 1730478201000000000\n",
+		"metric,tag=value foo=2i 1730478211000000000\n",
+	}
+	content := []byte(strings.Join(lines, ""))
+
+	inputFilename := filepath.Join(t.TempDir(), "input.influx")
+	require.NoError(t, os.WriteFile(inputFilename, content, 0600))
+
+	plugin := &Tail{
+		Files:               []string{inputFilename},
+		InitialReadOffset:   "beginning",
+		MaxUndeliveredLines: 1000,
+		Log:                 testutil.Logger{},
+	}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(lines))
+	}, time.Second, 10*time.Millisecond)
+
+	// The state must contain the current offset without stopping the plugin
+	var pi telegraf.StatefulPlugin = plugin
+	require.Eventually(t, func() bool {
+		state, ok := pi.GetState().(map[string]int64)
+		return ok && state[inputFilename] == int64(len(content))
+	}, time.Second, 10*time.Millisecond)
+}
+
 func TestGetSeekInfo(t *testing.T) {
 	tests := []struct {
 		name     string
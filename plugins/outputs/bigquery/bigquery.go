@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/schema"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -38,10 +39,12 @@ type BigQuery struct {
 	Timeout         config.Duration `toml:"timeout"`
 	ReplaceHyphenTo string          `toml:"replace_hyphen_to"`
 	CompactTable    string          `toml:"compact_table"`
+	SchemaEvolution string          `toml:"schema_evolution"`
 
 	Log telegraf.Logger `toml:"-"`
 
-	client *bigquery.Client
+	client  *bigquery.Client
+	tracker *schema.Tracker
 
 	warnedOnHyphens map[string]bool
 }
@@ -59,7 +62,16 @@ func (s *BigQuery) Init() error {
 		return errors.New(`"dataset" is required`)
 	}
 
+	switch s.SchemaEvolution {
+	case "":
+		s.SchemaEvolution = "none"
+	case "none", "widen":
+	default:
+		return fmt.Errorf("invalid schema_evolution %q", s.SchemaEvolution)
+	}
+
 	s.warnedOnHyphens = make(map[string]bool)
+	s.tracker = &schema.Tracker{}
 
 	return nil
 }
@@ -118,6 +130,16 @@ func (s *BigQuery) Write(metrics []telegraf.Metric) error {
 		return s.writeCompact(metrics)
 	}
 
+	if s.SchemaEvolution == "widen" {
+		byName := make(map[string][]telegraf.Metric)
+		for _, m := range metrics {
+			byName[m.Name()] = append(byName[m.Name()], m)
+		}
+		for name, group := range byName {
+			s.updateTable(name, group)
+		}
+	}
+
 	groupedMetrics := groupByMetricName(metrics)
 
 	var wg sync.WaitGroup
@@ -293,6 +315,65 @@ func (s *BigQuery) insertToTable(metricName string, metrics []bigquery.ValueSave
 	}
 }
 
+// updateTable adds the columns of the metrics missing in the table of the
+// given metric name. Column types of existing columns are never changed.
+func (s *BigQuery) updateTable(metricName string, metrics []telegraf.Metric) {
+	_, change := s.tracker.Update(metricName, metrics)
+	if len(change.Added) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.Timeout))
+	defer cancel()
+
+	table := s.client.Dataset(s.Dataset).Table(s.metricToTable(metricName))
+	meta, err := table.Metadata(ctx)
+	if err != nil {
+		s.Log.Errorf("getting table schema for metric %q failed: %v", metricName, err)
+		s.tracker.Reset(metricName)
+		return
+	}
+
+	existing := make(map[string]bool, len(meta.Schema))
+	for _, f := range meta.Schema {
+		existing[f.Name] = true
+	}
+	updated := meta.Schema
+	for _, c := range change.Added {
+		if existing[c.Name] {
+			continue
+		}
+		updated = append(updated, &bigquery.FieldSchema{
+			Name: c.Name,
+			Type: schemaTypeToBqType(c.Type),
+		})
+	}
+	if len(updated) == len(meta.Schema) {
+		return
+	}
+
+	if _, err := table.Update(ctx, bigquery.TableMetadataToUpdate{Schema: updated}, meta.ETag); err != nil {
+		s.Log.Errorf("updating table schema for metric %q failed: %v", metricName, err)
+		s.tracker.Reset(metricName)
+		return
+	}
+	s.Log.Debugf("Added %d column(s) to table of metric %q", len(updated)-len(meta.Schema), metricName)
+}
+
+func schemaTypeToBqType(t schema.Type) bigquery.FieldType {
+	switch t {
+	case schema.Integer, schema.Unsigned:
+		return bigquery.IntegerFieldType
+	case schema.Float:
+		return bigquery.FloatFieldType
+	case schema.Boolean:
+		return bigquery.BooleanFieldType
+	default:
+		return bigquery.StringFieldType
+	}
+}
+
 func (s *BigQuery) metricToTable(metricName string) string {
 	if !strings.Contains(metricName, "-") {
 		return metricName
@@ -13,11 +13,22 @@ import (
 	"google.golang.org/api/option"
 	"google.golang.org/api/option/internaloption"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 )
 
 const (
-	successfulResponse = `{"kind": "bigquery#tableDataInsertAllResponse"}`
+	successfulResponse    = `{"kind": "bigquery#tableDataInsertAllResponse"}`
+	tableMetadataResponse = `{
+		"kind": "bigquery#table",
+		"etag": "etag1",
+		"tableReference": {"projectId": "test-project", "datasetId": "test-dataset", "tableId": "test1"},
+		"schema": {"fields": [
+			{"name": "timestamp", "type": "TIMESTAMP"},
+			{"name": "tag1", "type": "STRING"},
+			{"name": "value", "type": "FLOAT"}
+		]}
+	}`
 )
 
 var receivedBody map[string]json.RawMessage
@@ -39,6 +50,14 @@ func TestInit(t *testing.T) {
 			errorString: `"dataset" is required`,
 			plugin:      &BigQuery{},
 		},
+		{
+			name:        "invalid schema evolution",
+			errorString: `invalid schema_evolution "rotate"`,
+			plugin: &BigQuery{
+				Dataset:         "test-dataset",
+				SchemaEvolution: "rotate",
+			},
+		},
 		{
 			name: "valid config",
 			plugin: &BigQuery{
@@ -302,3 +321,80 @@ func localBigQueryServer(t *testing.T) *httptest.Server {
 
 	return srv
 }
+
+func TestWriteSchemaEvolution(t *testing.T) {
+	var updated []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/projects/test-project/datasets/test-dataset/tables/test1" && r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if _, err := w.Write([]byte(tableMetadataResponse)); err != nil {
+				t.Error(err)
+			}
+		case r.URL.Path == "/projects/test-project/datasets/test-dataset/tables/test1" && r.Method == http.MethodPatch:
+			var body struct {
+				Schema struct {
+					Fields []map[string]interface{} `json:"fields"`
+				} `json:"schema"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			updated = body.Schema.Fields
+			w.WriteHeader(http.StatusOK)
+			if _, err := w.Write([]byte(tableMetadataResponse)); err != nil {
+				t.Error(err)
+			}
+		case r.URL.Path == "/projects/test-project/datasets/test-dataset/tables/test1/insertAll":
+			w.WriteHeader(http.StatusOK)
+			if _, err := w.Write([]byte(successfulResponse)); err != nil {
+				t.Error(err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:         "test-project",
+		Dataset:         "test-dataset",
+		Timeout:         defaultTimeout,
+		SchemaEvolution: "widen",
+		Log:             testutil.Logger{},
+	}
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"test1",
+			map[string]string{"tag1": "value1", "tag2": "value2"},
+			map[string]interface{}{"value": 1.0, "count": int64(2), "ok": true},
+			time.Unix(0, 0),
+		),
+	}
+
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+	require.NoError(t, b.Write(metrics))
+
+	names := make([]string, 0, len(updated))
+	types := make(map[string]interface{}, len(updated))
+	for _, f := range updated {
+		name, ok := f["name"].(string)
+		require.True(t, ok)
+		names = append(names, name)
+		types[name] = f["type"]
+	}
+	require.Equal(t, []string{"timestamp", "tag1", "value", "tag2", "count", "ok"}, names)
+	require.Equal(t, "STRING", types["tag2"])
+	require.Equal(t, "INTEGER", types["count"])
+	require.Equal(t, "BOOLEAN", types["ok"])
+
+	// Writing the same schema again must not update the table
+	updated = nil
+	require.NoError(t, b.Write(metrics))
+	require.Nil(t, updated)
+}
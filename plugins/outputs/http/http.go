@@ -8,10 +8,13 @@ import (
 	"crypto/sha256"
 	_ "embed"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -35,6 +38,8 @@ const (
 	defaultURL   = "http://127.0.0.1:8080/telegraf"
 )
 
+var errNonRetryable = errors.New("received non-retryable status")
+
 const (
 	defaultContentType    = "text/plain; charset=utf-8"
 	defaultMethod         = http.MethodPost
@@ -54,8 +59,9 @@ type HTTP struct {
 	common_http.HTTPClientConfig
 	Log telegraf.Logger `toml:"-"`
 
-	client     *http.Client
-	serializer telegraf.Serializer
+	client         *http.Client
+	serializer     telegraf.Serializer
+	serializerLock sync.Mutex
 
 	awsCfg *aws.Config
 	common_aws.CredentialConfig
@@ -63,6 +69,7 @@ type HTTP struct {
 	// Google API Auth
 	CredentialsFile string `toml:"google_application_credentials"`
 	oauth2Token     *oauth2.Token
+	tokenLock       sync.Mutex
 }
 
 func (*HTTP) SampleConfig() string {
@@ -73,6 +80,12 @@ func (h *HTTP) SetSerializer(serializer telegraf.Serializer) {
 	h.serializer = serializer
 }
 
+// SupportsConcurrentWrites allows to send multiple batches at the same time
+// as the client is safe for concurrent use.
+func (*HTTP) SupportsConcurrentWrites() bool {
+	return true
+}
+
 func (h *HTTP) Connect() error {
 	if h.AwsService != "" {
 		cfg, err := h.CredentialConfig.Credentials()
@@ -110,23 +123,56 @@ func (h *HTTP) Close() error {
 
 func (h *HTTP) Write(metrics []telegraf.Metric) error {
 	if h.UseBatchFormat {
+		h.serializerLock.Lock()
 		reqBody, err := h.serializer.SerializeBatch(metrics)
+		h.serializerLock.Unlock()
 		if err != nil {
 			return err
 		}
 
-		return h.writeMetric(reqBody)
+		err = h.writeMetric(reqBody)
+		if errors.Is(err, errNonRetryable) {
+			writeErr := &internal.PartialWriteError{
+				Err:           err,
+				MetricsReject: make([]int, 0, len(metrics)),
+			}
+			for i := range metrics {
+				writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			}
+			return writeErr
+		}
+		return err
 	}
 
-	for _, metric := range metrics {
+	// Reject the metrics failing to serialize or refused by the server so
+	// they can be handled by the dead-letter sink of the output. On retryable
+	// errors stop writing and report the metrics handled so far to avoid
+	// sending them again.
+	writeErr := &internal.PartialWriteError{}
+	for i, metric := range metrics {
+		h.serializerLock.Lock()
 		reqBody, err := h.serializer.Serialize(metric)
+		h.serializerLock.Unlock()
 		if err != nil {
-			return err
+			writeErr.Err = internal.ErrSerialization
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, err)
+			continue
 		}
 
 		if err := h.writeMetric(reqBody); err != nil {
-			return err
+			if !errors.Is(err, errNonRetryable) {
+				writeErr.Err = err
+				return writeErr
+			}
+			writeErr.Err = err
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			continue
 		}
+		writeErr.MetricsAccept = append(writeErr.MetricsAccept, i)
+	}
+	if len(writeErr.MetricsReject) > 0 {
+		return writeErr
 	}
 	return nil
 }
@@ -236,11 +282,8 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 			errorLine = scanner.Text()
 		}
 
-		for _, nonRetryableStatusCode := range h.NonRetryableStatusCodes {
-			if resp.StatusCode == nonRetryableStatusCode {
-				h.Log.Errorf("Received non-retryable status %v. Metrics are lost. body: %s", resp.StatusCode, errorLine)
-				return nil
-			}
+		if slices.Contains(h.NonRetryableStatusCodes, resp.StatusCode) {
+			return fmt.Errorf("%w %d when writing to [%s], metrics are rejected. body: %s", errNonRetryable, resp.StatusCode, h.URL, errorLine)
 		}
 
 		return fmt.Errorf("when writing to [%s] received status code: %d. body: %s", h.URL, resp.StatusCode, errorLine)
@@ -265,6 +308,9 @@ func init() {
 }
 
 func (h *HTTP) getAccessToken(ctx context.Context, audience string) (*oauth2.Token, error) {
+	h.tokenLock.Lock()
+	defer h.tokenLock.Unlock()
+
 	if h.oauth2Token.Valid() {
 		return h.oauth2Token, nil
 	}
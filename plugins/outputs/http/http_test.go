@@ -262,7 +262,10 @@ func TestStatusCode(t *testing.T) {
 			},
 			statusCode: http.StatusConflict,
 			errFunc: func(t *testing.T, err error) {
-				require.NoError(t, err)
+				var writeErr *internal.PartialWriteError
+				require.ErrorAs(t, err, &writeErr)
+				require.Empty(t, writeErr.MetricsAccept)
+				require.Equal(t, []int{0}, writeErr.MetricsReject)
 			},
 		},
 	}
@@ -287,6 +290,72 @@ func TestStatusCode(t *testing.T) {
 	}
 }
 
+func TestRetryablePartialWrite(t *testing.T) {
+	// Accept the first metric, refuse the second one and fail on the third
+	statusCodes := []int{http.StatusOK, http.StatusConflict, http.StatusInternalServerError}
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(statusCodes[min(requests, len(statusCodes)-1)])
+		requests++
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:                     ts.URL,
+		NonRetryableStatusCodes: []int{http.StatusConflict},
+		Log:                     testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	// Writing must stop at the retryable error and report the metrics
+	// handled so far
+	err := plugin.Write(getMetrics(4))
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ErrorContains(t, writeErr.Err, "when writing to")
+	require.NotErrorIs(t, writeErr.Err, errNonRetryable)
+	require.Equal(t, []int{0}, writeErr.MetricsAccept)
+	require.Equal(t, []int{1}, writeErr.MetricsReject)
+	require.Equal(t, 3, requests)
+}
+
+func TestSerializationErrorPartialWrite(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL: ts.URL,
+		Log: testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	// The metric without fields cannot be serialized and must be rejected
+	// while the other metrics are written
+	metrics := []telegraf.Metric{
+		getMetric(),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{}, time.Unix(0, 0)),
+		getMetric(),
+	}
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ErrorIs(t, err, internal.ErrSerialization)
+	require.Equal(t, []int{0, 2}, writeErr.MetricsAccept)
+	require.Equal(t, []int{1}, writeErr.MetricsReject)
+	require.Len(t, writeErr.MetricsRejectErrors, 1)
+	require.Equal(t, 2, requests)
+}
+
 func TestContentType(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -8,8 +8,6 @@ import (
 	"sort"
 	"time"
 
-	"github.com/influxdata/influxdb-observability/common"
-	"github.com/influxdata/influxdb-observability/influx2otel"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -20,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	common_otel "github.com/influxdata/telegraf/plugins/common/opentelemetry"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -37,11 +36,12 @@ type OpenTelemetry struct {
 	Compression string            `toml:"compression"`
 	Headers     map[string]string `toml:"headers"`
 	Attributes  map[string]string `toml:"attributes"`
+	UnitTag     string            `toml:"unit_tag"`
 	Coralogix   *CoralogixConfig  `toml:"coralogix"`
 
 	Log telegraf.Logger `toml:"-"`
 
-	metricsConverter     *influx2otel.LineProtocolToOtelMetrics
+	converter            *common_otel.MetricsConverter
 	grpcClientConn       *grpc.ClientConn
 	metricsServiceClient pmetricotlp.GRPCClient
 	callOptions          []grpc.CallOption
@@ -58,8 +58,6 @@ func (*OpenTelemetry) SampleConfig() string {
 }
 
 func (o *OpenTelemetry) Connect() error {
-	logger := &otelLogger{o.Log}
-
 	if o.ServiceAddress == "" {
 		o.ServiceAddress = defaultServiceAddress
 	}
@@ -78,10 +76,11 @@ func (o *OpenTelemetry) Connect() error {
 		o.Headers["Authorization"] = "Bearer " + o.Coralogix.PrivateKey
 	}
 
-	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(logger)
+	converter, err := common_otel.NewMetricsConverter(o.Log)
 	if err != nil {
 		return err
 	}
+	converter.UnitTag = o.UnitTag
 
 	var grpcTLSDialOption grpc.DialOption
 	if tlsConfig, err := o.ClientConfig.TLSConfig(); err != nil {
@@ -102,7 +101,7 @@ func (o *OpenTelemetry) Connect() error {
 
 	metricsServiceClient := pmetricotlp.NewGRPCClient(grpcClientConn)
 
-	o.metricsConverter = metricsConverter
+	o.converter = converter
 	o.grpcClientConn = grpcClientConn
 	o.metricsServiceClient = metricsServiceClient
 
@@ -150,32 +149,8 @@ func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
 }
 
 func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
-	batch := o.metricsConverter.NewBatch()
-	for _, metric := range metrics {
-		var vType common.InfluxMetricValueType
-		switch metric.Type() {
-		case telegraf.Gauge:
-			vType = common.InfluxMetricValueTypeGauge
-		case telegraf.Untyped:
-			vType = common.InfluxMetricValueTypeUntyped
-		case telegraf.Counter:
-			vType = common.InfluxMetricValueTypeSum
-		case telegraf.Histogram:
-			vType = common.InfluxMetricValueTypeHistogram
-		case telegraf.Summary:
-			vType = common.InfluxMetricValueTypeSummary
-		default:
-			o.Log.Warnf("Unrecognized metric type %v", metric.Type())
-			continue
-		}
-		err := batch.AddPoint(metric.Name(), metric.Tags(), metric.Fields(), metric.Time(), vType)
-		if err != nil {
-			o.Log.Warnf("Failed to add point: %v", err)
-			continue
-		}
-	}
-
-	md := pmetricotlp.NewExportRequestFromMetrics(batch.GetMetrics())
+	data := o.converter.Convert(metrics)
+	md := pmetricotlp.NewExportRequestFromMetrics(data)
 	if md.Metrics().ResourceMetrics().Len() == 0 {
 		return nil
 	}
@@ -6,8 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/influxdata/influxdb-observability/common"
-	"github.com/influxdata/influxdb-observability/influx2otel"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -19,6 +17,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	common_otel "github.com/influxdata/telegraf/plugins/common/opentelemetry"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -41,14 +40,14 @@ func TestOpenTelemetry(t *testing.T) {
 	m := newMockOtelService(t)
 	t.Cleanup(m.Cleanup)
 
-	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	converter, err := common_otel.NewMetricsConverter(testutil.Logger{})
 	require.NoError(t, err)
 	plugin := &OpenTelemetry{
 		ServiceAddress:       m.Address(),
 		Timeout:              config.Duration(time.Second),
 		Headers:              map[string]string{"test": "header1"},
 		Attributes:           map[string]string{"attr-key": "attr-val"},
-		metricsConverter:     metricsConverter,
+		converter:            converter,
 		grpcClientConn:       m.GrpcClient(),
 		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
 		Log:                  testutil.Logger{},
@@ -81,6 +80,76 @@ func TestOpenTelemetry(t *testing.T) {
 	require.JSONEq(t, string(expectJSON), string(gotJSON))
 }
 
+func TestOpenTelemetryExponentialHistogram(t *testing.T) {
+	expect := pmetric.NewMetrics()
+	{
+		rm := expect.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("host.name", "potato")
+		ilm := rm.ScopeMetrics().AppendEmpty()
+		m := ilm.Metrics().AppendEmpty()
+		m.SetName("http_latency")
+		m.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp := m.ExponentialHistogram().DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("path", "/")
+		dp.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+		dp.SetScale(3)
+		dp.SetCount(4)
+		dp.SetSum(14.5)
+		dp.SetZeroCount(1)
+		dp.Positive().SetOffset(7)
+		dp.Positive().BucketCounts().FromRaw([]uint64{2, 0, 1})
+	}
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	converter, err := common_otel.NewMetricsConverter(testutil.Logger{})
+	require.NoError(t, err)
+	plugin := &OpenTelemetry{
+		ServiceAddress:       m.Address(),
+		Timeout:              config.Duration(time.Second),
+		Headers:              map[string]string{"test": "header1"},
+		converter:            converter,
+		grpcClientConn:       m.GrpcClient(),
+		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
+		Log:                  testutil.Logger{},
+	}
+
+	input := testutil.MustMetric(
+		"http_latency",
+		map[string]string{
+			"path":      "/",
+			"host.name": "potato",
+		},
+		map[string]interface{}{
+			"count":                  float64(4),
+			"sum":                    float64(14.5),
+			"schema":                 int64(3),
+			"counter_reset_hint":     uint64(0),
+			"zero_threshold":         float64(0),
+			"zero_count":             float64(1),
+			"positive_span_0_offset": int64(8),
+			"positive_span_0_length": uint64(1),
+			"positive_span_1_offset": int64(1),
+			"positive_span_1_length": uint64(1),
+			"positive_bucket_0":      float64(2),
+			"positive_bucket_1":      float64(1),
+		},
+		time.Unix(0, 1622848686000000000),
+		telegraf.Histogram,
+	)
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{input}))
+
+	marshaller := pmetric.JSONMarshaler{}
+	expectJSON, err := marshaller.MarshalMetrics(expect)
+	require.NoError(t, err)
+
+	gotJSON, err := marshaller.MarshalMetrics(m.GotMetrics())
+	require.NoError(t, err)
+
+	require.JSONEq(t, string(expectJSON), string(gotJSON))
+}
+
 var _ pmetricotlp.GRPCServer = (*mockOtelService)(nil)
 
 type mockOtelService struct {
@@ -17,6 +17,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	common_arrow "github.com/influxdata/telegraf/plugins/common/arrow"
+	"github.com/influxdata/telegraf/plugins/common/schema"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -36,8 +38,11 @@ type Parquet struct {
 	Directory          string          `toml:"directory"`
 	RotationInterval   config.Duration `toml:"rotation_interval"`
 	TimestampFieldName string          `toml:"timestamp_field_name"`
+	SchemaEvolution    string          `toml:"schema_evolution"`
 	Log                telegraf.Logger `toml:"-"`
 
+	converter    *common_arrow.Converter
+	tracker      *schema.Tracker
 	metricGroups map[string]*metricGroup
 }
 
@@ -50,6 +55,14 @@ func (p *Parquet) Init() error {
 		p.Directory = "."
 	}
 
+	switch p.SchemaEvolution {
+	case "":
+		p.SchemaEvolution = "none"
+	case "none", "rotate":
+	default:
+		return fmt.Errorf("invalid schema_evolution %q", p.SchemaEvolution)
+	}
+
 	stat, err := os.Stat(p.Directory)
 	if os.IsNotExist(err) {
 		if err := os.MkdirAll(p.Directory, 0750); err != nil {
@@ -59,6 +72,11 @@ func (p *Parquet) Init() error {
 		return fmt.Errorf("provided directory %q is not a directory", p.Directory)
 	}
 
+	p.converter = &common_arrow.Converter{
+		TimestampColumn: p.TimestampFieldName,
+		SkipInvalid:     true,
+	}
+	p.tracker = &schema.Tracker{Promote: true}
 	p.metricGroups = make(map[string]*metricGroup)
 
 	return nil
@@ -91,23 +109,24 @@ func (p *Parquet) Write(metrics []telegraf.Metric) error {
 		groupedMetrics[metric.Name()] = append(groupedMetrics[metric.Name()], metric)
 	}
 
-	now := time.Now()
 	for name, metrics := range groupedMetrics {
-		if _, ok := p.metricGroups[name]; !ok {
-			filename := fmt.Sprintf("%s/%s-%s-%s.parquet", p.Directory, name, now.Format("2006-01-02"), strconv.FormatInt(now.Unix(), 10))
-			schema, err := p.createSchema(metrics)
-			if err != nil {
-				return fmt.Errorf("failed to create schema for file %q: %w", name, err)
+		s, change := p.tracker.Update(name, metrics)
+		if group, found := p.metricGroups[name]; !found {
+			if err := p.addMetricGroup(name, s); err != nil {
+				p.tracker.Reset(name)
+				return err
 			}
-			writer, err := p.createWriter(name, filename, schema)
-			if err != nil {
-				return fmt.Errorf("failed to create writer for file %q: %w", name, err)
+		} else if p.SchemaEvolution == "rotate" && !change.Empty() {
+			// Start a new file as the schema of an existing file cannot change
+			p.Log.Debugf("Schema of %q changed, rotating file %q", name, group.filename)
+			if err := group.writer.Close(); err != nil {
+				p.Log.Errorf("failed to close file %q: %v", group.filename, err)
 			}
-			p.metricGroups[name] = &metricGroup{
-				builder:  array.NewRecordBuilder(memory.DefaultAllocator, schema),
-				filename: filename,
-				schema:   schema,
-				writer:   writer,
+			group.builder.Release()
+			delete(p.metricGroups, name)
+			if err := p.addMetricGroup(name, s); err != nil {
+				p.tracker.Reset(name)
+				return err
 			}
 		}
 
@@ -117,7 +136,7 @@ func (p *Parquet) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
-		record, err := p.createRecord(metrics, p.metricGroups[name].builder, p.metricGroups[name].schema)
+		record, err := p.converter.Record(p.metricGroups[name].builder, metrics)
 		if err != nil {
 			return fmt.Errorf("failed to create record for file %q: %w", p.metricGroups[name].filename, err)
 		}
@@ -130,6 +149,36 @@ func (p *Parquet) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+func (p *Parquet) addMetricGroup(name string, s *schema.Schema) error {
+	filename := p.newFilename(name)
+	arrowSchema := p.converter.ArrowSchema(s)
+	writer, err := p.createWriter(name, filename, arrowSchema)
+	if err != nil {
+		return fmt.Errorf("failed to create writer for file %q: %w", name, err)
+	}
+	p.metricGroups[name] = &metricGroup{
+		builder:  array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema),
+		filename: filename,
+		schema:   arrowSchema,
+		writer:   writer,
+	}
+	return nil
+}
+
+// newFilename returns the name of a new file for the metric name, adding a
+// counter if a file of the same name already exists
+func (p *Parquet) newFilename(name string) string {
+	now := time.Now()
+	base := fmt.Sprintf("%s/%s-%s-%s", p.Directory, name, now.Format("2006-01-02"), strconv.FormatInt(now.Unix(), 10))
+	filename := base + ".parquet"
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			return filename
+		}
+		filename = fmt.Sprintf("%s-%d.parquet", base, i)
+	}
+}
+
 func (p *Parquet) rotateIfNeeded(name string) error {
 	fileInfo, err := os.Stat(p.metricGroups[name].filename)
 	if err != nil {
@@ -154,128 +203,6 @@ func (p *Parquet) rotateIfNeeded(name string) error {
 	return nil
 }
 
-func (p *Parquet) createRecord(metrics []telegraf.Metric, builder *array.RecordBuilder, schema *arrow.Schema) (arrow.Record, error) {
-	for index, col := range schema.Fields() {
-		for _, m := range metrics {
-			if p.TimestampFieldName != "" && col.Name == p.TimestampFieldName {
-				builder.Field(index).(*array.Int64Builder).Append(m.Time().UnixNano())
-				continue
-			}
-
-			// Try to get the value from a field first, then from a tag.
-			var value any
-			var ok bool
-			value, ok = m.GetField(col.Name)
-			if !ok {
-				value, ok = m.GetTag(col.Name)
-			}
-
-			// if neither field nor tag exists, append a null value
-			if !ok {
-				switch col.Type {
-				case arrow.PrimitiveTypes.Int8:
-					builder.Field(index).(*array.Int8Builder).AppendNull()
-				case arrow.PrimitiveTypes.Int16:
-					builder.Field(index).(*array.Int16Builder).AppendNull()
-				case arrow.PrimitiveTypes.Int32:
-					builder.Field(index).(*array.Int32Builder).AppendNull()
-				case arrow.PrimitiveTypes.Int64:
-					builder.Field(index).(*array.Int64Builder).AppendNull()
-				case arrow.PrimitiveTypes.Uint8:
-					builder.Field(index).(*array.Uint8Builder).AppendNull()
-				case arrow.PrimitiveTypes.Uint16:
-					builder.Field(index).(*array.Uint16Builder).AppendNull()
-				case arrow.PrimitiveTypes.Uint32:
-					builder.Field(index).(*array.Uint32Builder).AppendNull()
-				case arrow.PrimitiveTypes.Uint64:
-					builder.Field(index).(*array.Uint64Builder).AppendNull()
-				case arrow.PrimitiveTypes.Float32:
-					builder.Field(index).(*array.Float32Builder).AppendNull()
-				case arrow.PrimitiveTypes.Float64:
-					builder.Field(index).(*array.Float64Builder).AppendNull()
-				case arrow.BinaryTypes.String:
-					builder.Field(index).(*array.StringBuilder).AppendNull()
-				case arrow.FixedWidthTypes.Boolean:
-					builder.Field(index).(*array.BooleanBuilder).AppendNull()
-				default:
-					return nil, fmt.Errorf("unsupported type: %T", value)
-				}
-
-				continue
-			}
-
-			switch col.Type {
-			case arrow.PrimitiveTypes.Int8:
-				builder.Field(index).(*array.Int8Builder).Append(value.(int8))
-			case arrow.PrimitiveTypes.Int16:
-				builder.Field(index).(*array.Int16Builder).Append(value.(int16))
-			case arrow.PrimitiveTypes.Int32:
-				builder.Field(index).(*array.Int32Builder).Append(value.(int32))
-			case arrow.PrimitiveTypes.Int64:
-				builder.Field(index).(*array.Int64Builder).Append(value.(int64))
-			case arrow.PrimitiveTypes.Uint8:
-				builder.Field(index).(*array.Uint8Builder).Append(value.(uint8))
-			case arrow.PrimitiveTypes.Uint16:
-				builder.Field(index).(*array.Uint16Builder).Append(value.(uint16))
-			case arrow.PrimitiveTypes.Uint32:
-				builder.Field(index).(*array.Uint32Builder).Append(value.(uint32))
-			case arrow.PrimitiveTypes.Uint64:
-				builder.Field(index).(*array.Uint64Builder).Append(value.(uint64))
-			case arrow.PrimitiveTypes.Float32:
-				builder.Field(index).(*array.Float32Builder).Append(value.(float32))
-			case arrow.PrimitiveTypes.Float64:
-				builder.Field(index).(*array.Float64Builder).Append(value.(float64))
-			case arrow.BinaryTypes.String:
-				builder.Field(index).(*array.StringBuilder).Append(value.(string))
-			case arrow.FixedWidthTypes.Boolean:
-				builder.Field(index).(*array.BooleanBuilder).Append(value.(bool))
-			default:
-				return nil, fmt.Errorf("unsupported type: %T", value)
-			}
-		}
-	}
-
-	record := builder.NewRecord()
-	return record, nil
-}
-
-func (p *Parquet) createSchema(metrics []telegraf.Metric) (*arrow.Schema, error) {
-	rawFields := make(map[string]arrow.DataType, 0)
-	for _, metric := range metrics {
-		for _, field := range metric.FieldList() {
-			if _, ok := rawFields[field.Key]; !ok {
-				arrowType, err := goToArrowType(field.Value)
-				if err != nil {
-					return nil, fmt.Errorf("error converting '%s=%s' field to arrow type: %w", field.Key, field.Value, err)
-				}
-				rawFields[field.Key] = arrowType
-			}
-		}
-		for _, tag := range metric.TagList() {
-			if _, ok := rawFields[tag.Key]; !ok {
-				rawFields[tag.Key] = arrow.BinaryTypes.String
-			}
-		}
-	}
-
-	fields := make([]arrow.Field, 0)
-	for key, value := range rawFields {
-		fields = append(fields, arrow.Field{
-			Name: key,
-			Type: value,
-		})
-	}
-
-	if p.TimestampFieldName != "" {
-		fields = append(fields, arrow.Field{
-			Name: p.TimestampFieldName,
-			Type: arrow.PrimitiveTypes.Int64,
-		})
-	}
-
-	return arrow.NewSchema(fields, nil), nil
-}
-
 func (p *Parquet) createWriter(name, filename string, schema *arrow.Schema) (*pqarrow.FileWriter, error) {
 	if _, err := os.Stat(filename); err == nil {
 		now := time.Now()
@@ -297,37 +224,6 @@ func (p *Parquet) createWriter(name, filename string, schema *arrow.Schema) (*pq
 	return writer, nil
 }
 
-func goToArrowType(value interface{}) (arrow.DataType, error) {
-	switch value.(type) {
-	case int8:
-		return arrow.PrimitiveTypes.Int8, nil
-	case int16:
-		return arrow.PrimitiveTypes.Int16, nil
-	case int32:
-		return arrow.PrimitiveTypes.Int32, nil
-	case int64, int:
-		return arrow.PrimitiveTypes.Int64, nil
-	case uint8:
-		return arrow.PrimitiveTypes.Uint8, nil
-	case uint16:
-		return arrow.PrimitiveTypes.Uint16, nil
-	case uint32:
-		return arrow.PrimitiveTypes.Uint32, nil
-	case uint64, uint:
-		return arrow.PrimitiveTypes.Uint64, nil
-	case float32:
-		return arrow.PrimitiveTypes.Float32, nil
-	case float64:
-		return arrow.PrimitiveTypes.Float64, nil
-	case string:
-		return arrow.BinaryTypes.String, nil
-	case bool:
-		return arrow.FixedWidthTypes.Boolean, nil
-	default:
-		return nil, fmt.Errorf("unsupported type: %T", value)
-	}
-}
-
 func init() {
 	outputs.Add("parquet", func() telegraf.Output {
 		return &Parquet{
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/apache/arrow-go/v18/parquet"
 	"github.com/apache/arrow-go/v18/parquet/file"
 	"github.com/stretchr/testify/require"
 
@@ -245,3 +246,94 @@ func TestTimestampDifferentName(t *testing.T) {
 	require.Equal(t, 1, int(metadata.NumRows))
 	require.Equal(t, 2, metadata.Schema.NumColumns())
 }
+
+func TestInitInvalidSchemaEvolution(t *testing.T) {
+	plugin := &Parquet{
+		Directory:       t.TempDir(),
+		SchemaEvolution: "widen",
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid schema_evolution "widen"`)
+}
+
+func TestSchemaPromotion(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("test", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Now()),
+		testutil.MustMetric("test", map[string]string{}, map[string]interface{}{"value": 1.5}, time.Now()),
+	}
+
+	testDir := t.TempDir()
+	plugin := &Parquet{
+		Directory: testDir,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+
+	files, err := os.ReadDir(testDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	reader, err := file.OpenParquetFile(filepath.Join(testDir, files[0].Name()), false)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata := reader.MetaData()
+	require.Equal(t, 2, int(metadata.NumRows))
+	require.Equal(t, parquet.Types.Double, metadata.Schema.Column(0).PhysicalType())
+}
+
+func TestSchemaEvolution(t *testing.T) {
+	first := []telegraf.Metric{
+		testutil.MustMetric("test", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Now()),
+	}
+	second := []telegraf.Metric{
+		testutil.MustMetric("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.5}, time.Now()),
+	}
+
+	tests := []struct {
+		name            string
+		schemaEvolution string
+		columns         []int
+	}{
+		{
+			name:            "none",
+			schemaEvolution: "none",
+			columns:         []int{1},
+		},
+		{
+			name:            "rotate",
+			schemaEvolution: "rotate",
+			columns:         []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDir := t.TempDir()
+			plugin := &Parquet{
+				Directory:       testDir,
+				SchemaEvolution: tt.schemaEvolution,
+				Log:             testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			require.NoError(t, plugin.Write(first))
+			require.NoError(t, plugin.Write(second))
+			require.NoError(t, plugin.Close())
+
+			files, err := os.ReadDir(testDir)
+			require.NoError(t, err)
+			require.Len(t, files, len(tt.columns))
+
+			var columns []int
+			for _, f := range files {
+				reader, err := file.OpenParquetFile(filepath.Join(testDir, f.Name()), false)
+				require.NoError(t, err)
+				columns = append(columns, reader.MetaData().Schema.NumColumns())
+				reader.Close()
+			}
+			require.ElementsMatch(t, tt.columns, columns)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/schema"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -64,6 +65,7 @@ type SQL struct {
 
 	db                       *gosql.DB
 	tables                   map[string]map[string]bool
+	tracker                  *schema.Tracker
 	tableListColumnsTemplate string
 }
 
@@ -134,6 +136,7 @@ func (p *SQL) Connect() error {
 
 	p.db = db
 	p.tables = make(map[string]map[string]bool)
+	p.tracker = &schema.Tracker{}
 
 	return nil
 }
@@ -168,12 +171,21 @@ func sanitizeQuoted(in string) string {
 }
 
 func (p *SQL) deriveDatatype(value interface{}) string {
+	t, ok := schema.TypeOf(value)
+	if !ok {
+		p.Log.Errorf("Unknown datatype: '%T' %v", value, value)
+		return p.Convert.Defaultvalue
+	}
+	return p.columnType(t)
+}
+
+func (p *SQL) columnType(t schema.Type) string {
 	var datatype string
 
-	switch value.(type) {
-	case int64:
+	switch t {
+	case schema.Integer:
 		datatype = p.Convert.Integer
-	case uint64:
+	case schema.Unsigned:
 		if p.Convert.ConversionStyle == "unsigned_suffix" {
 			datatype = fmt.Sprintf("%s %s", p.Convert.Integer, p.Convert.Unsigned)
 		} else if p.Convert.ConversionStyle == "literal" {
@@ -181,41 +193,49 @@ func (p *SQL) deriveDatatype(value interface{}) string {
 		} else {
 			p.Log.Errorf("unknown conversion style: %s", p.Convert.ConversionStyle)
 		}
-	case float64:
+	case schema.Float:
 		datatype = p.Convert.Real
-	case string:
+	case schema.String:
 		datatype = p.Convert.Text
-	case bool:
+	case schema.Boolean:
 		datatype = p.Convert.Bool
 	default:
 		datatype = p.Convert.Defaultvalue
-		p.Log.Errorf("Unknown datatype: '%T' %v", value, value)
 	}
 	return datatype
 }
 
-func (p *SQL) generateCreateTable(metric telegraf.Metric) string {
-	columns := make([]string, 0, len(metric.TagList())+len(metric.FieldList())+1)
-	tagColumnNames := make([]string, 0, len(metric.TagList()))
+// fieldDatatype returns the column type of the field as tracked in the schema
+// falling back to the type of the given value
+func (p *SQL) fieldDatatype(s *schema.Schema, name string, value interface{}) string {
+	if c, found := s.Column(name); found && !c.Tag {
+		return p.columnType(c.Type)
+	}
+	return p.deriveDatatype(value)
+}
+
+func (p *SQL) generateCreateTable(s *schema.Schema) string {
+	tags := s.Tags()
+	fields := s.Fields()
+	columns := make([]string, 0, len(tags)+len(fields)+1)
+	tagColumnNames := make([]string, 0, len(tags))
 
 	if p.TimestampColumn != "" {
 		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(p.TimestampColumn), p.Convert.Timestamp))
 	}
 
-	for _, tag := range metric.TagList() {
-		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(tag.Key), p.Convert.Text))
-		tagColumnNames = append(tagColumnNames, quoteIdent(tag.Key))
+	for _, tag := range tags {
+		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(tag.Name), p.Convert.Text))
+		tagColumnNames = append(tagColumnNames, quoteIdent(tag.Name))
 	}
 
-	var datatype string
-	for _, field := range metric.FieldList() {
-		datatype = p.deriveDatatype(field.Value)
-		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(field.Key), datatype))
+	for _, field := range fields {
+		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(field.Name), p.columnType(field.Type)))
 	}
 
 	query := p.TableTemplate
-	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(metric.Name()))
-	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(metric.Name()))
+	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(s.Name))
+	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(s.Name))
 	query = strings.ReplaceAll(query, "{COLUMNS}", strings.Join(columns, ","))
 	query = strings.ReplaceAll(query, "{TAG_COLUMN_NAMES}", strings.Join(tagColumnNames, ","))
 	query = strings.ReplaceAll(query, "{TIMESTAMP_COLUMN_NAME}", quoteIdent(p.TimestampColumn))
@@ -255,9 +275,9 @@ func (p *SQL) generateInsert(tablename string, columns []string) string {
 		strings.Join(placeholders, ","))
 }
 
-func (p *SQL) createTable(metric telegraf.Metric) error {
-	tablename := metric.Name()
-	stmt := p.generateCreateTable(metric)
+func (p *SQL) createTable(s *schema.Schema) error {
+	tablename := s.Name
+	stmt := p.generateCreateTable(s)
 	if _, err := p.db.Exec(stmt); err != nil {
 		return fmt.Errorf("creating table failed: %w", err)
 	}
@@ -336,38 +356,54 @@ func (p *SQL) updateTableCache(tablename string) error {
 func (p *SQL) Write(metrics []telegraf.Metric) error {
 	var err error
 
+	// Track the schema of all metrics in the batch to derive the column types
+	// of new tables and columns from all metrics instead of the first one
+	groupedMetrics := make(map[string][]telegraf.Metric)
+	for _, metric := range metrics {
+		groupedMetrics[metric.Name()] = append(groupedMetrics[metric.Name()], metric)
+	}
+	schemas := make(map[string]*schema.Schema, len(groupedMetrics))
+	for name, group := range groupedMetrics {
+		schemas[name], _ = p.tracker.Update(name, group)
+	}
+
 	for _, metric := range metrics {
 		tablename := metric.Name()
+		s := schemas[tablename]
 
 		// create table if needed
 		if _, found := p.tables[tablename]; !found && !p.tableExists(tablename) {
-			if err := p.createTable(metric); err != nil {
+			if err := p.createTable(s); err != nil {
 				return err
 			}
 		}
 
 		var columns []string
 		var values []interface{}
+		var datatypes []string
 
 		if p.TimestampColumn != "" {
 			columns = append(columns, p.TimestampColumn)
 			values = append(values, metric.Time())
+			datatypes = append(datatypes, p.Convert.Timestamp)
 		}
 
 		for column, value := range metric.Tags() {
 			columns = append(columns, column)
 			values = append(values, value)
+			datatypes = append(datatypes, p.Convert.Text)
 		}
 
 		for column, value := range metric.Fields() {
 			columns = append(columns, column)
 			values = append(values, value)
+			datatypes = append(datatypes, p.fieldDatatype(s, column, value))
 		}
 
 		// Modifying the table schema is opt-in
 		if p.TableUpdateTemplate != "" {
 			for i := range len(columns) {
-				if err := p.createColumn(tablename, columns[i], p.deriveDatatype(values[i])); err != nil {
+				if err := p.createColumn(tablename, columns[i], datatypes[i]); err != nil {
 					return err
 				}
 			}
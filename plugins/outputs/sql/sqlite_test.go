@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -179,3 +180,53 @@ func TestSqliteUpdateScheme(t *testing.T) {
 		sql,
 	)
 }
+
+func TestSqliteSchemaFromBatch(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+	defer os.Remove(dbfile)
+
+	p := &SQL{
+		Driver:            "sqlite",
+		DataSourceName:    config.NewSecret([]byte(dbfile)),
+		Convert:           defaultConvert,
+		TimestampColumn:   "timestamp",
+		ConnectionMaxIdle: 2,
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	// The first metric alone would create an integer column and miss the
+	// additional tag and field of the second metric
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"metric_mixed",
+			map[string]string{"tag_one": "tag1"},
+			map[string]interface{}{"value": int64(1)},
+			ts,
+		),
+		testutil.MustMetric(
+			"metric_mixed",
+			map[string]string{"tag_one": "tag1", "tag_two": "tag2"},
+			map[string]interface{}{"value": 1.5, "count": int64(2)},
+			ts,
+		),
+	}
+	require.NoError(t, p.Write(metrics))
+
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var sql string
+	require.NoError(t, db.QueryRow("select sql from sqlite_master").Scan(&sql))
+	require.Equal(t,
+		`CREATE TABLE "metric_mixed"("timestamp" TIMESTAMP,"tag_one" TEXT,"tag_two" TEXT,"value" DOUBLE,"count" INT)`,
+		sql,
+	)
+
+	var count int
+	require.NoError(t, db.QueryRow(`select count(*) from "metric_mixed"`).Scan(&count))
+	require.Equal(t, 2, count)
+}
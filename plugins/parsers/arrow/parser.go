@@ -0,0 +1,218 @@
+package arrow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	common_arrow "github.com/influxdata/telegraf/plugins/common/arrow"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// Parser decodes metrics from Apache Arrow IPC streams
+type Parser struct {
+	MeasurementColumn string   `toml:"arrow_measurement_column"`
+	TagColumns        []string `toml:"arrow_tag_columns"`
+	TimestampColumn   string   `toml:"arrow_timestamp_column"`
+	TimestampFormat   string   `toml:"arrow_timestamp_format"`
+	TimestampTimezone string   `toml:"arrow_timestamp_timezone"`
+
+	defaultTags map[string]string
+	location    *time.Location
+	metricName  string
+}
+
+func (p *Parser) Init() error {
+	if p.TimestampFormat == "" {
+		p.TimestampFormat = "unix"
+	}
+	if p.TimestampTimezone == "" {
+		p.location = time.UTC
+	} else {
+		loc, err := time.LoadLocation(p.TimestampTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid location %s: %w", p.TimestampTimezone, err)
+		}
+		p.location = loc
+	}
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	reader, err := ipc.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create arrow reader: %w", err)
+	}
+	defer reader.Release()
+
+	// Determine the metric property stored in each column
+	schema := reader.Schema()
+	columnTypes := make([]string, 0, schema.NumFields())
+	for _, field := range schema.Fields() {
+		columnTypes = append(columnTypes, p.columnType(field))
+	}
+
+	now := time.Now()
+	var metrics []telegraf.Metric
+	for reader.Next() {
+		record := reader.Record()
+		for row := 0; row < int(record.NumRows()); row++ {
+			m := metric.New(p.metricName, p.defaultTags, nil, now)
+			for i, col := range record.Columns() {
+				name := schema.Field(i).Name
+				if col.IsNull(row) {
+					continue
+				}
+				value, err := columnValue(col, row)
+				if err != nil {
+					return nil, fmt.Errorf("column %q: %w", name, err)
+				}
+
+				switch columnTypes[i] {
+				case "measurement":
+					valStr, err := internal.ToString(value)
+					if err != nil {
+						return nil, fmt.Errorf("could not convert value to string: %w", err)
+					}
+					m.SetName(valStr)
+				case "tag":
+					valStr, err := internal.ToString(value)
+					if err != nil {
+						return nil, fmt.Errorf("could not convert value to string: %w", err)
+					}
+					m.AddTag(name, valStr)
+				case "timestamp":
+					timestamp, err := p.parseTimestamp(value)
+					if err != nil {
+						return nil, err
+					}
+					m.SetTime(timestamp)
+				default:
+					if t, ok := value.(time.Time); ok {
+						value = t.UnixNano()
+					}
+					m.AddField(name, value)
+				}
+			}
+			metrics = append(metrics, m)
+		}
+	}
+	if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading record failed: %w", err)
+	}
+
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, nil
+	}
+	if len(metrics) > 1 {
+		return nil, errors.New("line contains multiple metrics")
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.defaultTags = tags
+}
+
+// columnType returns the metric property stored in the column. Configured
+// columns take precedence over the column-type metadata written by the arrow
+// serializer.
+func (p *Parser) columnType(field arrow.Field) string {
+	switch {
+	case p.MeasurementColumn != "" && field.Name == p.MeasurementColumn:
+		return "measurement"
+	case p.TimestampColumn != "" && field.Name == p.TimestampColumn:
+		return "timestamp"
+	case slices.Contains(p.TagColumns, field.Name):
+		return "tag"
+	}
+
+	if idx := field.Metadata.FindKey(common_arrow.ColumnTypeKey); idx >= 0 {
+		return field.Metadata.Values()[idx]
+	}
+	return "field"
+}
+
+func (p *Parser) parseTimestamp(value interface{}) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+
+	valStr, err := internal.ToString(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not convert value to string: %w", err)
+	}
+	timestamp, err := internal.ParseTimestamp(p.TimestampFormat, valStr, p.location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse '%s' to '%s'", valStr, p.TimestampFormat)
+	}
+	return timestamp, nil
+}
+
+// columnValue returns the value of the given row, timestamps are returned as
+// time and dictionary-encoded values are resolved
+func columnValue(col arrow.Array, row int) (interface{}, error) {
+	switch arr := col.(type) {
+	case *array.Int8:
+		return arr.Value(row), nil
+	case *array.Int16:
+		return arr.Value(row), nil
+	case *array.Int32:
+		return arr.Value(row), nil
+	case *array.Int64:
+		return arr.Value(row), nil
+	case *array.Uint8:
+		return arr.Value(row), nil
+	case *array.Uint16:
+		return arr.Value(row), nil
+	case *array.Uint32:
+		return arr.Value(row), nil
+	case *array.Uint64:
+		return arr.Value(row), nil
+	case *array.Float32:
+		return arr.Value(row), nil
+	case *array.Float64:
+		return arr.Value(row), nil
+	case *array.String:
+		return arr.Value(row), nil
+	case *array.LargeString:
+		return arr.Value(row), nil
+	case *array.Boolean:
+		return arr.Value(row), nil
+	case *array.Timestamp:
+		unit := arr.DataType().(*arrow.TimestampType).Unit
+		return arr.Value(row).ToTime(unit), nil
+	case *array.Dictionary:
+		return columnValue(arr.Dictionary(), arr.GetValueIndex(row))
+	}
+	return nil, fmt.Errorf("unsupported type %s", col.DataType())
+}
+
+func init() {
+	parsers.Add("arrow",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{metricName: defaultMetricName}
+		},
+	)
+}
@@ -0,0 +1,102 @@
+package arrow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// stream creates an IPC stream with columns as written by other tools,
+// i.e. without column-type metadata
+func stream(t *testing.T) []byte {
+	t.Helper()
+
+	dictType := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "site", Type: dictType, Nullable: true},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "ts", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"temp", "temp"}, nil)
+	site := builder.Field(1).(*array.BinaryDictionaryBuilder)
+	require.NoError(t, site.AppendString("berlin"))
+	site.AppendNull()
+	builder.Field(2).(*array.Float64Builder).AppendValues([]float64{21.5, 0}, []bool{true, false})
+	builder.Field(3).(*array.Int64Builder).AppendValues([]int64{1700000000, 1700000001}, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	require.NoError(t, writer.Write(record))
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	parser := &Parser{
+		MeasurementColumn: "name",
+		TagColumns:        []string{"site"},
+		TimestampColumn:   "ts",
+	}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "test"})
+
+	actual, err := parser.Parse(stream(t))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"temp",
+			map[string]string{"site": "berlin", "source": "test"},
+			map[string]interface{}{"value": 21.5},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"temp",
+			map[string]string{"source": "test"},
+			map[string]interface{}{},
+			time.Unix(1700000001, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseWithoutConfig(t *testing.T) {
+	parser := &Parser{metricName: "arrow"}
+	require.NoError(t, parser.Init())
+
+	actual, err := parser.Parse(stream(t))
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+
+	expected := metric.New(
+		"arrow",
+		map[string]string{},
+		map[string]interface{}{"name": "temp", "site": "berlin", "value": 21.5, "ts": int64(1700000000)},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, actual[:1], testutil.IgnoreTime())
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse([]byte("not arrow"))
+	require.ErrorContains(t, err, "unable to create arrow reader")
+}
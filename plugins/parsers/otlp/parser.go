@@ -0,0 +1,169 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"github.com/influxdata/influxdb-observability/otel2influx"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	common_otel "github.com/influxdata/telegraf/plugins/common/opentelemetry"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+var metricsSchemata = map[string]common.MetricsSchema{
+	"prometheus-v1": common.MetricsSchemaTelegrafPrometheusV1,
+	"prometheus-v2": common.MetricsSchemaTelegrafPrometheusV2,
+}
+
+// Parser decodes OTLP metrics export requests
+type Parser struct {
+	Format        string            `toml:"otlp_format"`
+	MetricsSchema string            `toml:"otlp_metrics_schema"`
+	DefaultTags   map[string]string `toml:"-"`
+	Log           telegraf.Logger   `toml:"-"`
+
+	exporter *otel2influx.OtelMetricsToLineProtocol
+	writer   *collector
+	sync.Mutex
+}
+
+func (p *Parser) Init() error {
+	switch p.Format {
+	case "":
+		p.Format = "protobuf"
+	case "protobuf", "json":
+	default:
+		return fmt.Errorf("invalid otlp_format %q", p.Format)
+	}
+
+	if p.MetricsSchema == "" {
+		p.MetricsSchema = "prometheus-v1"
+	}
+	schema, found := metricsSchemata[p.MetricsSchema]
+	if !found {
+		return fmt.Errorf("invalid otlp_metrics_schema %q", p.MetricsSchema)
+	}
+
+	p.writer = &collector{}
+	cfg := otel2influx.DefaultOtelMetricsToLineProtocolConfig()
+	cfg.Logger = &common_otel.Logger{Logger: p.Log}
+	cfg.Writer = p.writer
+	cfg.Schema = schema
+	exporter, err := otel2influx.NewOtelMetricsToLineProtocol(cfg)
+	if err != nil {
+		return fmt.Errorf("creating converter failed: %w", err)
+	}
+	p.exporter = exporter
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	req := pmetricotlp.NewExportRequest()
+	switch p.Format {
+	case "protobuf":
+		if err := req.UnmarshalProto(buf); err != nil {
+			return nil, fmt.Errorf("unmarshalling protobuf request failed: %w", err)
+		}
+	case "json":
+		if err := req.UnmarshalJSON(buf); err != nil {
+			return nil, fmt.Errorf("unmarshalling JSON request failed: %w", err)
+		}
+	}
+
+	// The converter writes to the shared collector so serialize the calls
+	p.Lock()
+	defer p.Unlock()
+
+	p.writer.metrics = nil
+	if err := p.exporter.WriteMetrics(context.Background(), req.Metrics()); err != nil {
+		return nil, fmt.Errorf("converting metrics failed: %w", err)
+	}
+	metrics := p.writer.metrics
+	p.writer.metrics = nil
+
+	for _, m := range metrics {
+		for k, v := range p.DefaultTags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, errors.New("no metrics in line")
+	}
+
+	if len(metrics) > 1 {
+		return nil, errors.New("more than one metric in line")
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// collector receives the converted metrics
+type collector struct {
+	metrics []telegraf.Metric
+}
+
+func (c *collector) NewBatch() otel2influx.InfluxWriterBatch {
+	return c
+}
+
+func (c *collector) EnqueuePoint(
+	_ context.Context,
+	measurement string,
+	tags map[string]string,
+	fields map[string]interface{},
+	ts time.Time,
+	vType common.InfluxMetricValueType,
+) error {
+	var mType telegraf.ValueType
+	switch vType {
+	case common.InfluxMetricValueTypeUntyped:
+		mType = telegraf.Untyped
+	case common.InfluxMetricValueTypeGauge:
+		mType = telegraf.Gauge
+	case common.InfluxMetricValueTypeSum:
+		mType = telegraf.Counter
+	case common.InfluxMetricValueTypeHistogram:
+		mType = telegraf.Histogram
+	case common.InfluxMetricValueTypeSummary:
+		mType = telegraf.Summary
+	default:
+		return fmt.Errorf("unrecognized InfluxMetricValueType %q", vType)
+	}
+	c.metrics = append(c.metrics, metric.New(measurement, tags, fields, ts, mType))
+	return nil
+}
+
+func (*collector) WriteBatch(context.Context) error {
+	return nil
+}
+
+func init() {
+	parsers.Add("otlp",
+		func(string) telegraf.Parser {
+			return &Parser{}
+		},
+	)
+}
@@ -0,0 +1,106 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func request() pmetricotlp.ExportRequest {
+	data := pmetric.NewMetrics()
+	rm := data.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("host.name", "potato")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("My Library Name")
+	m := sm.Metrics().AppendEmpty()
+	m.SetName("cpu_temp")
+	dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.Attributes().PutStr("foo", "bar")
+	dp.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+	dp.SetDoubleValue(87.332)
+	return pmetricotlp.NewExportRequestFromMetrics(data)
+}
+
+func expected() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New(
+			"cpu_temp",
+			map[string]string{
+				"foo":               "bar",
+				"otel.library.name": "My Library Name",
+				"host.name":         "potato",
+				"region":            "eu",
+			},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848686000000000),
+			telegraf.Gauge,
+		),
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   *Parser
+		expected string
+	}{
+		{
+			name:     "invalid format",
+			parser:   &Parser{Format: "xml"},
+			expected: `invalid otlp_format "xml"`,
+		},
+		{
+			name:     "invalid schema",
+			parser:   &Parser{MetricsSchema: "prometheus-v3"},
+			expected: `invalid otlp_metrics_schema "prometheus-v3"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.parser.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParseProtobuf(t *testing.T) {
+	buf, err := request().MarshalProto()
+	require.NoError(t, err)
+
+	parser := &Parser{Log: testutil.Logger{}}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"region": "eu"})
+
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected(), actual, testutil.SortMetrics())
+}
+
+func TestParseJSON(t *testing.T) {
+	buf, err := request().MarshalJSON()
+	require.NoError(t, err)
+
+	parser := &Parser{Format: "json", Log: testutil.Logger{}}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"region": "eu"})
+
+	actual, err := parser.ParseLine(string(buf))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected(), []telegraf.Metric{actual})
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := &Parser{Format: "json", Log: testutil.Logger{}}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse([]byte("{"))
+	require.ErrorContains(t, err, "unmarshalling JSON request failed")
+}
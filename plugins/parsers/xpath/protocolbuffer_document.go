@@ -1,25 +1,20 @@
 package xpath
 
 import (
-	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"reflect"
-	"sort"
 	"strconv"
-	"strings"
 
 	path "github.com/antchfx/xpath"
-	"github.com/bufbuild/protocompile"
 	"github.com/srebhan/protobufquery"
 	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/reflect/protoreflect"
-	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/dynamicpb"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/protobuf"
 )
 
 type protobufDocument struct {
@@ -43,59 +38,23 @@ func (d *protobufDocument) Init() error {
 	}
 
 	// Load the file descriptors from the given protocol-buffer definition
-	ctx := context.Background()
-	resolver := &protocompile.SourceResolver{ImportPaths: d.ImportPaths}
-	compiler := &protocompile.Compiler{
-		Resolver: protocompile.WithStandardImports(resolver),
-	}
-	files, err := compiler.Compile(ctx, d.MessageFiles...)
+	registry, err := protobuf.Load(d.MessageFiles, d.ImportPaths)
 	if err != nil {
-		return fmt.Errorf("parsing protocol-buffer definition failed: %w", err)
-	}
-	if len(files) < 1 {
-		return errors.New("files do not contain a file descriptor")
-	}
-
-	// Register all definitions in the file in the global registry
-	var registry protoregistry.Files
-	for _, f := range files {
-		if err := registry.RegisterFile(f); err != nil {
-			return fmt.Errorf("adding file %q to registry failed: %w", f.Path(), err)
-		}
+		return err
 	}
 
 	d.unmarshaller = proto.UnmarshalOptions{
 		RecursionLimit: protowire.DefaultRecursionLimit,
-		Resolver:       dynamicpb.NewTypes(&registry),
+		Resolver:       dynamicpb.NewTypes(registry),
 	}
 
 	// Lookup given type in the loaded file descriptors
-	msgFullName := protoreflect.FullName(d.MessageType)
-	descriptor, err := registry.FindDescriptorByName(msgFullName)
+	msgDesc, err := protobuf.FindMessage(registry, d.MessageType, d.Log)
 	if err != nil {
-		d.Log.Infof("Could not find %q... Known messages:", msgFullName)
-
-		var known []string
-		registry.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
-			name := strings.TrimSpace(string(fd.FullName()))
-			if name != "" {
-				known = append(known, name)
-			}
-			return true
-		})
-		sort.Strings(known)
-		for _, name := range known {
-			d.Log.Infof("  %s", name)
-		}
 		return err
 	}
 
 	// Get a prototypical message for later use
-	msgDesc, ok := descriptor.(protoreflect.MessageDescriptor)
-	if !ok {
-		return fmt.Errorf("%q is not a message descriptor (%T)", msgFullName, descriptor)
-	}
-
 	d.msg = dynamicpb.NewMessage(msgDesc)
 	if d.msg == nil {
 		return fmt.Errorf("creating message template for %q failed", msgDesc.FullName())
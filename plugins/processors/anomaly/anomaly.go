@@ -0,0 +1,205 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package anomaly
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Anomaly struct {
+	Fields       []string        `toml:"fields"`
+	Threshold    float64         `toml:"threshold"`
+	Warmup       int             `toml:"warmup"`
+	Alpha        float64         `toml:"alpha"`
+	Beta         float64         `toml:"beta"`
+	Gamma        float64         `toml:"gamma"`
+	SeasonLength int             `toml:"season_length"`
+	Action       string          `toml:"action"`
+	Measurement  string          `toml:"measurement"`
+	Log          telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+
+	// Baselines of the fields per series
+	baselines map[uint64]map[string]*baseline
+	sync.Mutex
+}
+
+func (*Anomaly) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Anomaly) Init() error {
+	if a.Threshold <= 0 {
+		return fmt.Errorf("invalid threshold %v, must be positive", a.Threshold)
+	}
+	for name, factor := range map[string]float64{"alpha": a.Alpha, "beta": a.Beta, "gamma": a.Gamma} {
+		if factor <= 0 || factor > 1 {
+			return fmt.Errorf("invalid %s %v, must be between zero and one", name, factor)
+		}
+	}
+	if a.SeasonLength < 0 {
+		return fmt.Errorf("invalid season length %d", a.SeasonLength)
+	}
+	switch a.Action {
+	case "annotate", "emit":
+	default:
+		return fmt.Errorf("invalid action %q", a.Action)
+	}
+
+	f, err := filter.Compile(a.Fields)
+	if err != nil {
+		return err
+	}
+	a.fieldFilter = f
+	if a.baselines == nil {
+		a.baselines = make(map[uint64]map[string]*baseline)
+	}
+
+	return nil
+}
+
+func (a *Anomaly) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	a.Lock()
+	defer a.Unlock()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		out = append(out, m)
+
+		id := m.HashID()
+		series, found := a.baselines[id]
+		if !found {
+			series = make(map[string]*baseline)
+			a.baselines[id] = series
+		}
+
+		var anomalies []string
+		for _, field := range m.FieldList() {
+			if a.fieldFilter != nil && !a.fieldFilter.Match(field.Key) {
+				continue
+			}
+			value, ok := toFloat(field.Value)
+			if !ok {
+				continue
+			}
+
+			b, found := series[field.Key]
+			if !found {
+				b = newBaseline(a.SeasonLength)
+				series[field.Key] = b
+			}
+
+			expected, stddev := b.forecast()
+			ready := b.ready(a.Warmup)
+			b.update(value, a.Alpha, a.Beta, a.Gamma)
+			if !ready || stddev == 0 {
+				continue
+			}
+
+			zscore := (value - expected) / stddev
+			if math.Abs(zscore) <= a.Threshold {
+				continue
+			}
+
+			switch a.Action {
+			case "annotate":
+				m.AddField(field.Key+"_zscore", zscore)
+				anomalies = append(anomalies, field.Key)
+			case "emit":
+				tags := m.Tags()
+				tags["field"] = field.Key
+				fields := map[string]interface{}{
+					"value":    value,
+					"zscore":   zscore,
+					"expected": expected,
+					"stddev":   stddev,
+				}
+				out = append(out, metric.New(a.Measurement, tags, fields, m.Time()))
+			}
+		}
+
+		if len(anomalies) > 0 {
+			sort.Strings(anomalies)
+			m.AddTag("anomaly", strings.Join(anomalies, ","))
+		}
+	}
+	return out
+}
+
+func (a *Anomaly) GetState() interface{} {
+	a.Lock()
+	defer a.Unlock()
+
+	// Return a copy as the baselines are modified when applying metrics
+	state := make(map[uint64]map[string]*baseline, len(a.baselines))
+	for id, series := range a.baselines {
+		s := make(map[string]*baseline, len(series))
+		for key, b := range series {
+			s[key] = b.clone()
+		}
+		state[id] = s
+	}
+	return state
+}
+
+func (a *Anomaly) SetState(state interface{}) error {
+	baselines, ok := state.(map[uint64]map[string]*baseline)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	// Discard baselines of a different seasonality
+	for _, series := range baselines {
+		for key, b := range series {
+			if len(b.Seasonal) != a.SeasonLength {
+				a.Log.Debugf("Discarding baseline of field %q with different season length", key)
+				delete(series, key)
+			}
+		}
+	}
+	a.baselines = baselines
+
+	return nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func init() {
+	processors.Add("anomaly", func() telegraf.Processor {
+		return &Anomaly{
+			Threshold:   3.0,
+			Warmup:      10,
+			Alpha:       0.1,
+			Beta:        0.01,
+			Gamma:       0.1,
+			Action:      "annotate",
+			Measurement: "anomaly",
+		}
+	})
+}
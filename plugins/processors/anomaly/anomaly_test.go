@@ -0,0 +1,169 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newAnomaly() *Anomaly {
+	return &Anomaly{
+		Threshold:   3.0,
+		Warmup:      10,
+		Alpha:       0.1,
+		Beta:        0.01,
+		Gamma:       0.1,
+		Action:      "annotate",
+		Measurement: "anomaly",
+		Log:         testutil.Logger{},
+	}
+}
+
+// noisy returns a metric with a value alternating around the given value
+func noisy(value float64, i int) telegraf.Metric {
+	if i%2 == 0 {
+		value++
+	} else {
+		value--
+	}
+	return metric.New(
+		"sensor",
+		map[string]string{"id": "1"},
+		map[string]interface{}{"temperature": value, "status": "ok"},
+		time.Unix(int64(i), 0),
+	)
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := newAnomaly()
+	plugin.Alpha = 1.5
+	require.ErrorContains(t, plugin.Init(), "invalid alpha 1.5")
+
+	plugin = newAnomaly()
+	plugin.Action = "alert"
+	require.ErrorContains(t, plugin.Init(), `invalid action "alert"`)
+}
+
+func TestAnnotate(t *testing.T) {
+	plugin := newAnomaly()
+	require.NoError(t, plugin.Init())
+
+	// No anomalies are reported for regular values or during warmup
+	for i := range 50 {
+		out := plugin.Apply(noisy(20, i))
+		require.Len(t, out, 1)
+		require.False(t, out[0].HasTag("anomaly"))
+	}
+
+	out := plugin.Apply(metric.New(
+		"sensor",
+		map[string]string{"id": "1"},
+		map[string]interface{}{"temperature": 30.0, "status": "ok"},
+		time.Unix(50, 0),
+	))
+	require.Len(t, out, 1)
+	tag, found := out[0].GetTag("anomaly")
+	require.True(t, found)
+	require.Equal(t, "temperature", tag)
+	zscore, found := out[0].GetField("temperature_zscore")
+	require.True(t, found)
+	require.Greater(t, zscore, 3.0)
+}
+
+func TestEmit(t *testing.T) {
+	plugin := newAnomaly()
+	plugin.Action = "emit"
+	require.NoError(t, plugin.Init())
+
+	for i := range 50 {
+		require.Len(t, plugin.Apply(noisy(20, i)), 1)
+	}
+
+	input := metric.New(
+		"sensor",
+		map[string]string{"id": "1"},
+		map[string]interface{}{"temperature": 10.0},
+		time.Unix(50, 0),
+	)
+	out := plugin.Apply(input.Copy())
+	require.Len(t, out, 2)
+	testutil.RequireMetricEqual(t, input, out[0])
+
+	require.Equal(t, "anomaly", out[1].Name())
+	require.Equal(t, map[string]string{"id": "1", "field": "temperature"}, out[1].Tags())
+	require.Equal(t, input.Time(), out[1].Time())
+	value, found := out[1].GetField("value")
+	require.True(t, found)
+	require.InDelta(t, 10.0, value, 1e-9)
+	zscore, found := out[1].GetField("zscore")
+	require.True(t, found)
+	require.Less(t, zscore, -3.0)
+}
+
+func TestSeasonal(t *testing.T) {
+	plugin := newAnomaly()
+	plugin.SeasonLength = 24
+	plugin.Threshold = 4
+	require.NoError(t, plugin.Init())
+
+	// A daily pattern must not be reported with a seasonal baseline
+	daily := func(i int) float64 {
+		return 20 + 10*math.Sin(2*math.Pi*float64(i%24)/24)
+	}
+	rnd := rand.New(rand.NewSource(42))
+	for i := range 24 * 10 {
+		out := plugin.Apply(noisy(daily(i)+rnd.Float64()-0.5, i))
+		require.Len(t, out, 1)
+		require.Falsef(t, out[0].HasTag("anomaly"), "anomaly reported for value %d", i)
+	}
+
+	// A value being normal at another time of the day is an anomaly
+	i := 24 * 10
+	out := plugin.Apply(noisy(daily(i+6), i))
+	require.True(t, out[0].HasTag("anomaly"))
+}
+
+func TestState(t *testing.T) {
+	plugin := newAnomaly()
+	require.NoError(t, plugin.Init())
+	for i := range 50 {
+		plugin.Apply(noisy(20, i))
+	}
+
+	// Simulate the persister storing and restoring the state
+	buf, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	var state map[uint64]map[string]*baseline
+	require.NoError(t, json.Unmarshal(buf, &state))
+
+	restored := newAnomaly()
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state))
+	require.Equal(t, plugin.baselines, restored.baselines)
+
+	// The restored baseline must detect anomalies without warmup
+	out := restored.Apply(metric.New(
+		"sensor",
+		map[string]string{"id": "1"},
+		map[string]interface{}{"temperature": 30.0},
+		time.Unix(50, 0),
+	))
+	require.True(t, out[0].HasTag("anomaly"))
+
+	// Baselines of a different seasonality are discarded
+	seasonal := newAnomaly()
+	seasonal.SeasonLength = 24
+	require.NoError(t, seasonal.Init())
+	require.NoError(t, seasonal.SetState(state))
+	for _, series := range seasonal.baselines {
+		require.Empty(t, series)
+	}
+}
@@ -0,0 +1,99 @@
+package anomaly
+
+import (
+	"math"
+	"slices"
+)
+
+// baseline models the expected value of a field. Without seasonality the
+// baseline is the exponentially weighted moving average and variance of the
+// values. Seasonal baselines use the additive Holt-Winters method with the
+// variance of the forecast errors. The fields are exported for persisting the
+// state.
+type baseline struct {
+	Count    int     `json:"count"`
+	Mean     float64 `json:"mean,omitempty"`
+	Variance float64 `json:"variance"`
+
+	Level    float64   `json:"level,omitempty"`
+	Trend    float64   `json:"trend,omitempty"`
+	Seasonal []float64 `json:"seasonal,omitempty"`
+	Index    int       `json:"index,omitempty"`
+}
+
+func newBaseline(seasonLength int) *baseline {
+	b := &baseline{}
+	if seasonLength > 0 {
+		b.Seasonal = make([]float64, seasonLength)
+	}
+	return b
+}
+
+func (b *baseline) clone() *baseline {
+	c := *b
+	c.Seasonal = slices.Clone(b.Seasonal)
+	return &c
+}
+
+// ready returns true if the baseline received the given number of values
+// after initializing the seasonal components with the first season
+func (b *baseline) ready(warmup int) bool {
+	return b.Count >= len(b.Seasonal)+warmup
+}
+
+// forecast returns the expected next value and its standard deviation
+func (b *baseline) forecast() (expected, stddev float64) {
+	if len(b.Seasonal) == 0 {
+		return b.Mean, math.Sqrt(b.Variance)
+	}
+	return b.Level + b.Trend + b.Seasonal[b.Index], math.Sqrt(b.Variance)
+}
+
+// update adds the value to the baseline using the smoothing factors of the
+// level, trend and seasonal components
+func (b *baseline) update(value, alpha, beta, gamma float64) {
+	defer func() { b.Count++ }()
+
+	if len(b.Seasonal) == 0 {
+		if b.Count == 0 {
+			b.Mean = value
+			return
+		}
+		w := weight(alpha, b.Count)
+		diff := value - b.Mean
+		b.Mean += w * diff
+		b.Variance = (1 - w) * (b.Variance + w*diff*diff)
+		return
+	}
+
+	i := b.Index
+	b.Index = (i + 1) % len(b.Seasonal)
+
+	// Initialize the level and seasonal components using the first season
+	if b.Count < len(b.Seasonal) {
+		b.Seasonal[i] = value
+		b.Level += (value - b.Level) / float64(b.Count+1)
+		if b.Count == len(b.Seasonal)-1 {
+			for j := range b.Seasonal {
+				b.Seasonal[j] -= b.Level
+			}
+		}
+		return
+	}
+
+	w := weight(alpha, b.Count-len(b.Seasonal)+1)
+	residual := value - (b.Level + b.Trend + b.Seasonal[i])
+	b.Variance = (1 - w) * (b.Variance + w*residual*residual)
+
+	level := alpha*(value-b.Seasonal[i]) + (1-alpha)*(b.Level+b.Trend)
+	b.Trend = beta*(level-b.Level) + (1-beta)*b.Trend
+	b.Level = level
+	b.Seasonal[i] = gamma*(value-level) + (1-gamma)*b.Seasonal[i]
+}
+
+// weight returns the smoothing factor for the variance given the number of
+// previous values. Using the cumulative average for the first values avoids
+// underestimating the variance while it builds up.
+func weight(alpha float64, n int) float64 {
+	return max(alpha, 1/float64(n+1))
+}
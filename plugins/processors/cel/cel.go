@@ -0,0 +1,197 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cel
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CEL struct {
+	Drop   string            `toml:"drop"`
+	Name   string            `toml:"name"`
+	Tags   map[string]string `toml:"tags"`
+	Fields map[string]string `toml:"fields"`
+	Log    telegraf.Logger   `toml:"-"`
+
+	drop   cel.Program
+	name   cel.Program
+	tags   map[string]cel.Program
+	fields map[string]cel.Program
+}
+
+func (*CEL) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CEL) Init() error {
+	if c.Drop == "" && c.Name == "" && len(c.Tags) == 0 && len(c.Fields) == 0 {
+		return errors.New("no expression given")
+	}
+
+	env, err := models.NewCELEnvironment()
+	if err != nil {
+		return err
+	}
+
+	if c.Drop != "" {
+		if c.drop, err = compile(env, c.Drop, cel.BoolType); err != nil {
+			return fmt.Errorf("compiling drop expression failed: %w", err)
+		}
+	}
+	if c.Name != "" {
+		if c.name, err = compile(env, c.Name, cel.StringType); err != nil {
+			return fmt.Errorf("compiling name expression failed: %w", err)
+		}
+	}
+
+	c.tags = make(map[string]cel.Program, len(c.Tags))
+	for key, expression := range c.Tags {
+		if c.tags[key], err = compile(env, expression, cel.StringType, cel.NullType); err != nil {
+			return fmt.Errorf("compiling expression for tag %q failed: %w", key, err)
+		}
+	}
+
+	c.fields = make(map[string]cel.Program, len(c.Fields))
+	for key, expression := range c.Fields {
+		if c.fields[key], err = compile(env, expression); err != nil {
+			return fmt.Errorf("compiling expression for field %q failed: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *CEL) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		keep, err := c.transform(m)
+		if err != nil {
+			c.Log.Errorf("Transforming metric %q failed: %v", m.Name(), err)
+		}
+		if !keep {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// transform applies the expressions to the given metric and returns false if
+// the metric should be dropped. All expressions are evaluated on the incoming
+// metric before modifying it, so the metric is left untouched on errors.
+func (c *CEL) transform(m telegraf.Metric) (bool, error) {
+	vars := models.CELActivation(m)
+
+	if c.drop != nil {
+		result, _, err := c.drop.Eval(vars)
+		if err != nil {
+			return true, fmt.Errorf("evaluating drop expression failed: %w", err)
+		}
+		if drop, ok := result.Value().(bool); !ok {
+			return true, fmt.Errorf("invalid drop result type %T", result.Value())
+		} else if drop {
+			return false, nil
+		}
+	}
+
+	var name string
+	if c.name != nil {
+		result, _, err := c.name.Eval(vars)
+		if err != nil {
+			return true, fmt.Errorf("evaluating name expression failed: %w", err)
+		}
+		var ok bool
+		if name, ok = result.Value().(string); !ok {
+			return true, fmt.Errorf("invalid name result type %T", result.Value())
+		}
+	}
+
+	tags := make(map[string]ref.Val, len(c.tags))
+	for key, program := range c.tags {
+		result, _, err := program.Eval(vars)
+		if err != nil {
+			return true, fmt.Errorf("evaluating expression for tag %q failed: %w", key, err)
+		}
+		switch result.(type) {
+		case types.String, types.Null:
+		default:
+			return true, fmt.Errorf("invalid result type %T for tag %q", result.Value(), key)
+		}
+		tags[key] = result
+	}
+
+	fields := make(map[string]ref.Val, len(c.fields))
+	for key, program := range c.fields {
+		result, _, err := program.Eval(vars)
+		if err != nil {
+			return true, fmt.Errorf("evaluating expression for field %q failed: %w", key, err)
+		}
+		switch result.(type) {
+		case types.Bool, types.Int, types.Uint, types.Double, types.String, types.Null:
+		default:
+			return true, fmt.Errorf("invalid result type %T for field %q", result.Value(), key)
+		}
+		fields[key] = result
+	}
+
+	// Apply the results
+	if name != "" {
+		m.SetName(name)
+	}
+	for key, result := range tags {
+		if _, ok := result.(types.Null); ok {
+			m.RemoveTag(key)
+		} else {
+			m.AddTag(key, result.Value().(string))
+		}
+	}
+	for key, result := range fields {
+		if _, ok := result.(types.Null); ok {
+			m.RemoveField(key)
+		} else {
+			m.AddField(key, result.Value())
+		}
+	}
+
+	return true, nil
+}
+
+// compile checks and compiles the expression. The result of the expression
+// must either be of any of the given types or determined at runtime.
+func compile(env *cel.Env, expression string, allowed ...*cel.Type) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if len(allowed) > 0 {
+		valid := ast.OutputType().IsExactType(cel.DynType)
+		for _, t := range allowed {
+			valid = valid || ast.OutputType().IsExactType(t)
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid expression result type %v", ast.OutputType())
+		}
+	}
+
+	return env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+}
+
+func init() {
+	processors.Add("cel", func() telegraf.Processor {
+		return &CEL{}
+	})
+}
@@ -0,0 +1,181 @@
+package cel
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CEL
+		expected string
+	}{
+		{
+			name:     "no expression",
+			plugin:   &CEL{},
+			expected: "no expression given",
+		},
+		{
+			name:     "invalid syntax",
+			plugin:   &CEL{Fields: map[string]string{"x": "fields.a +"}},
+			expected: `compiling expression for field "x" failed`,
+		},
+		{
+			name:     "non-boolean drop",
+			plugin:   &CEL{Drop: "name + 'x'"},
+			expected: "invalid expression result type",
+		},
+		{
+			name:     "non-string name",
+			plugin:   &CEL{Name: "42"},
+			expected: "invalid expression result type",
+		},
+		{
+			name:     "non-string tag",
+			plugin:   &CEL{Tags: map[string]string{"x": "1.5"}},
+			expected: "invalid expression result type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CEL
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "compute fields",
+			plugin: &CEL{
+				Fields: map[string]string{
+					"usage_busy": "100.0 - fields.usage_idle",
+					"usage_idle": "null",
+					"high":       "fields.usage_idle < 10.0",
+				},
+			},
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_idle": 5.0}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_busy": 95.0, "high": true}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "set tags",
+			plugin: &CEL{
+				Tags: map[string]string{
+					"host":   "tags.host.lowerAscii()",
+					"unit":   "'percent'",
+					"region": "null",
+				},
+			},
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "SERVER", "region": "eu"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "server", "unit": "percent"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "rename",
+			plugin: &CEL{
+				Name: "name + '_' + tags.type",
+			},
+			input: []telegraf.Metric{
+				metric.New("disk", map[string]string{"type": "ssd"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("disk_ssd", map[string]string{"type": "ssd"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "drop",
+			plugin: &CEL{
+				Drop:   "fields.value > 10",
+				Fields: map[string]string{"double": "fields.value * 2"},
+			},
+			input: []telegraf.Metric{
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": 50}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": 5, "double": 10}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "errors leave metric untouched",
+			plugin: &CEL{
+				Tags:   map[string]string{"x": "'y'"},
+				Fields: map[string]string{"double": "fields.missing * 2"},
+			},
+			input: []telegraf.Metric{
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			actual := tt.plugin.Apply(tt.input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 2)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 50}, time.Unix(0, 0)),
+	}
+	for i, m := range input {
+		input[i], _ = metric.WithTracking(m, notify)
+	}
+
+	plugin := &CEL{
+		Drop: "fields.value > 10",
+		Log:  &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == len(input)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
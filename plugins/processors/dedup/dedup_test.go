@@ -2,6 +2,7 @@ package dedup
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -530,3 +531,35 @@ func TestStatePersistence(t *testing.T) {
 	}
 	require.Len(t, actualState, expectedLen)
 }
+
+func TestStateWhileApplying(t *testing.T) {
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Hour),
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+	}
+
+	// Getting the state concurrently to applying metrics must be safe
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			m := metric.New("metric",
+				map[string]string{"id": fmt.Sprintf("%d", i)},
+				map[string]interface{}{"foo": i},
+				time.Now(),
+			)
+			plugin.Apply(m)
+		}
+	}()
+	for range 100 {
+		_, ok := plugin.GetState().([]byte)
+		require.True(t, ok, "state is not a bytes array")
+	}
+	wg.Wait()
+
+	state, ok := plugin.GetState().([]byte)
+	require.True(t, ok, "state is not a bytes array")
+	require.Len(t, strings.Split(strings.TrimSpace(string(state)), "\n"), 100)
+}
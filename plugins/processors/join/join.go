@@ -0,0 +1,324 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package join
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Join struct {
+	SideNamepass    []string        `toml:"side_namepass"`
+	JoinOn          []string        `toml:"join_on"`
+	SideJoinOn      []string        `toml:"side_join_on"`
+	Tags            []string        `toml:"tags"`
+	Fields          []string        `toml:"fields"`
+	JoinType        string          `toml:"join_type"`
+	TTL             config.Duration `toml:"ttl"`
+	Delay           config.Duration `toml:"delay"`
+	DropSideMetrics bool            `toml:"drop_side_metrics"`
+	Log             telegraf.Logger `toml:"-"`
+
+	sideFilter  filter.Filter
+	tagFilter   filter.Filter
+	fieldFilter filter.Filter
+
+	// Side-stream data by join key
+	table map[string]*entry
+	// Main-stream metrics waiting for side-stream data in order of arrival
+	// and by join key
+	queue   []*pending
+	pending map[string][]*pending
+
+	acc    telegraf.Accumulator
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	sync.Mutex
+}
+
+// entry holds the tags and fields of a side-stream metric to join
+type entry struct {
+	tags    map[string]string
+	fields  map[string]interface{}
+	expires time.Time
+}
+
+// pending is a main-stream metric waiting for matching side-stream data
+type pending struct {
+	metric   telegraf.Metric
+	key      string
+	deadline time.Time
+	done     bool
+}
+
+func (*Join) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *Join) Init() error {
+	if len(j.SideNamepass) == 0 {
+		return errors.New("no side_namepass given")
+	}
+	if len(j.JoinOn) == 0 {
+		return errors.New("no join_on tags given")
+	}
+	if len(j.SideJoinOn) == 0 {
+		j.SideJoinOn = j.JoinOn
+	}
+	if len(j.SideJoinOn) != len(j.JoinOn) {
+		return errors.New("side_join_on must have the same number of tags as join_on")
+	}
+	switch j.JoinType {
+	case "left", "inner":
+	default:
+		return fmt.Errorf("invalid join_type %q", j.JoinType)
+	}
+	if j.TTL <= 0 {
+		return errors.New("ttl must be positive")
+	}
+	if j.Delay < 0 {
+		return errors.New("delay must not be negative")
+	}
+
+	var err error
+	if j.sideFilter, err = filter.Compile(j.SideNamepass); err != nil {
+		return fmt.Errorf("creating side_namepass filter failed: %w", err)
+	}
+	if j.tagFilter, err = filter.NewIncludeExcludeFilterDefaults(j.Tags, j.SideJoinOn, false, false); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	if j.fieldFilter, err = filter.Compile(j.Fields); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (j *Join) Start(acc telegraf.Accumulator) error {
+	j.acc = acc
+	j.table = make(map[string]*entry)
+	j.pending = make(map[string][]*pending)
+
+	// Regularly release delayed metrics and remove expired side-stream data
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	interval := time.Duration(j.TTL)
+	if j.Delay > 0 {
+		interval = min(interval, time.Duration(j.Delay))
+	}
+	interval = max(interval/2, 10*time.Millisecond)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.expire(time.Now())
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (j *Join) Stop() {
+	j.cancel()
+	j.wg.Wait()
+
+	j.Lock()
+	defer j.Unlock()
+
+	// Release all waiting metrics to avoid data loss
+	for _, p := range j.queue {
+		if !p.done {
+			j.unmatched(p.metric)
+		}
+	}
+	j.queue = nil
+	j.pending = make(map[string][]*pending)
+}
+
+func (j *Join) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	j.Lock()
+	defer j.Unlock()
+
+	now := time.Now()
+	if j.sideFilter.Match(m.Name()) {
+		j.addSide(m, now)
+		if j.DropSideMetrics {
+			m.Drop()
+		} else {
+			acc.AddMetric(m)
+		}
+		return nil
+	}
+
+	key, ok := joinKey(m, j.JoinOn)
+	if !ok {
+		j.unmatched(m)
+		return nil
+	}
+
+	if e, found := j.table[key]; found && now.Before(e.expires) {
+		join(m, e)
+		acc.AddMetric(m)
+		return nil
+	}
+
+	if j.Delay <= 0 {
+		j.unmatched(m)
+		return nil
+	}
+
+	// Wait for matching side-stream data to arrive
+	p := &pending{
+		metric:   m,
+		key:      key,
+		deadline: now.Add(time.Duration(j.Delay)),
+	}
+	j.queue = append(j.queue, p)
+	j.pending[key] = append(j.pending[key], p)
+
+	return nil
+}
+
+// addSide updates the join table with the data of the given side-stream metric
+// and releases the main-stream metrics waiting for it
+func (j *Join) addSide(m telegraf.Metric, now time.Time) {
+	key, ok := joinKey(m, j.SideJoinOn)
+	if !ok {
+		j.Log.Debugf("Side metric %q is missing join tags", m.Name())
+		return
+	}
+
+	e := &entry{
+		tags:    make(map[string]string),
+		fields:  make(map[string]interface{}),
+		expires: now.Add(time.Duration(j.TTL)),
+	}
+	for _, tag := range m.TagList() {
+		if j.tagFilter.Match(tag.Key) {
+			e.tags[tag.Key] = tag.Value
+		}
+	}
+	if j.fieldFilter != nil {
+		for _, field := range m.FieldList() {
+			if j.fieldFilter.Match(field.Key) {
+				e.fields[field.Key] = field.Value
+			}
+		}
+	}
+	j.table[key] = e
+
+	for _, p := range j.pending[key] {
+		join(p.metric, e)
+		j.acc.AddMetric(p.metric)
+		p.done = true
+	}
+	delete(j.pending, key)
+}
+
+// expire releases all waiting metrics exceeding the delay and removes expired
+// side-stream data
+func (j *Join) expire(now time.Time) {
+	j.Lock()
+	defer j.Unlock()
+
+	var n int
+	for _, p := range j.queue {
+		if !p.done && now.Before(p.deadline) {
+			break
+		}
+		if !p.done {
+			j.unmatched(p.metric)
+			p.done = true
+			j.removePending(p)
+		}
+		n++
+	}
+	j.queue = j.queue[n:]
+
+	for key, e := range j.table {
+		if !now.Before(e.expires) {
+			delete(j.table, key)
+		}
+	}
+}
+
+func (j *Join) removePending(p *pending) {
+	waiting := j.pending[p.key][:0]
+	for _, other := range j.pending[p.key] {
+		if other != p {
+			waiting = append(waiting, other)
+		}
+	}
+	if len(waiting) == 0 {
+		delete(j.pending, p.key)
+		return
+	}
+	j.pending[p.key] = waiting
+}
+
+// unmatched passes or drops a main-stream metric without side-stream data
+// depending on the join type
+func (j *Join) unmatched(m telegraf.Metric) {
+	if j.JoinType == "inner" {
+		m.Drop()
+		return
+	}
+	j.acc.AddMetric(m)
+}
+
+// join adds the side-stream data to the metric keeping existing tags and
+// fields of the metric
+func join(m telegraf.Metric, e *entry) {
+	for key, value := range e.tags {
+		if !m.HasTag(key) {
+			m.AddTag(key, value)
+		}
+	}
+	for key, value := range e.fields {
+		if !m.HasField(key) {
+			m.AddField(key, value)
+		}
+	}
+}
+
+func joinKey(m telegraf.Metric, tags []string) (string, bool) {
+	values := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		value, found := m.GetTag(tag)
+		if !found {
+			return "", false
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+func init() {
+	processors.AddStreaming("join", func() telegraf.StreamingProcessor {
+		return &Join{
+			Tags:     []string{"*"},
+			JoinType: "left",
+			TTL:      config.Duration(10 * time.Minute),
+		}
+	})
+}
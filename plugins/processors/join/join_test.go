@@ -0,0 +1,243 @@
+package join
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newJoin() *Join {
+	return &Join{
+		SideNamepass: []string{"kube_*"},
+		JoinOn:       []string{"pod"},
+		Tags:         []string{"*"},
+		JoinType:     "left",
+		TTL:          config.Duration(10 * time.Minute),
+		Log:          &testutil.Logger{},
+	}
+}
+
+func sideMetric(pod, app string) telegraf.Metric {
+	return metric.New(
+		"kube_pod",
+		map[string]string{"pod_name": pod, "app": app, "node": "n1"},
+		map[string]interface{}{"restarts": 2},
+		time.Unix(0, 0),
+	)
+}
+
+func mainMetric(pod string) telegraf.Metric {
+	return metric.New(
+		"procstat",
+		map[string]string{"pod": pod, "node": "n2"},
+		map[string]interface{}{"cpu": 1.5},
+		time.Unix(0, 0),
+	)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Join)
+		expected string
+	}{
+		{
+			name:     "no side stream",
+			modify:   func(j *Join) { j.SideNamepass = nil },
+			expected: "no side_namepass given",
+		},
+		{
+			name:     "no join tags",
+			modify:   func(j *Join) { j.JoinOn = nil },
+			expected: "no join_on tags given",
+		},
+		{
+			name:     "join tag mismatch",
+			modify:   func(j *Join) { j.SideJoinOn = []string{"a", "b"} },
+			expected: "side_join_on must have the same number of tags as join_on",
+		},
+		{
+			name:     "invalid join type",
+			modify:   func(j *Join) { j.JoinType = "outer" },
+			expected: `invalid join_type "outer"`,
+		},
+		{
+			name:     "no ttl",
+			modify:   func(j *Join) { j.TTL = 0 },
+			expected: "ttl must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newJoin()
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestLeftJoin(t *testing.T) {
+	plugin := newJoin()
+	plugin.SideJoinOn = []string{"pod_name"}
+	plugin.Fields = []string{"restarts"}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(sideMetric("a", "web"), &acc))
+	require.NoError(t, plugin.Add(mainMetric("a"), &acc))
+	require.NoError(t, plugin.Add(mainMetric("b"), &acc))
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		sideMetric("a", "web"),
+		metric.New(
+			"procstat",
+			map[string]string{"pod": "a", "node": "n2", "app": "web"},
+			map[string]interface{}{"cpu": 1.5, "restarts": 2},
+			time.Unix(0, 0),
+		),
+		mainMetric("b"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestInnerJoin(t *testing.T) {
+	plugin := newJoin()
+	plugin.SideJoinOn = []string{"pod_name"}
+	plugin.JoinType = "inner"
+	plugin.DropSideMetrics = true
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(sideMetric("a", "web"), &acc))
+	require.NoError(t, plugin.Add(mainMetric("a"), &acc))
+	require.NoError(t, plugin.Add(mainMetric("b"), &acc))
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat",
+			map[string]string{"pod": "a", "node": "n2", "app": "web"},
+			map[string]interface{}{"cpu": 1.5},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestDelay(t *testing.T) {
+	plugin := newJoin()
+	plugin.SideJoinOn = []string{"pod_name"}
+	plugin.Tags = []string{"app"}
+	plugin.Delay = config.Duration(100 * time.Millisecond)
+	plugin.DropSideMetrics = true
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Metrics are held back until the side-stream data arrives
+	require.NoError(t, plugin.Add(mainMetric("a"), &acc))
+	require.NoError(t, plugin.Add(mainMetric("b"), &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.NoError(t, plugin.Add(sideMetric("a", "web"), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat",
+			map[string]string{"pod": "a", "node": "n2", "app": "web"},
+			map[string]interface{}{"cpu": 1.5},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Unmatched metrics are released after the delay
+	expected = append(expected, mainMetric("b"))
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, time.Second, 10*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestTTL(t *testing.T) {
+	plugin := newJoin()
+	plugin.SideJoinOn = []string{"pod_name"}
+	plugin.TTL = config.Duration(50 * time.Millisecond)
+	plugin.DropSideMetrics = true
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(sideMetric("a", "web"), &acc))
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, plugin.Add(mainMetric("a"), &acc))
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{mainMetric("a")}, acc.GetTelegrafMetrics())
+}
+
+func TestStopReleasesMetrics(t *testing.T) {
+	plugin := newJoin()
+	plugin.Delay = config.Duration(time.Hour)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(mainMetric("a"), &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{mainMetric("a")}, acc.GetTelegrafMetrics())
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 3)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := []telegraf.Metric{sideMetric("a", "web"), mainMetric("a"), mainMetric("b")}
+	for i, m := range input {
+		input[i], _ = metric.WithTracking(m, notify)
+	}
+
+	plugin := newJoin()
+	plugin.SideJoinOn = []string{"pod_name"}
+	plugin.JoinType = "inner"
+	plugin.DropSideMetrics = true
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == len(input)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
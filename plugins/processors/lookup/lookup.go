@@ -3,31 +3,61 @@ package lookup
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
 type Processor struct {
-	Filenames   []string        `toml:"files"`
-	Fileformat  string          `toml:"format"`
-	KeyTemplate string          `toml:"key"`
-	Log         telegraf.Logger `toml:"-"`
+	Filenames       []string        `toml:"files"`
+	URLs            []string        `toml:"urls"`
+	Fileformat      string          `toml:"format"`
+	KeyTemplate     string          `toml:"key"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+	SQL             *sqlSource      `toml:"sql"`
+	Log             telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
 
 	tmpl     *template.Template
-	mappings map[string][]telegraf.Tag
+	mappings atomic.Pointer[table]
+	parse    func(r io.Reader, source string, t table) error
+	client   *http.Client
+	files    map[string]fileState
+
+	hits   selfstat.Stat
+	misses selfstat.Stat
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// table maps the lookup keys to the tags to add
+type table map[string][]telegraf.Tag
+
+// fileState identifies the version of a lookup file to detect changes
+type fileState struct {
+	modTime time.Time
+	size    int64
 }
 
 func (*Processor) SampleConfig() string {
@@ -35,34 +65,109 @@ func (*Processor) SampleConfig() string {
 }
 
 func (p *Processor) Init() error {
-	if len(p.Filenames) < 1 {
-		return errors.New("missing 'files'")
+	if len(p.Filenames) < 1 && len(p.URLs) < 1 && p.SQL == nil {
+		return errors.New("missing 'files', 'urls' or 'sql' source")
 	}
 
 	if p.KeyTemplate == "" {
 		return errors.New("missing 'key_template'")
 	}
 
+	if p.RefreshInterval < 0 {
+		return errors.New("'refresh_interval' must not be negative")
+	}
+
 	tmpl, err := template.New("key").Parse(p.KeyTemplate)
 	if err != nil {
 		return fmt.Errorf("creating template failed: %w", err)
 	}
 	p.tmpl = tmpl
 
-	p.mappings = make(map[string][]telegraf.Tag)
 	switch strings.ToLower(p.Fileformat) {
 	case "", "json":
-		return p.loadJSONFiles()
+		p.parse = parseJSON
 	case "csv_key_name_value":
-		return p.loadCSVKeyNameValueFiles()
+		p.parse = parseCSVKeyNameValue
 	case "csv_key_values":
-		return p.loadCSVKeyValuesFiles()
+		p.parse = parseCSVKeyValues
+	default:
+		return fmt.Errorf("invalid format %q", p.Fileformat)
+	}
+
+	if len(p.URLs) > 0 {
+		ctx := context.Background()
+		client, err := p.HTTPClientConfig.CreateClient(ctx, p.Log)
+		if err != nil {
+			return fmt.Errorf("creating HTTP client failed: %w", err)
+		}
+		p.client = client
 	}
 
-	return fmt.Errorf("invalid format %q", p.Fileformat)
+	if p.SQL != nil {
+		if err := p.SQL.init(); err != nil {
+			return err
+		}
+	}
+
+	tags := map[string]string{"key": p.KeyTemplate}
+	p.hits = selfstat.Register("lookup", "hits", tags)
+	p.misses = selfstat.Register("lookup", "misses", tags)
+
+	// Load the initial table to fail early on configuration errors
+	p.files = make(map[string]fileState, len(p.Filenames))
+	t, err := p.load()
+	if err != nil {
+		return err
+	}
+	p.mappings.Store(&t)
+
+	return nil
+}
+
+// Start launches the periodic refresh of the lookup table if configured
+func (p *Processor) Start(telegraf.Accumulator) error {
+	if p.RefreshInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(time.Duration(p.RefreshInterval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.refresh()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *Processor) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+
+	if p.SQL != nil {
+		p.SQL.close()
+	}
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
 }
 
 func (p *Processor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	mappings := *p.mappings.Load()
+
 	out := make([]telegraf.Metric, 0, len(in))
 	for _, raw := range in {
 		m := raw
@@ -74,54 +179,120 @@ func (p *Processor) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		if err := p.tmpl.Execute(&buf, m); err != nil {
 			p.Log.Errorf("generating key failed: %v", err)
 			p.Log.Debugf("metric was %v", m)
-		} else if tags, found := p.mappings[buf.String()]; found {
+		} else if tags, found := mappings[buf.String()]; found {
+			p.hits.Incr(1)
 			for _, tag := range tags {
 				m.AddTag(tag.Key, tag.Value)
 			}
+		} else {
+			p.misses.Incr(1)
 		}
 		out = append(out, raw)
 	}
 	return out
 }
 
-func (p *Processor) loadJSONFiles() error {
+// refresh reloads the lookup table if any of the sources might have changed.
+// Files are only reloaded if modified while remote sources are always queried.
+// In case of errors, the previous table is kept.
+func (p *Processor) refresh() {
+	if len(p.URLs) == 0 && p.SQL == nil && !p.filesChanged() {
+		return
+	}
+
+	t, err := p.load()
+	if err != nil {
+		p.Log.Errorf("Reloading lookup table failed, keeping previous data: %v", err)
+		return
+	}
+	p.mappings.Store(&t)
+	p.Log.Debugf("Reloaded lookup table with %d entries", len(t))
+}
+
+// load creates a new table from all configured sources
+func (p *Processor) load() (table, error) {
+	t := make(table)
 	for _, fn := range p.Filenames {
-		buf, err := os.ReadFile(fn)
-		if err != nil {
-			return fmt.Errorf("loading %q failed: %w", fn, err)
+		if err := p.loadFile(fn, t); err != nil {
+			return nil, err
 		}
-
-		var data map[string]map[string]string
-		if err := json.Unmarshal(buf, &data); err != nil {
-			return fmt.Errorf("parsing %q failed: %w", fn, err)
+	}
+	for _, u := range p.URLs {
+		if err := p.loadURL(u, t); err != nil {
+			return nil, err
 		}
-
-		for key, tags := range data {
-			for k, v := range tags {
-				p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: k, Value: v})
-			}
+	}
+	if p.SQL != nil {
+		if err := p.SQL.load(t); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return t, nil
 }
 
-func (p *Processor) loadCSVKeyNameValueFiles() error {
+func (p *Processor) filesChanged() bool {
 	for _, fn := range p.Filenames {
-		if err := p.loadCSVKeyNameValueFile(fn); err != nil {
-			return err
+		info, err := os.Stat(fn)
+		if err != nil {
+			// Let the reload report the error
+			return true
+		}
+		if p.files[fn] != (fileState{modTime: info.ModTime(), size: info.Size()}) {
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
-func (p *Processor) loadCSVKeyNameValueFile(fn string) error {
+func (p *Processor) loadFile(fn string, t table) error {
 	f, err := os.Open(fn)
 	if err != nil {
 		return fmt.Errorf("loading %q failed: %w", fn, err)
 	}
 	defer f.Close()
 
-	reader := csv.NewReader(f)
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("loading %q failed: %w", fn, err)
+	}
+	if err := p.parse(f, fn, t); err != nil {
+		return err
+	}
+	p.files[fn] = fileState{modTime: info.ModTime(), size: info.Size()}
+
+	return nil
+}
+
+func (p *Processor) loadURL(u string, t table) error {
+	resp, err := p.client.Get(u)
+	if err != nil {
+		return fmt.Errorf("loading %q failed: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("loading %q failed: received status code %d (%s)", u, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	return p.parse(resp.Body, u, t)
+}
+
+func parseJSON(r io.Reader, source string, t table) error {
+	var data map[string]map[string]string
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("parsing %q failed: %w", source, err)
+	}
+
+	for key, tags := range data {
+		for k, v := range tags {
+			t[key] = append(t[key], telegraf.Tag{Key: k, Value: v})
+		}
+	}
+	return nil
+}
+
+func parseCSVKeyNameValue(r io.Reader, source string, t table) error {
+	reader := csv.NewReader(r)
 	reader.Comment = '#'
 	reader.FieldsPerRecord = -1
 	reader.TrimLeadingSpace = true
@@ -134,42 +305,27 @@ func (p *Processor) loadCSVKeyNameValueFile(fn string) error {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return fmt.Errorf("reading line %d in %q failed: %w", line, fn, err)
+			return fmt.Errorf("reading line %d in %q failed: %w", line, source, err)
 		}
 		if len(data) < 3 {
-			return fmt.Errorf("line %d in %q has not enough columns, requiring at least `key,name,value`", line, fn)
+			return fmt.Errorf("line %d in %q has not enough columns, requiring at least `key,name,value`", line, source)
 		}
 		if len(data)%2 != 1 {
-			return fmt.Errorf("line %d in %q has a tag-name without value", line, fn)
+			return fmt.Errorf("line %d in %q has a tag-name without value", line, source)
 		}
 
 		key := data[0]
 		for i := 1; i < len(data)-1; i += 2 {
 			k, v := data[i], data[i+1]
-			p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: k, Value: v})
+			t[key] = append(t[key], telegraf.Tag{Key: k, Value: v})
 		}
 	}
 
 	return nil
 }
 
-func (p *Processor) loadCSVKeyValuesFiles() error {
-	for _, fn := range p.Filenames {
-		if err := p.loadCSVKeyValuesFile(fn); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (p *Processor) loadCSVKeyValuesFile(fn string) error {
-	f, err := os.Open(fn)
-	if err != nil {
-		return fmt.Errorf("loading %q failed: %w", fn, err)
-	}
-	defer f.Close()
-
-	reader := csv.NewReader(f)
+func parseCSVKeyValues(r io.Reader, source string, t table) error {
+	reader := csv.NewReader(r)
 	reader.Comment = '#'
 	reader.TrimLeadingSpace = true
 
@@ -177,12 +333,12 @@ func (p *Processor) loadCSVKeyValuesFile(fn string) error {
 	header, err := reader.Read()
 	if err != nil {
 		if errors.Is(err, io.EOF) {
-			return fmt.Errorf("missing header in %q", fn)
+			return fmt.Errorf("missing header in %q", source)
 		}
-		return fmt.Errorf("reading header in %q failed: %w", fn, err)
+		return fmt.Errorf("reading header in %q failed: %w", source, err)
 	}
 	if len(header) < 2 {
-		return fmt.Errorf("header in %q has not enough columns, requiring at least `key,value`", fn)
+		return fmt.Errorf("header in %q has not enough columns, requiring at least `key,value`", source)
 	}
 	header = header[1:]
 
@@ -194,20 +350,21 @@ func (p *Processor) loadCSVKeyValuesFile(fn string) error {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return fmt.Errorf("reading line %d in %q failed: %w", line, fn, err)
+			return fmt.Errorf("reading line %d in %q failed: %w", line, source, err)
 		}
 
 		key := data[0]
 		for i, v := range data[1:] {
 			v = strings.TrimSpace(v)
 			if v != "" {
-				p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: header[i], Value: v})
+				t[key] = append(t[key], telegraf.Tag{Key: header[i], Value: v})
 			}
 		}
 	}
 
 	return nil
 }
+
 func init() {
 	processors.Add("lookup", func() telegraf.Processor {
 		return &Processor{}
@@ -1,12 +1,17 @@
 package lookup
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -176,3 +181,175 @@ func TestCasesTracking(t *testing.T) {
 		})
 	}
 }
+
+func TestReloadFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.json")
+	require.NoError(t, os.WriteFile(fn, []byte(`{"a": {"location": "eu-central"}}`), 0600))
+
+	plugin := &Processor{
+		Filenames:       []string{fn},
+		KeyTemplate:     `{{.Tag "host"}}`,
+		RefreshInterval: config.Duration(10 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"host": "a", "location": "eu-central"},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input.Copy()))
+
+	// Invalid files must not replace the current table
+	require.NoError(t, os.WriteFile(fn, []byte(`{"a": `), 0600))
+	time.Sleep(50 * time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input.Copy()))
+
+	// Changes should be picked up
+	require.NoError(t, os.WriteFile(fn, []byte(`{"a": {"location": "us-west", "rack": "C01-42"}}`), 0600))
+	expected = []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"host": "a", "location": "us-west", "rack": "C01-42"},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+	require.Eventually(t, func() bool {
+		return testutil.MetricEqual(expected[0], plugin.Apply(input.Copy())[0], testutil.SortMetrics())
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestURL(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lut.csv" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if requests.Add(1) == 1 {
+			fmt.Fprintln(w, "-,location")
+			fmt.Fprintln(w, "a,eu-central")
+			return
+		}
+		fmt.Fprintln(w, "-,location")
+		fmt.Fprintln(w, "a,us-west")
+	}))
+	defer server.Close()
+
+	plugin := &Processor{
+		URLs:        []string{server.URL + "/missing.csv"},
+		Fileformat:  "csv_key_values",
+		KeyTemplate: `{{.Tag "host"}}`,
+		Log:         testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "received status code 404")
+
+	plugin.URLs = []string{server.URL + "/lut.csv"}
+	plugin.RefreshInterval = config.Duration(10 * time.Millisecond)
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	actual := plugin.Apply(input.Copy())
+	require.Equal(t, map[string]string{"host": "a", "location": "eu-central"}, actual[0].Tags())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		location, _ := plugin.Apply(input.Copy())[0].GetTag("location")
+		return location == "us-west"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSQL(t *testing.T) {
+	db, mock, err := sqlmock.NewWithDSN("lookup_test")
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT host, owner, rack FROM inventory").WillReturnRows(
+		sqlmock.NewRows([]string{"host", "owner", "rack"}).
+			AddRow("a", "alice", nil).
+			AddRow("b", []byte("bob"), 12),
+	)
+
+	plugin := &Processor{
+		SQL: &sqlSource{
+			Driver:    "sqlmock",
+			Dsn:       config.NewSecret([]byte("lookup_test")),
+			Query:     "SELECT host, owner, rack FROM inventory",
+			KeyColumn: "host",
+		},
+		KeyTemplate: `{{.Tag "host"}}`,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"host": "b"}, map[string]interface{}{"value": 23}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"host": "c"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"host": "a", "owner": "alice"},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"test",
+			map[string]string{"host": "b", "owner": "bob", "rack": "12"},
+			map[string]interface{}{"value": 23},
+			time.Unix(0, 0),
+		),
+		metric.New("test", map[string]string{"host": "c"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestSQLInvalid(t *testing.T) {
+	plugin := &Processor{
+		SQL:         &sqlSource{Driver: "foo", Query: "SELECT 1", KeyColumn: "key"},
+		KeyTemplate: `{{.Tag "host"}}`,
+	}
+	require.ErrorContains(t, plugin.Init(), `driver "foo" not supported`)
+
+	plugin.SQL = &sqlSource{Driver: "sqlmock", KeyColumn: "key"}
+	require.ErrorContains(t, plugin.Init(), "missing SQL 'query'")
+
+	plugin.SQL = &sqlSource{Driver: "sqlmock", Query: "SELECT 1"}
+	require.ErrorContains(t, plugin.Init(), "missing SQL 'key_column'")
+}
+
+func TestHitMissStats(t *testing.T) {
+	plugin := &Processor{
+		Filenames:   []string{filepath.Join("testcases", "normal_lookup_json", "lut.json")},
+		KeyTemplate: `{{.Name}}-{{.Tag "host"}}`,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Statistics are shared between instances with the same key
+	hits, misses := plugin.hits.Get(), plugin.misses.Get()
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	input, err := testutil.ParseMetricsFromFile(filepath.Join("testcases", "normal_lookup_json", "input.influx"), parser)
+	require.NoError(t, err)
+	plugin.Apply(input...)
+
+	require.Equal(t, int64(4), plugin.hits.Get()-hits)
+	require.Equal(t, int64(1), plugin.misses.Get()-misses)
+}
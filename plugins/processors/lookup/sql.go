@@ -0,0 +1,121 @@
+package lookup
+
+import (
+	"context"
+	dbsql "database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_sql "github.com/influxdata/telegraf/plugins/common/sql"
+)
+
+// sqlSource queries the lookup table from a database. The key is taken from
+// the key column and all other non-empty columns are added as tags.
+type sqlSource struct {
+	Driver    string          `toml:"driver"`
+	Dsn       config.Secret   `toml:"dsn"`
+	Query     string          `toml:"query"`
+	KeyColumn string          `toml:"key_column"`
+	Timeout   config.Duration `toml:"timeout"`
+
+	db *dbsql.DB
+}
+
+func (s *sqlSource) init() error {
+	if s.Query == "" {
+		return errors.New("missing SQL 'query'")
+	}
+	if s.KeyColumn == "" {
+		return errors.New("missing SQL 'key_column'")
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = config.Duration(5 * time.Second)
+	}
+
+	driverName, err := common_sql.DriverName(s.Driver)
+	if err != nil {
+		return err
+	}
+
+	dsnSecret, err := s.Dsn.Get()
+	if err != nil {
+		return fmt.Errorf("getting DSN failed: %w", err)
+	}
+	dsn := dsnSecret.String()
+	dsnSecret.Destroy()
+
+	s.db, err = dbsql.Open(driverName, dsn)
+	if err != nil {
+		return fmt.Errorf("opening database failed: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlSource) close() {
+	if s.db != nil {
+		s.db.Close()
+	}
+}
+
+func (s *sqlSource) load(t table) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, s.Query)
+	if err != nil {
+		return fmt.Errorf("running query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("getting columns failed: %w", err)
+	}
+	keyIdx := -1
+	for i, name := range columnNames {
+		if name == s.KeyColumn {
+			keyIdx = i
+			break
+		}
+	}
+	if keyIdx < 0 {
+		return fmt.Errorf("key column %q not found in query result", s.KeyColumn)
+	}
+
+	columnData := make([]interface{}, len(columnNames))
+	columnDataPtr := make([]interface{}, len(columnNames))
+	for i := range columnData {
+		columnDataPtr[i] = &columnData[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(columnDataPtr...); err != nil {
+			return fmt.Errorf("scanning row failed: %w", err)
+		}
+
+		key, err := internal.ToString(columnData[keyIdx])
+		if err != nil {
+			return fmt.Errorf("converting key column %q failed: %w", s.KeyColumn, err)
+		}
+
+		for i, name := range columnNames {
+			if i == keyIdx || columnData[i] == nil {
+				continue
+			}
+			value, err := internal.ToString(columnData[i])
+			if err != nil {
+				return fmt.Errorf("converting column %q failed: %w", name, err)
+			}
+			if v := strings.TrimSpace(value); v != "" {
+				t[key] = append(t[key], telegraf.Tag{Key: name, Value: v})
+			}
+		}
+	}
+
+	return rows.Err()
+}
@@ -3711,6 +3711,51 @@ def apply(metric):
 	require.EqualValues(t, expectedState, actualState, "mismatch in state")
 }
 
+func TestStateWhileProcessing(t *testing.T) {
+	source := `
+def apply(metric):
+  state[str(metric.fields["value"])] = metric.fields["value"]
+  return metric
+`
+	plugin := &Starlark{
+		Common: common.Common{
+			StarlarkLoadFunc: testLoadFunc,
+			Source:           source,
+			Log:              testutil.Logger{},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	// Getting the state concurrently to processing metrics must be safe
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(0, 0))
+			if err := plugin.Add(m, &acc); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	var pi telegraf.StatefulPlugin = plugin
+	for range 100 {
+		_, ok := pi.GetState().([]byte)
+		require.True(t, ok, "state is not a bytes array")
+	}
+	wg.Wait()
+	plugin.Stop()
+
+	var actualState map[string]interface{}
+	stateData, ok := pi.GetState().([]byte)
+	require.True(t, ok, "state is not a bytes array")
+	require.NoError(t, gob.NewDecoder(bytes.NewBuffer(stateData)).Decode(&actualState))
+	require.Len(t, actualState, 100)
+}
+
 func TestUsePredefinedStateName(t *testing.T) {
 	source := `
 def apply(metric):
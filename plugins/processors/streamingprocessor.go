@@ -14,6 +14,13 @@ func NewStreamingProcessorFromProcessor(p telegraf.Processor) telegraf.Streaming
 	return sp
 }
 
+// startStopper is implemented by standard processors requiring background
+// work such as periodically refreshing their data
+type startStopper interface {
+	Start(acc telegraf.Accumulator) error
+	Stop()
+}
+
 type streamingProcessor struct {
 	processor telegraf.Processor
 	acc       telegraf.Accumulator
@@ -26,6 +33,9 @@ func (sp *streamingProcessor) SampleConfig() string {
 
 func (sp *streamingProcessor) Start(acc telegraf.Accumulator) error {
 	sp.acc = acc
+	if p, ok := sp.processor.(startStopper); ok {
+		return p.Start(acc)
+	}
 	return nil
 }
 
@@ -36,7 +46,10 @@ func (sp *streamingProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) e
 	return nil
 }
 
-func (*streamingProcessor) Stop() {
+func (sp *streamingProcessor) Stop() {
+	if p, ok := sp.processor.(startStopper); ok {
+		p.Stop()
+	}
 }
 
 // Make the streamingProcessor of type Initializer to be able
@@ -0,0 +1,224 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package throttle
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Interval for removing the buckets of series not seen for a while
+const cleanupInterval = time.Minute
+
+type Throttle struct {
+	Rate          float64         `toml:"rate"`
+	Burst         int64           `toml:"burst"`
+	SamplePercent float64         `toml:"sample_percent"`
+	SeriesTags    []string        `toml:"series_tags"`
+	Keep          string          `toml:"keep"`
+	Log           telegraf.Logger `toml:"-"`
+
+	keep        cel.Program
+	seriesTags  []string
+	threshold   uint64
+	buckets     map[uint64]*bucket
+	lastCleanup time.Time
+	now         func() time.Time
+
+	kept        selfstat.Stat
+	sampled     selfstat.Stat
+	rateLimited selfstat.Stat
+}
+
+// bucket is a token bucket refilled with the configured rate up to the burst
+// size where each metric consumes one token
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (*Throttle) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Throttle) Init() error {
+	if t.Rate < 0 {
+		return errors.New("rate must not be negative")
+	}
+	if t.Burst < 0 {
+		return errors.New("burst must not be negative")
+	}
+	if t.SamplePercent < 0 || t.SamplePercent > 100 {
+		return fmt.Errorf("sample_percent %v out of range [0, 100]", t.SamplePercent)
+	}
+	if t.Rate == 0 && t.SamplePercent >= 100 {
+		return errors.New("neither rate nor sample_percent limits the metrics")
+	}
+	if t.Rate > 0 && t.Burst == 0 {
+		t.Burst = int64(math.Ceil(t.Rate))
+	}
+
+	if t.Keep != "" {
+		env, err := models.NewCELEnvironment()
+		if err != nil {
+			return err
+		}
+		ast, issues := env.Compile(t.Keep)
+		if issues.Err() != nil {
+			return fmt.Errorf("compiling keep expression failed: %w", issues.Err())
+		}
+		if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+			return fmt.Errorf("invalid keep expression result type %v", ast.OutputType())
+		}
+		if t.keep, err = env.Program(ast, cel.EvalOptions(cel.OptOptimize)); err != nil {
+			return fmt.Errorf("creating keep program failed: %w", err)
+		}
+	}
+
+	// Keep the series of a fraction p of the hash space
+	if t.SamplePercent >= 100 {
+		t.threshold = math.MaxUint64
+	} else {
+		t.threshold = uint64(t.SamplePercent / 100 * math.MaxUint64)
+	}
+
+	t.seriesTags = make([]string, len(t.SeriesTags))
+	copy(t.seriesTags, t.SeriesTags)
+	sort.Strings(t.seriesTags)
+
+	t.buckets = make(map[uint64]*bucket)
+	if t.now == nil {
+		t.now = time.Now
+	}
+	t.lastCleanup = t.now()
+
+	t.kept = selfstat.Register("throttle", "kept", map[string]string{})
+	t.sampled = selfstat.Register("throttle", "dropped_sampling", map[string]string{})
+	t.rateLimited = selfstat.Register("throttle", "dropped_rate_limit", map[string]string{})
+
+	return nil
+}
+
+func (t *Throttle) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := t.now()
+	if t.Rate > 0 && now.Sub(t.lastCleanup) >= cleanupInterval {
+		t.cleanup(now)
+		t.lastCleanup = now
+	}
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		if t.pass(m, now) {
+			out = append(out, m)
+			t.kept.Incr(1)
+		} else {
+			m.Drop()
+		}
+	}
+	return out
+}
+
+// pass returns true if the metric should be passed on. Metrics matching the
+// keep expression always pass, all others are first sampled and then checked
+// against the rate limit of their series.
+func (t *Throttle) pass(m telegraf.Metric, now time.Time) bool {
+	if t.keep != nil {
+		result, _, err := t.keep.Eval(models.CELActivation(m))
+		if err != nil {
+			t.Log.Errorf("Evaluating keep expression failed: %v", err)
+		} else if keep, ok := result.Value().(bool); !ok {
+			t.Log.Errorf("Invalid keep result type %T", result.Value())
+		} else if keep {
+			return true
+		}
+	}
+
+	id := t.seriesID(m)
+	if t.threshold == 0 || id > t.threshold {
+		t.sampled.Incr(1)
+		return false
+	}
+
+	if t.Rate > 0 && !t.take(id, now) {
+		t.rateLimited.Incr(1)
+		return false
+	}
+	return true
+}
+
+// take consumes a token from the bucket of the given series and returns false
+// if no token is available
+func (t *Throttle) take(id uint64, now time.Time) bool {
+	b, found := t.buckets[id]
+	if !found {
+		b = &bucket{tokens: float64(t.Burst), last: now}
+		t.buckets[id] = b
+	}
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*t.Rate, float64(t.Burst))
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// cleanup removes the buckets of series that would be completely refilled by
+// now as those are equivalent to new buckets
+func (t *Throttle) cleanup(now time.Time) {
+	refill := time.Duration(float64(t.Burst) / t.Rate * float64(time.Second))
+	for id, b := range t.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(t.buckets, id)
+		}
+	}
+}
+
+// seriesID returns the hash of the metric name and the series tags. The hash
+// is stable across restarts and instances so the same series are sampled.
+func (t *Throttle) seriesID(m telegraf.Metric) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte("\n"))
+	if len(t.seriesTags) == 0 {
+		// The tag-list is sorted by key
+		for _, tag := range m.TagList() {
+			h.Write([]byte(tag.Key))
+			h.Write([]byte("\x00"))
+			h.Write([]byte(tag.Value))
+			h.Write([]byte("\n"))
+		}
+		return h.Sum64()
+	}
+	for _, key := range t.seriesTags {
+		value, _ := m.GetTag(key)
+		h.Write([]byte(key))
+		h.Write([]byte("\x00"))
+		h.Write([]byte(value))
+		h.Write([]byte("\n"))
+	}
+	return h.Sum64()
+}
+
+func init() {
+	processors.Add("throttle", func() telegraf.Processor {
+		return &Throttle{SamplePercent: 100}
+	})
+}
@@ -0,0 +1,188 @@
+package throttle
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Throttle
+		expected string
+	}{
+		{
+			name:     "no limit",
+			plugin:   &Throttle{SamplePercent: 100},
+			expected: "neither rate nor sample_percent limits the metrics",
+		},
+		{
+			name:     "negative rate",
+			plugin:   &Throttle{Rate: -1},
+			expected: "rate must not be negative",
+		},
+		{
+			name:     "sample percentage out of range",
+			plugin:   &Throttle{SamplePercent: 101},
+			expected: "sample_percent 101 out of range",
+		},
+		{
+			name:     "invalid keep expression",
+			plugin:   &Throttle{Rate: 1, Keep: "fields.a +"},
+			expected: "compiling keep expression failed",
+		},
+		{
+			name:     "non-boolean keep expression",
+			plugin:   &Throttle{Rate: 1, Keep: "name + 'x'"},
+			expected: "invalid keep expression result type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &Throttle{
+		Rate:          2,
+		Burst:         3,
+		SamplePercent: 100,
+		SeriesTags:    []string{"host"},
+		Log:           &testutil.Logger{},
+		now:           func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	newMetric := func(host, source string, value int) telegraf.Metric {
+		return metric.New(
+			"app",
+			map[string]string{"host": host, "source": source},
+			map[string]interface{}{"value": value},
+			time.Unix(0, 0),
+		)
+	}
+
+	// The burst passes, the series tags identify the series
+	input := []telegraf.Metric{
+		newMetric("a", "x", 1),
+		newMetric("a", "y", 2),
+		newMetric("a", "x", 3),
+		newMetric("a", "x", 4),
+		newMetric("b", "x", 5),
+	}
+	expected := []telegraf.Metric{input[0], input[1], input[2], input[4]}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+
+	// Tokens are refilled with the rate
+	now = now.Add(time.Second)
+	input = []telegraf.Metric{
+		newMetric("a", "x", 6),
+		newMetric("a", "x", 7),
+		newMetric("a", "x", 8),
+	}
+	expected = []telegraf.Metric{input[0], input[1]}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+
+	// Idle series are removed
+	now = now.Add(time.Hour)
+	plugin.Apply(newMetric("c", "x", 9))
+	require.Len(t, plugin.buckets, 1)
+}
+
+func TestSampling(t *testing.T) {
+	plugin := &Throttle{
+		SamplePercent: 25,
+		Log:           &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := make([]telegraf.Metric, 0, 2000)
+	for i := range 1000 {
+		for j := range 2 {
+			input = append(input, metric.New(
+				"app",
+				map[string]string{"host": fmt.Sprintf("host%d", i)},
+				map[string]interface{}{"value": j},
+				time.Unix(0, 0),
+			))
+		}
+	}
+	actual := plugin.Apply(input...)
+	require.InDelta(t, 500, len(actual), 100)
+
+	// Series must be sampled consistently
+	hosts := make(map[string]int)
+	for _, m := range actual {
+		hosts[m.Tags()["host"]]++
+	}
+	for host, n := range hosts {
+		require.Equalf(t, 2, n, "series %q not kept consistently", host)
+	}
+
+	// Another instance must sample the same series
+	other := &Throttle{SamplePercent: 25, Log: &testutil.Logger{}}
+	require.NoError(t, other.Init())
+	testutil.RequireMetricsEqual(t, actual, other.Apply(input...))
+}
+
+func TestKeep(t *testing.T) {
+	plugin := &Throttle{
+		SamplePercent: 0,
+		Keep:          "tags.level == 'error' || fields.duration > 1.0",
+		Log:           &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("app", map[string]string{"level": "info"}, map[string]interface{}{"duration": 0.5}, time.Unix(0, 0)),
+		metric.New("app", map[string]string{"level": "error"}, map[string]interface{}{"duration": 0.5}, time.Unix(0, 0)),
+		metric.New("app", map[string]string{"level": "info"}, map[string]interface{}{"duration": 1.5}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{input[1], input[2]}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 3)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		m := metric.New("app", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(0, 0))
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Throttle{Rate: 1, SamplePercent: 100, Log: &testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
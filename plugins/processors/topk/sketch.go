@@ -0,0 +1,102 @@
+package topk
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// spaceSaving implements the weighted Space-Saving algorithm tracking the
+// approximate heavy hitters of a stream in bounded memory. At most capacity
+// groups are tracked; when a new group arrives with all counters in use, the
+// group with the smallest count is replaced and the new group inherits its
+// count as error. The true sum of a tracked group lies within
+// [count - error, count] and every group with a true sum larger than
+// total / capacity is guaranteed to be tracked.
+type spaceSaving struct {
+	capacity int
+	counters map[string]*sketchCounter
+	heap     counterHeap
+}
+
+// sketchCounter holds the estimated sum of a group together with the
+// information required to create the output metric for the group
+type sketchCounter struct {
+	key   string
+	name  string
+	tags  map[string]string
+	count float64
+	error float64
+	index int
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{
+		capacity: capacity,
+		counters: make(map[string]*sketchCounter, capacity),
+		heap:     make(counterHeap, 0, capacity),
+	}
+}
+
+// add increases the count of the given group by the given non-negative weight
+func (s *spaceSaving) add(key, name string, tags map[string]string, weight float64) {
+	if c, found := s.counters[key]; found {
+		c.count += weight
+		heap.Fix(&s.heap, c.index)
+		return
+	}
+
+	if len(s.heap) < s.capacity {
+		c := &sketchCounter{key: key, name: name, tags: tags, count: weight}
+		s.counters[key] = c
+		heap.Push(&s.heap, c)
+		return
+	}
+
+	// Replace the group with the smallest count
+	c := s.heap[0]
+	delete(s.counters, c.key)
+	c.key, c.name, c.tags = key, name, tags
+	c.error = c.count
+	c.count += weight
+	s.counters[key] = c
+	heap.Fix(&s.heap, 0)
+}
+
+// top returns the k groups with the largest estimated sums in descending order
+func (s *spaceSaving) top(k int) []*sketchCounter {
+	counters := make([]*sketchCounter, 0, len(s.heap))
+	counters = append(counters, s.heap...)
+	sort.SliceStable(counters, func(i, j int) bool {
+		if counters[i].count != counters[j].count {
+			return counters[i].count > counters[j].count
+		}
+		return counters[i].key < counters[j].key
+	})
+	return counters[:min(k, len(counters))]
+}
+
+// counterHeap is a min-heap of counters ordered by their count
+type counterHeap []*sketchCounter
+
+func (h counterHeap) Len() int           { return len(h) }
+func (h counterHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+
+func (h counterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *counterHeap) Push(x any) {
+	c := x.(*sketchCounter)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *counterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	c := old[n-1]
+	*h = old[:n-1]
+	return c
+}
@@ -3,6 +3,7 @@ package topk
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -28,9 +29,12 @@ type TopK struct {
 	AddGroupByTag      string          `toml:"add_groupby_tag"`
 	AddRankFields      []string        `toml:"add_rank_fields"`
 	AddAggregateFields []string        `toml:"add_aggregate_fields"`
+	Mode               string          `toml:"mode"`
+	Capacity           int             `toml:"capacity"`
 	Log                telegraf.Logger `toml:"-"`
 
 	cache           map[string][]telegraf.Metric
+	sketches        map[string]*spaceSaving
 	tagsGlobs       filter.Filter
 	rankFieldSet    map[string]bool
 	aggFieldSet     map[string]bool
@@ -48,6 +52,8 @@ func New() *TopK {
 	topk.Aggregation = "mean"
 	topk.GroupBy = []string{"*"}
 	topk.AddGroupByTag = ""
+	topk.Mode = "exact"
+	topk.Capacity = 1000
 
 	// Initialize cache
 	topk.Reset()
@@ -78,8 +84,28 @@ func (*TopK) SampleConfig() string {
 	return sampleConfig
 }
 
+func (t *TopK) Init() error {
+	switch t.Mode {
+	case "", "exact":
+	case "sketch":
+		if t.Aggregation != "sum" {
+			return fmt.Errorf("aggregation %q not supported in sketch mode", t.Aggregation)
+		}
+		if t.Bottomk {
+			return errors.New("bottomk not supported in sketch mode")
+		}
+		if t.Capacity < t.K {
+			return fmt.Errorf("capacity %d must not be less than k", t.Capacity)
+		}
+	default:
+		return fmt.Errorf("invalid mode %q", t.Mode)
+	}
+	return nil
+}
+
 func (t *TopK) Reset() {
 	t.cache = make(map[string][]telegraf.Metric)
+	t.sketches = make(map[string]*spaceSaving)
 	t.lastAggregation = time.Now()
 }
 
@@ -175,6 +201,12 @@ func (t *TopK) Apply(in ...telegraf.Metric) []telegraf.Metric {
 			continue
 		}
 
+		// In sketch mode only the estimated sums of the groups are kept
+		if t.Mode == "sketch" {
+			t.addToSketch(m)
+			continue
+		}
+
 		// Add the metric to the internal cache
 		t.groupBy(m)
 	}
@@ -182,6 +214,9 @@ func (t *TopK) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	// If enough time has passed
 	elapsed := time.Since(t.lastAggregation)
 	if elapsed >= time.Duration(t.Period) {
+		if t.Mode == "sketch" {
+			return t.pushSketch()
+		}
 		return t.push()
 	}
 
@@ -262,6 +297,80 @@ func (t *TopK) push() []telegraf.Metric {
 	return result
 }
 
+func (t *TopK) addToSketch(m telegraf.Metric) {
+	groupkey, err := t.generateGroupByKey(m)
+	if err != nil {
+		t.Log.Errorf("Could not generate group key: %v", err)
+		return
+	}
+
+	// Only keep the tags used for grouping as the other tags may differ
+	// between the metrics of a group
+	var tags map[string]string
+	for _, field := range t.Fields {
+		fieldVal, ok := m.GetField(field)
+		if !ok {
+			continue
+		}
+		val, ok := convert(fieldVal)
+		if !ok || val < 0 {
+			t.Log.Debugf("Ignoring value %v of field %q from metric %q", fieldVal, field, m.Name())
+			continue
+		}
+
+		if tags == nil {
+			tags = make(map[string]string)
+			for _, tag := range m.TagList() {
+				if t.tagsGlobs != nil && t.tagsGlobs.Match(tag.Key) {
+					tags[tag.Key] = tag.Value
+				}
+			}
+		}
+
+		sketch, ok := t.sketches[field]
+		if !ok {
+			sketch = newSpaceSaving(t.Capacity)
+			t.sketches[field] = sketch
+		}
+		sketch.add(groupkey, m.Name(), tags, val)
+	}
+}
+
+func (t *TopK) pushSketch() []telegraf.Metric {
+	now := time.Now()
+
+	// Create one metric per group containing the estimates of all fields the
+	// group is in the top K for
+	result := make([]telegraf.Metric, 0, t.K)
+	groups := make(map[string]telegraf.Metric)
+	for _, field := range t.Fields {
+		sketch, ok := t.sketches[field]
+		if !ok {
+			continue
+		}
+		for i, c := range sketch.top(t.K) {
+			m, ok := groups[c.key]
+			if !ok {
+				m = metric.New(c.name, c.tags, map[string]interface{}{}, now)
+				if t.AddGroupByTag != "" {
+					m.AddTag(t.AddGroupByTag, c.key)
+				}
+				groups[c.key] = m
+				result = append(result, m)
+			}
+			m.AddField(field, c.count)
+			m.AddField(field+"_topk_error", c.error)
+			if t.rankFieldSet[field] {
+				m.AddField(field+"_topk_rank", i+1)
+			}
+		}
+	}
+
+	t.Reset()
+
+	return result
+}
+
 // Function that generates the aggregation functions
 func (t *TopK) getAggregationFunction(aggOperation string) (func([]telegraf.Metric, []string) map[string]float64, error) {
 	// This is a function aggregates a set of metrics using a given aggregation function
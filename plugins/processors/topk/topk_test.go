@@ -1,6 +1,7 @@
 package topk
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -579,3 +580,138 @@ func TestTracking(t *testing.T) {
 		return len(input) == len(delivered)
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
+
+func TestSketchInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*TopK)
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			modify:   func(tk *TopK) { tk.Mode = "foo" },
+			expected: `invalid mode "foo"`,
+		},
+		{
+			name:     "mean aggregation",
+			modify:   func(tk *TopK) { tk.Mode = "sketch" },
+			expected: `aggregation "mean" not supported in sketch mode`,
+		},
+		{
+			name: "bottomk",
+			modify: func(tk *TopK) {
+				tk.Mode = "sketch"
+				tk.Aggregation = "sum"
+				tk.Bottomk = true
+			},
+			expected: "bottomk not supported in sketch mode",
+		},
+		{
+			name: "capacity less than k",
+			modify: func(tk *TopK) {
+				tk.Mode = "sketch"
+				tk.Aggregation = "sum"
+				tk.Capacity = 5
+			},
+			expected: "capacity 5 must not be less than k",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := New()
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSketch(t *testing.T) {
+	plugin := New()
+	plugin.Period = 0
+	plugin.K = 2
+	plugin.Mode = "sketch"
+	plugin.Aggregation = "sum"
+	plugin.GroupBy = []string{"url"}
+	plugin.Fields = []string{"bytes", "requests"}
+	plugin.AddRankFields = []string{"bytes"}
+	plugin.AddGroupByTag = "group"
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"url": "/a", "host": "x"}, map[string]interface{}{"bytes": 100, "requests": 1}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"url": "/b", "host": "x"}, map[string]interface{}{"bytes": 10, "requests": 5}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"url": "/c", "host": "y"}, map[string]interface{}{"bytes": 50, "requests": 1}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"url": "/b", "host": "y"}, map[string]interface{}{"bytes": 20, "requests": 5}, time.Unix(0, 0)),
+		metric.New("http", map[string]string{"url": "/c", "host": "y"}, map[string]interface{}{"bytes": -5}, time.Unix(0, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"http",
+			map[string]string{"url": "/a", "group": "http&url=/a&"},
+			map[string]interface{}{
+				"bytes":               float64(100),
+				"bytes_topk_error":    float64(0),
+				"bytes_topk_rank":     1,
+				"requests":            float64(1),
+				"requests_topk_error": float64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"http",
+			map[string]string{"url": "/c", "group": "http&url=/c&"},
+			map[string]interface{}{"bytes": float64(50), "bytes_topk_error": float64(0), "bytes_topk_rank": 2},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"http",
+			map[string]string{"url": "/b", "group": "http&url=/b&"},
+			map[string]interface{}{"requests": float64(10), "requests_topk_error": float64(0)},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestSketchBoundedMemory(t *testing.T) {
+	plugin := New()
+	plugin.Period = config.Duration(time.Hour)
+	plugin.K = 3
+	plugin.Mode = "sketch"
+	plugin.Aggregation = "sum"
+	plugin.Capacity = 20
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	// Interleave three heavy hitters with many rare groups
+	heavy := map[string]float64{"a": 1000, "b": 500, "c": 250}
+	for i := 0; i < 1000; i++ {
+		var input []telegraf.Metric
+		for _, key := range []string{"a", "b", "c"} {
+			input = append(input, metric.New("flow", map[string]string{"src": key}, map[string]interface{}{"value": heavy[key] / 100}, time.Unix(0, 0)))
+		}
+		input = append(input, metric.New("flow", map[string]string{"src": fmt.Sprintf("rare%d", i)}, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+		require.Empty(t, plugin.Apply(input...))
+		require.LessOrEqual(t, len(plugin.sketches["value"].counters), plugin.Capacity)
+	}
+
+	plugin.Period = 0
+	actual := plugin.Apply()
+	require.Len(t, actual, 3)
+	for i, key := range []string{"a", "b", "c"} {
+		m := actual[i]
+		src, _ := m.GetTag("src")
+		require.Equal(t, key, src)
+
+		estimate := m.Fields()["value"].(float64)
+		bound := m.Fields()["value_topk_error"].(float64)
+		truth := heavy[key] * 10
+		require.GreaterOrEqual(t, estimate, truth)
+		require.LessOrEqual(t, estimate-bound, truth)
+	}
+}
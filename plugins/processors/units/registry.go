@@ -0,0 +1,169 @@
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unit describes a unit by its dimension and the linear transformation to the
+// base unit of that dimension, i.e. base = value * factor + offset
+type unit struct {
+	dimension string
+	factor    float64
+	offset    float64
+	symbol    string
+	ucum      string
+}
+
+type prefix struct {
+	symbol  string
+	ucum    string
+	factor  float64
+	aliases []string
+}
+
+var (
+	siSmall = []prefix{
+		{symbol: "n", ucum: "n", factor: 1e-9},
+		{symbol: "u", ucum: "u", factor: 1e-6, aliases: []string{"µ", "μ"}},
+		{symbol: "m", ucum: "m", factor: 1e-3},
+	}
+	siLarge = []prefix{
+		{symbol: "k", ucum: "k", factor: 1e3},
+		{symbol: "M", ucum: "M", factor: 1e6},
+		{symbol: "G", ucum: "G", factor: 1e9},
+		{symbol: "T", ucum: "T", factor: 1e12},
+		{symbol: "P", ucum: "P", factor: 1e15},
+	}
+	siPrefixes     = append(append([]prefix{}, siSmall...), siLarge...)
+	binaryPrefixes = []prefix{
+		{symbol: "Ki", ucum: "Ki", factor: 1 << 10},
+		{symbol: "Mi", ucum: "Mi", factor: 1 << 20},
+		{symbol: "Gi", ucum: "Gi", factor: 1 << 30},
+		{symbol: "Ti", ucum: "Ti", factor: 1 << 40},
+		{symbol: "Pi", ucum: "Pi", factor: 1 << 50},
+	}
+)
+
+// registry contains all known units by symbol and alias
+var registry = make(map[string]*unit)
+
+func init() {
+	// Temperature with Kelvin as base unit
+	register(&unit{dimension: "temperature", factor: 1, symbol: "K", ucum: "K"}, "kelvin")
+	register(&unit{dimension: "temperature", factor: 1, offset: 273.15, symbol: "degC", ucum: "Cel"},
+		"°C", "C", "celsius", "degrees_c", "deg_c")
+	register(&unit{dimension: "temperature", factor: 5.0 / 9.0, offset: 273.15 - 32*5.0/9.0, symbol: "degF", ucum: "[degF]"},
+		"°F", "F", "fahrenheit", "degrees_f", "deg_f")
+
+	// Time with seconds as base unit
+	registerPrefixed(&unit{dimension: "time", factor: 1, symbol: "s", ucum: "s"}, siSmall, "seconds", "second", "sec")
+	register(&unit{dimension: "time", factor: 60, symbol: "min", ucum: "min"}, "minutes", "minute")
+	register(&unit{dimension: "time", factor: 3600, symbol: "h", ucum: "h"}, "hours", "hour")
+	register(&unit{dimension: "time", factor: 86400, symbol: "d", ucum: "d"}, "days", "day")
+	register(registry["ms"], "milliseconds", "millisecond")
+	register(registry["us"], "microseconds", "microsecond")
+	register(registry["ns"], "nanoseconds", "nanosecond")
+
+	// Information with bits as base unit
+	registerPrefixed(&unit{dimension: "information", factor: 1, symbol: "bit", ucum: "bit"}, siLarge, "bits")
+	registerPrefixed(&unit{dimension: "information", factor: 8, symbol: "B", ucum: "By"}, siLarge, "bytes", "byte")
+	registerPrefixed(registry["bit"], binaryPrefixes)
+	registerPrefixed(registry["B"], binaryPrefixes)
+
+	// Dimensionless ratios
+	register(&unit{dimension: "ratio", factor: 1, symbol: "1", ucum: "1"}, "ratio")
+	register(&unit{dimension: "ratio", factor: 0.01, symbol: "%", ucum: "%"}, "percent")
+
+	// Frequencies are ratios per time for being able to express rates
+	registerPrefixed(&unit{dimension: "ratio/time", factor: 1, symbol: "Hz", ucum: "Hz"}, siPrefixes, "hertz")
+	register(&unit{dimension: "ratio/time", factor: 1.0 / 60.0, symbol: "rpm", ucum: "{rev}/min"}, "RPM")
+
+	// Other SI units
+	registerPrefixed(&unit{dimension: "length", factor: 1, symbol: "m", ucum: "m"}, siPrefixes, "meters", "metres")
+	registerPrefixed(&unit{dimension: "voltage", factor: 1, symbol: "V", ucum: "V"}, siPrefixes, "volts", "volt")
+	registerPrefixed(&unit{dimension: "current", factor: 1, symbol: "A", ucum: "A"}, siPrefixes, "amps", "amperes")
+	registerPrefixed(&unit{dimension: "power", factor: 1, symbol: "W", ucum: "W"}, siPrefixes, "watts", "watt")
+	registerPrefixed(&unit{dimension: "energy", factor: 1, symbol: "J", ucum: "J"}, siPrefixes, "joules", "joule")
+	registerPrefixed(&unit{dimension: "energy", factor: 3600, symbol: "Wh", ucum: "W.h"}, siLarge)
+	registerPrefixed(&unit{dimension: "pressure", factor: 1, symbol: "Pa", ucum: "Pa"}, siPrefixes, "pascal")
+
+	// Common rate notations
+	for _, p := range append([]prefix{{factor: 1}}, siLarge...) {
+		bps := registry[p.symbol+"bit"]
+		register(&unit{
+			dimension: "information/time",
+			factor:    bps.factor,
+			symbol:    bps.symbol + "/s",
+			ucum:      bps.ucum + "/s",
+		}, p.symbol+"bps")
+	}
+}
+
+// register adds the unit with its symbol and the given aliases
+func register(u *unit, aliases ...string) {
+	for _, name := range append([]string{u.symbol}, aliases...) {
+		if _, found := registry[name]; found && registry[name] != u {
+			panic(fmt.Sprintf("duplicate unit %q", name))
+		}
+		registry[name] = u
+	}
+}
+
+// registerPrefixed adds the unit and all prefixed variants
+func registerPrefixed(u *unit, prefixes []prefix, aliases ...string) {
+	if _, found := registry[u.symbol]; !found {
+		register(u, aliases...)
+	}
+	for _, p := range prefixes {
+		prefixed := &unit{
+			dimension: u.dimension,
+			factor:    u.factor * p.factor,
+			symbol:    p.symbol + u.symbol,
+			ucum:      p.ucum + u.ucum,
+		}
+		register(prefixed)
+		for _, alias := range p.aliases {
+			register(prefixed, alias+u.symbol)
+		}
+	}
+}
+
+// lookup returns the unit for the given symbol or alias. Units not in the
+// registry can be specified as quotient of two units like "kB/s".
+func lookup(name string) (*unit, error) {
+	name = strings.TrimSpace(name)
+	if u, found := registry[name]; found {
+		return u, nil
+	}
+	if u, found := registry[strings.ToLower(name)]; found {
+		return u, nil
+	}
+
+	numerator, denominator, found := strings.Cut(name, "/")
+	if !found {
+		return nil, fmt.Errorf("unknown unit %q", name)
+	}
+	n, err := lookup(numerator)
+	if err != nil {
+		return nil, err
+	}
+	d, err := lookup(denominator)
+	if err != nil {
+		return nil, err
+	}
+	if n.offset != 0 || d.offset != 0 || strings.Contains(d.dimension, "/") {
+		return nil, fmt.Errorf("unsupported unit %q", name)
+	}
+	return &unit{
+		dimension: n.dimension + "/" + d.dimension,
+		factor:    n.factor / d.factor,
+		symbol:    n.symbol + "/" + d.symbol,
+		ucum:      n.ucum + "/" + d.ucum,
+	}, nil
+}
+
+// convert returns the value given in the "from" unit in the "to" unit
+func convert(value float64, from, to *unit) float64 {
+	return (value*from.factor + from.offset - to.offset) / to.factor
+}
@@ -0,0 +1,164 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package units
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Units struct {
+	Conversions []*conversion   `toml:"conversion"`
+	UnitFormat  string          `toml:"unit_format"`
+	Log         telegraf.Logger `toml:"-"`
+}
+
+type conversion struct {
+	Fields  []string `toml:"fields"`
+	From    string   `toml:"from"`
+	FromTag string   `toml:"from_tag"`
+	To      string   `toml:"to"`
+	UnitTag string   `toml:"unit_tag"`
+
+	fieldFilter filter.Filter
+	from        *unit
+	to          *unit
+}
+
+func (*Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (u *Units) Init() error {
+	if len(u.Conversions) == 0 {
+		return errors.New("no conversion given")
+	}
+
+	switch u.UnitFormat {
+	case "":
+		u.UnitFormat = "symbol"
+	case "symbol", "ucum":
+	default:
+		return fmt.Errorf("invalid unit_format %q", u.UnitFormat)
+	}
+
+	for i, c := range u.Conversions {
+		if err := c.init(); err != nil {
+			return fmt.Errorf("conversion %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (c *conversion) init() error {
+	if len(c.Fields) == 0 {
+		return errors.New("no fields given")
+	}
+	if c.From == "" && c.FromTag == "" {
+		return errors.New("either 'from' or 'from_tag' must be given")
+	}
+	if c.From != "" && c.FromTag != "" {
+		return errors.New("'from' and 'from_tag' cannot be used at the same time")
+	}
+	if c.To == "" {
+		return errors.New("no target unit given")
+	}
+
+	var err error
+	if c.to, err = lookup(c.To); err != nil {
+		return err
+	}
+	if c.From != "" {
+		if c.from, err = lookup(c.From); err != nil {
+			return err
+		}
+		if c.from.dimension != c.to.dimension {
+			return fmt.Errorf("cannot convert %q (%s) to %q (%s)", c.From, c.from.dimension, c.To, c.to.dimension)
+		}
+	}
+
+	if c.fieldFilter, err = filter.Compile(c.Fields); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		for _, c := range u.Conversions {
+			u.apply(m, c)
+		}
+	}
+	return in
+}
+
+func (u *Units) apply(m telegraf.Metric, c *conversion) {
+	from := c.from
+	if from == nil {
+		name, found := m.GetTag(c.FromTag)
+		if !found {
+			return
+		}
+		var err error
+		if from, err = lookup(name); err != nil {
+			u.Log.Debugf("Skipping metric %q: %v", m.Name(), err)
+			return
+		}
+		if from.dimension != c.to.dimension {
+			u.Log.Debugf("Skipping metric %q: cannot convert %q (%s) to %q (%s)", m.Name(), name, from.dimension, c.To, c.to.dimension)
+			return
+		}
+	}
+
+	var converted bool
+	for _, field := range m.FieldList() {
+		if !c.fieldFilter.Match(field.Key) {
+			continue
+		}
+
+		var v float64
+		switch value := field.Value.(type) {
+		case float64:
+			v = value
+		case int64:
+			v = float64(value)
+		case uint64:
+			v = float64(value)
+		default:
+			u.Log.Debugf("Skipping non-numeric field %q of metric %q", field.Key, m.Name())
+			continue
+		}
+		m.AddField(field.Key, convert(v, from, c.to))
+		converted = true
+	}
+	if !converted {
+		return
+	}
+
+	// Keep the unit tag consistent with the converted values
+	tag := c.UnitTag
+	if tag == "" {
+		tag = c.FromTag
+	}
+	if tag == "" {
+		return
+	}
+	if u.UnitFormat == "ucum" {
+		m.AddTag(tag, c.to.ucum)
+	} else {
+		m.AddTag(tag, c.to.symbol)
+	}
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{}
+	})
+}
@@ -0,0 +1,201 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestLookupConvert(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		value    float64
+		expected float64
+	}{
+		{from: "degF", to: "degC", value: 212, expected: 100},
+		{from: "degrees_c", to: "degF", value: -40, expected: -40},
+		{from: "°C", to: "K", value: 0, expected: 273.15},
+		{from: "ms", to: "s", value: 1500, expected: 1.5},
+		{from: "h", to: "min", value: 2, expected: 120},
+		{from: "µs", to: "ns", value: 1, expected: 1000},
+		{from: "B", to: "bit", value: 3, expected: 24},
+		{from: "MiB", to: "KiB", value: 1, expected: 1024},
+		{from: "kB", to: "B", value: 1, expected: 1000},
+		{from: "bytes", to: "GiB", value: 1 << 30, expected: 1},
+		{from: "Mbps", to: "kB/s", value: 8, expected: 1000},
+		{from: "B/ms", to: "B/s", value: 1, expected: 1000},
+		{from: "rpm", to: "Hz", value: 120, expected: 2},
+		{from: "1/min", to: "1/s", value: 60, expected: 1},
+		{from: "percent", to: "1", value: 42, expected: 0.42},
+		{from: "mV", to: "Volts", value: 1200, expected: 1.2},
+		{from: "kWh", to: "J", value: 1, expected: 3.6e6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			from, err := lookup(tt.from)
+			require.NoError(t, err)
+			to, err := lookup(tt.to)
+			require.NoError(t, err)
+			require.Equal(t, from.dimension, to.dimension)
+			require.InDelta(t, tt.expected, convert(tt.value, from, to), 1e-9)
+		})
+	}
+}
+
+func TestLookupFail(t *testing.T) {
+	_, err := lookup("furlong")
+	require.ErrorContains(t, err, `unknown unit "furlong"`)
+
+	_, err = lookup("degC/s")
+	require.ErrorContains(t, err, `unsupported unit "degC/s"`)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		conversion *conversion
+		format     string
+		expected   string
+	}{
+		{
+			name:       "no fields",
+			conversion: &conversion{From: "s", To: "ms"},
+			expected:   "no fields given",
+		},
+		{
+			name:       "no source unit",
+			conversion: &conversion{Fields: []string{"*"}, To: "ms"},
+			expected:   "either 'from' or 'from_tag' must be given",
+		},
+		{
+			name:       "both source units",
+			conversion: &conversion{Fields: []string{"*"}, From: "s", FromTag: "unit", To: "ms"},
+			expected:   "'from' and 'from_tag' cannot be used at the same time",
+		},
+		{
+			name:       "unknown unit",
+			conversion: &conversion{Fields: []string{"*"}, From: "s", To: "fortnight"},
+			expected:   `unknown unit "fortnight"`,
+		},
+		{
+			name:       "incompatible units",
+			conversion: &conversion{Fields: []string{"*"}, From: "s", To: "B"},
+			expected:   `cannot convert "s" (time) to "B" (information)`,
+		},
+		{
+			name:       "invalid format",
+			conversion: &conversion{Fields: []string{"*"}, From: "s", To: "ms"},
+			format:     "long",
+			expected:   `invalid unit_format "long"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Units{
+				Conversions: []*conversion{tt.conversion},
+				UnitFormat:  tt.format,
+				Log:         &testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		conversion *conversion
+		format     string
+		input      telegraf.Metric
+		expected   telegraf.Metric
+	}{
+		{
+			name:       "fixed unit",
+			conversion: &conversion{Fields: []string{"temp*"}, From: "degF", To: "degC"},
+			input: metric.New(
+				"sensors",
+				map[string]string{},
+				map[string]interface{}{"temp_input": 212.0, "temp_max": int64(32), "fan": 1000.0},
+				time.Unix(0, 0),
+			),
+			expected: metric.New(
+				"sensors",
+				map[string]string{},
+				map[string]interface{}{"temp_input": 100.0, "temp_max": 0.0, "fan": 1000.0},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:       "unit tag",
+			conversion: &conversion{Fields: []string{"*"}, From: "KiB", To: "B", UnitTag: "unit"},
+			format:     "ucum",
+			input: metric.New(
+				"mem",
+				map[string]string{},
+				map[string]interface{}{"free": uint64(2), "state": "ok"},
+				time.Unix(0, 0),
+			),
+			expected: metric.New(
+				"mem",
+				map[string]string{"unit": "By"},
+				map[string]interface{}{"free": 2048.0, "state": "ok"},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:       "unit from tag",
+			conversion: &conversion{Fields: []string{"value"}, FromTag: "unit", To: "degC"},
+			input: metric.New(
+				"ipmi_sensor",
+				map[string]string{"unit": "degrees_f"},
+				map[string]interface{}{"value": 50.0},
+				time.Unix(0, 0),
+			),
+			expected: metric.New(
+				"ipmi_sensor",
+				map[string]string{"unit": "degC"},
+				map[string]interface{}{"value": 10.0},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:       "incompatible unit from tag",
+			conversion: &conversion{Fields: []string{"value"}, FromTag: "unit", To: "degC"},
+			input: metric.New(
+				"ipmi_sensor",
+				map[string]string{"unit": "volts"},
+				map[string]interface{}{"value": 12.0},
+				time.Unix(0, 0),
+			),
+			expected: metric.New(
+				"ipmi_sensor",
+				map[string]string{"unit": "volts"},
+				map[string]interface{}{"value": 12.0},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Units{
+				Conversions: []*conversion{tt.conversion},
+				UnitFormat:  tt.format,
+				Log:         &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual, cmpopts.EquateApprox(0, 1e-9))
+		})
+	}
+}
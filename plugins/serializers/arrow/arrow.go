@@ -0,0 +1,86 @@
+package arrow
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/influxdata/telegraf"
+	common_arrow "github.com/influxdata/telegraf/plugins/common/arrow"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// Serializer encodes metric batches in Apache Arrow IPC stream format
+type Serializer struct {
+	MeasurementColumn string `toml:"arrow_measurement_column"`
+	TimestampColumn   string `toml:"arrow_timestamp_column"`
+	Compression       string `toml:"arrow_compression"`
+
+	converter *common_arrow.Converter
+	options   []ipc.Option
+}
+
+func (s *Serializer) Init() error {
+	switch s.Compression {
+	case "", "none":
+	case "lz4":
+		s.options = append(s.options, ipc.WithLZ4())
+	case "zstd":
+		s.options = append(s.options, ipc.WithZstd())
+	default:
+		return fmt.Errorf("invalid arrow_compression %q", s.Compression)
+	}
+
+	s.converter = &common_arrow.Converter{
+		MeasurementColumn: s.MeasurementColumn,
+		TimestampColumn:   s.TimestampColumn,
+		TimestampType:     &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"},
+	}
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch outputs an IPC stream containing the schema inferred from
+// the metrics and a single record batch holding all metrics
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	schema := s.converter.Schema(metrics)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+
+	record, err := s.converter.Record(builder, metrics)
+	if err != nil {
+		return nil, fmt.Errorf("creating record failed: %w", err)
+	}
+	defer record.Release()
+
+	var buf bytes.Buffer
+	options := append([]ipc.Option{ipc.WithSchema(schema)}, s.options...)
+	writer := ipc.NewWriter(&buf, options...)
+	if err := writer.Write(record); err != nil {
+		return nil, fmt.Errorf("writing record failed: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("closing stream failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func init() {
+	serializers.Add("arrow",
+		func() telegraf.Serializer {
+			return &Serializer{
+				MeasurementColumn: "measurement",
+				TimestampColumn:   "timestamp",
+			}
+		},
+	)
+}
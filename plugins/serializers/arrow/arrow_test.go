@@ -0,0 +1,99 @@
+package arrow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	parsers_arrow "github.com/influxdata/telegraf/plugins/parsers/arrow"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func input() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5, "count": int64(3), "ok": true},
+			time.Unix(1700000000, 123456789),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "server02"},
+			map[string]interface{}{"free": uint64(1024), "status": "fine"},
+			time.Unix(1700000001, 0),
+		),
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	serializer := &Serializer{Compression: "gzip"}
+	require.ErrorContains(t, serializer.Init(), `invalid arrow_compression "gzip"`)
+}
+
+func TestSchema(t *testing.T) {
+	serializer := &Serializer{MeasurementColumn: "measurement", TimestampColumn: "timestamp"}
+	require.NoError(t, serializer.Init())
+
+	buf, err := serializer.SerializeBatch(input())
+	require.NoError(t, err)
+
+	reader, err := ipc.NewReader(bytes.NewReader(buf))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	names := make([]string, 0, reader.Schema().NumFields())
+	for _, field := range reader.Schema().Fields() {
+		names = append(names, field.Name)
+	}
+	expected := []string{"measurement", "count", "cpu", "free", "host", "ok", "status", "usage_idle", "timestamp"}
+	require.Equal(t, expected, names)
+
+	field, _ := reader.Schema().FieldsByName("timestamp")
+	require.Equal(t, arrow.TIMESTAMP, field[0].Type.ID())
+
+	require.True(t, reader.Next())
+	require.EqualValues(t, 2, reader.Record().NumRows())
+	require.False(t, reader.Next())
+}
+
+func TestRoundtrip(t *testing.T) {
+	for _, compression := range []string{"", "lz4", "zstd"} {
+		t.Run(compression, func(t *testing.T) {
+			serializer := &Serializer{
+				MeasurementColumn: "measurement",
+				TimestampColumn:   "timestamp",
+				Compression:       compression,
+			}
+			require.NoError(t, serializer.Init())
+
+			buf, err := serializer.SerializeBatch(input())
+			require.NoError(t, err)
+
+			parser := &parsers_arrow.Parser{}
+			require.NoError(t, parser.Init())
+			actual, err := parser.Parse(buf)
+			require.NoError(t, err)
+
+			testutil.RequireMetricsEqual(t, input(), actual)
+		})
+	}
+}
+
+func TestSerializeTypeConflict(t *testing.T) {
+	serializer := &Serializer{MeasurementColumn: "measurement", TimestampColumn: "timestamp"}
+	require.NoError(t, serializer.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.5}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": "high"}, time.Unix(0, 0)),
+	}
+	_, err := serializer.SerializeBatch(metrics)
+	require.ErrorContains(t, err, `column "value": value high of type string does not match column type float64`)
+}
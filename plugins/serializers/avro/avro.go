@@ -0,0 +1,515 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+type Serializer struct {
+	SchemaRegistry   string          `toml:"avro_schema_registry"`
+	CaCertPath       string          `toml:"avro_schema_registry_cert"`
+	Subject          string          `toml:"avro_schema_subject"`
+	AutoRegister     *bool           `toml:"avro_schema_auto_register"`
+	Schema           string          `toml:"avro_schema"`
+	Namespace        string          `toml:"avro_namespace"`
+	Format           string          `toml:"avro_format"`
+	MeasurementField string          `toml:"avro_measurement_field"`
+	Timestamp        string          `toml:"avro_timestamp"`
+	TimestampFormat  string          `toml:"avro_timestamp_format"`
+	Log              telegraf.Logger `toml:"-"`
+
+	registry *schemaregistry.Registry
+	schema   *schema
+	schemas  map[string]*schema
+	sync.Mutex
+}
+
+// schema holds a record schema with its codec and, if a registry is used, the
+// ID of the schema in the registry
+type schema struct {
+	name   string
+	text   string
+	codec  *goavro.Codec
+	fields []recordField
+	id     int
+}
+
+// recordField is a field of the record schema and the metric data to fill in
+type recordField struct {
+	name       string
+	key        string
+	source     string
+	typ        interface{}
+	hasDefault bool
+	def        interface{}
+}
+
+const (
+	sourceAny   = ""
+	sourceName  = "name"
+	sourceTime  = "time"
+	sourceTag   = "tag"
+	sourceField = "field"
+)
+
+func (s *Serializer) Init() error {
+	switch s.Format {
+	case "":
+		s.Format = "binary"
+	case "binary", "json":
+	default:
+		return fmt.Errorf("unknown 'avro_format' %q", s.Format)
+	}
+
+	switch s.TimestampFormat {
+	case "":
+		s.TimestampFormat = "unix_ns"
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+	default:
+		return fmt.Errorf("invalid timestamp format %q", s.TimestampFormat)
+	}
+
+	if s.Timestamp == "" {
+		s.Timestamp = "timestamp"
+	}
+
+	if s.AutoRegister == nil {
+		autoRegister := true
+		s.AutoRegister = &autoRegister
+	}
+
+	if s.SchemaRegistry != "" {
+		if s.Format != "binary" {
+			return errors.New("schema registry requires 'binary' format")
+		}
+		registry, err := schemaregistry.New(s.SchemaRegistry, s.CaCertPath)
+		if err != nil {
+			return fmt.Errorf("error connecting to the schema registry %q: %w", s.SchemaRegistry, err)
+		}
+		s.registry = registry
+	}
+
+	if s.Schema != "" {
+		sc, err := newSchema(s.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid schema: %w", err)
+		}
+		s.schema = sc
+	}
+	s.schemas = make(map[string]*schema)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.serialize(nil, metric)
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, m := range metrics {
+		var err error
+		if buf, err = s.serialize(buf, m); err != nil {
+			return nil, err
+		}
+		if s.Format == "json" {
+			buf = append(buf, '\n')
+		}
+	}
+	return buf, nil
+}
+
+func (s *Serializer) serialize(buf []byte, m telegraf.Metric) ([]byte, error) {
+	sc, err := s.schemaFor(m)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make(map[string]interface{}, len(sc.fields))
+	for _, f := range sc.fields {
+		v, found := s.value(m, f)
+		if !found {
+			if f.hasDefault {
+				continue
+			}
+			v = nil
+		}
+		converted, err := s.convert(f.typ, v)
+		if err != nil {
+			return nil, fmt.Errorf("converting %q of metric %q failed: %w", f.name, m.Name(), err)
+		}
+		record[f.name] = converted
+	}
+
+	if s.Format == "json" {
+		return sc.codec.TextualFromNative(buf, record)
+	}
+
+	// Prefix the message with the Confluent wire-format header
+	if s.registry != nil {
+		buf = append(buf, 0)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(sc.id))
+	}
+	return sc.codec.BinaryFromNative(buf, record)
+}
+
+// schemaFor returns the schema for the metric, either the configured one or
+// a schema derived from the metric's name, tags and fields. Schemas are
+// registered or looked up in the registry on first use.
+func (s *Serializer) schemaFor(m telegraf.Metric) (*schema, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	key := ""
+	if s.schema == nil {
+		key = signature(m)
+	}
+	sc, found := s.schemas[key]
+	if !found {
+		sc = s.schema
+		if sc == nil {
+			var err error
+			if sc, err = s.derive(m); err != nil {
+				return nil, err
+			}
+		}
+
+		if s.registry != nil {
+			subject := s.Subject
+			if subject == "" {
+				subject = sc.name
+			}
+			var err error
+			if *s.AutoRegister {
+				sc.id, err = s.registry.Register(subject, sc.text)
+			} else {
+				sc.id, err = s.registry.Lookup(subject, sc.text)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("getting schema ID for subject %q failed: %w", subject, err)
+			}
+		}
+		s.schemas[key] = sc
+	}
+	return sc, nil
+}
+
+// derive creates a record schema for the metric containing the timestamp, the
+// name if configured, and all tags and fields as optional values
+func (s *Serializer) derive(m telegraf.Metric) (*schema, error) {
+	var definitions []map[string]interface{}
+	var fields []recordField
+	names := make(map[string]bool)
+	add := func(f recordField, optional bool) error {
+		if names[f.name] {
+			return fmt.Errorf("duplicate field %q in schema for metric %q", f.name, m.Name())
+		}
+		names[f.name] = true
+		definition := map[string]interface{}{"name": f.name, "type": f.typ}
+		if optional {
+			f.typ = []interface{}{"null", f.typ}
+			f.hasDefault = true
+			definition["type"] = f.typ
+			definition["default"] = nil
+		}
+		definitions = append(definitions, definition)
+		fields = append(fields, f)
+		return nil
+	}
+
+	if s.MeasurementField != "" {
+		if err := add(recordField{name: s.MeasurementField, source: sourceName, typ: "string"}, false); err != nil {
+			return nil, err
+		}
+	}
+	if err := add(recordField{name: s.Timestamp, source: sourceTime, typ: "long"}, false); err != nil {
+		return nil, err
+	}
+	for _, tag := range m.TagList() {
+		f := recordField{name: avroName(tag.Key), key: tag.Key, source: sourceTag, typ: "string"}
+		if err := add(f, true); err != nil {
+			return nil, err
+		}
+	}
+	fieldList := m.FieldList()
+	sort.Slice(fieldList, func(i, j int) bool { return fieldList[i].Key < fieldList[j].Key })
+	for _, field := range fieldList {
+		var typ string
+		switch field.Value.(type) {
+		case float64:
+			typ = "double"
+		case int64, uint64:
+			typ = "long"
+		case bool:
+			typ = "boolean"
+		case string:
+			typ = "string"
+		default:
+			return nil, fmt.Errorf("unsupported type %T of field %q", field.Value, field.Key)
+		}
+		f := recordField{name: avroName(field.Key), key: field.Key, source: sourceField, typ: typ}
+		if err := add(f, true); err != nil {
+			return nil, err
+		}
+	}
+
+	name := avroName(m.Name())
+	record := map[string]interface{}{
+		"type":   "record",
+		"name":   name,
+		"fields": definitions,
+	}
+	if s.Namespace != "" {
+		record["namespace"] = s.Namespace
+		name = s.Namespace + "." + name
+	}
+	text, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := goavro.NewCodec(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("deriving schema for metric %q failed: %w", m.Name(), err)
+	}
+
+	return &schema{
+		name:   name,
+		text:   string(text),
+		codec:  codec,
+		fields: fields,
+	}, nil
+}
+
+// value returns the metric data for the given record field
+func (s *Serializer) value(m telegraf.Metric, f recordField) (interface{}, bool) {
+	switch f.source {
+	case sourceName:
+		return m.Name(), true
+	case sourceTime:
+		return m.Time(), true
+	case sourceTag:
+		return m.GetTag(f.key)
+	case sourceField:
+		return m.GetField(f.key)
+	}
+
+	// Configured schemas are matched by name
+	switch f.name {
+	case s.MeasurementField:
+		return m.Name(), true
+	case s.Timestamp:
+		return m.Time(), true
+	}
+	if v, found := m.GetTag(f.name); found {
+		return v, true
+	}
+	return m.GetField(f.name)
+}
+
+// timestamp converts the time to an integer in the configured format
+func (s *Serializer) timestamp(t time.Time) int64 {
+	switch s.TimestampFormat {
+	case "unix":
+		return t.Unix()
+	case "unix_ms":
+		return t.UnixMilli()
+	case "unix_us":
+		return t.UnixMicro()
+	}
+	return t.UnixNano()
+}
+
+func newSchema(text string) (*schema, error) {
+	codec, err := goavro.NewCodec(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition struct {
+		Type      string                   `json:"type"`
+		Name      string                   `json:"name"`
+		Namespace string                   `json:"namespace"`
+		Fields    []map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(text), &definition); err != nil {
+		return nil, err
+	}
+	if definition.Type != "record" {
+		return nil, fmt.Errorf("schema type must be 'record' but is %q", definition.Type)
+	}
+
+	name := definition.Name
+	if definition.Namespace != "" && !strings.Contains(name, ".") {
+		name = definition.Namespace + "." + name
+	}
+
+	fields := make([]recordField, 0, len(definition.Fields))
+	for _, f := range definition.Fields {
+		rf := recordField{typ: f["type"]}
+		rf.name, _ = f["name"].(string)
+		rf.def, rf.hasDefault = f["default"]
+		fields = append(fields, rf)
+	}
+
+	return &schema{
+		name:   name,
+		text:   text,
+		codec:  codec,
+		fields: fields,
+	}, nil
+}
+
+// signature identifies the derived schema of a metric
+func signature(m telegraf.Metric) string {
+	var b strings.Builder
+	b.WriteString(m.Name())
+	for _, tag := range m.TagList() {
+		b.WriteString("\x00t" + tag.Key)
+	}
+	fieldList := m.FieldList()
+	keys := make([]string, 0, len(fieldList))
+	for _, field := range fieldList {
+		keys = append(keys, fmt.Sprintf("\x00f%s:%T", field.Key, field.Value))
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(k)
+	}
+	return b.String()
+}
+
+// avroName converts the given name to a valid Avro name
+func avroName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+func init() {
+	serializers.Add("avro",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
+
+// convert converts the value to the native Go type expected by the codec for
+// the given Avro type
+func (s *Serializer) convert(typ, v interface{}) (interface{}, error) {
+	switch t := typ.(type) {
+	case string:
+		return s.convertPrimitive(t, "", v)
+	case map[string]interface{}:
+		name, _ := t["type"].(string)
+		switch name {
+		case "enum":
+			return internal.ToString(v)
+		case "record", "array", "map", "fixed":
+			return nil, fmt.Errorf("unsupported type %q", name)
+		}
+		logicalType, _ := t["logicalType"].(string)
+		return s.convertPrimitive(name, logicalType, v)
+	case []interface{}:
+		if v == nil {
+			for _, branch := range t {
+				if branch == "null" {
+					return nil, nil
+				}
+			}
+			return nil, errors.New("missing value for non-nullable type")
+		}
+		var errs []error
+		for _, branch := range t {
+			if branch == "null" {
+				continue
+			}
+			converted, err := s.convert(branch, v)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			return goavro.Union(unionName(branch), converted), nil
+		}
+		return nil, fmt.Errorf("no matching union type: %w", errors.Join(errs...))
+	}
+	return nil, fmt.Errorf("unsupported type %v", typ)
+}
+
+func (s *Serializer) convertPrimitive(typ, logicalType string, v interface{}) (interface{}, error) {
+	if v == nil {
+		if typ == "null" {
+			return nil, nil
+		}
+		return nil, errors.New("missing value for non-nullable type")
+	}
+
+	// Timestamps are passed as time for logical types and as integer in the
+	// configured format otherwise
+	if t, ok := v.(time.Time); ok {
+		if strings.HasPrefix(logicalType, "timestamp-") || strings.HasPrefix(logicalType, "local-timestamp-") {
+			return t, nil
+		}
+		v = s.timestamp(t)
+	}
+
+	switch typ {
+	case "boolean":
+		return internal.ToBool(v)
+	case "int":
+		return internal.ToInt32(v)
+	case "long":
+		return internal.ToInt64(v)
+	case "float":
+		return internal.ToFloat32(v)
+	case "double":
+		return internal.ToFloat64(v)
+	case "string":
+		return internal.ToString(v)
+	case "bytes":
+		str, err := internal.ToString(v)
+		return []byte(str), err
+	case "null":
+		return nil, errors.New("value for null type")
+	}
+	return nil, fmt.Errorf("unsupported type %q", typ)
+}
+
+// unionName returns the name of the union branch used by the codec
+func unionName(typ interface{}) string {
+	switch t := typ.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		name, _ := t["type"].(string)
+		if logicalType, ok := t["logicalType"].(string); ok {
+			return name + "." + logicalType
+		}
+		if name == "enum" {
+			n, _ := t["name"].(string)
+			if ns, ok := t["namespace"].(string); ok && !strings.Contains(n, ".") {
+				return ns + "." + n
+			}
+			return n
+		}
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,279 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	parsers_avro "github.com/influxdata/telegraf/plugins/parsers/avro"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer *Serializer
+		expected   string
+	}{
+		{
+			name:       "invalid format",
+			serializer: &Serializer{Format: "xml"},
+			expected:   `unknown 'avro_format' "xml"`,
+		},
+		{
+			name:       "invalid timestamp format",
+			serializer: &Serializer{TimestampFormat: "rfc3339"},
+			expected:   `invalid timestamp format "rfc3339"`,
+		},
+		{
+			name:       "registry with json",
+			serializer: &Serializer{Format: "json", SchemaRegistry: "http://localhost:8081"},
+			expected:   "schema registry requires 'binary' format",
+		},
+		{
+			name:       "invalid schema",
+			serializer: &Serializer{Schema: `{"type": "foo"}`},
+			expected:   "invalid schema",
+		},
+		{
+			name:       "non-record schema",
+			serializer: &Serializer{Schema: `"string"`},
+			expected:   "invalid schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.serializer.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDerivedSchema(t *testing.T) {
+	serializer := &Serializer{
+		Format:           "json",
+		Namespace:        "com.example",
+		MeasurementField: "measurement",
+		TimestampFormat:  "unix_ms",
+	}
+	require.NoError(t, serializer.Init())
+
+	m := metric.New(
+		"cpu-load",
+		map[string]string{"host": "a", "data.center": "eu"},
+		map[string]interface{}{"value": 42.5, "count": int64(3), "ok": true, "state": "up"},
+		time.Unix(1700000000, 5e6),
+	)
+	buf, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"measurement": "cpu-load",
+		"timestamp": 1700000000005,
+		"data_center": {"string": "eu"},
+		"host": {"string": "a"},
+		"count": {"long": 3},
+		"ok": {"boolean": true},
+		"state": {"string": "up"},
+		"value": {"double": 42.5}
+	}`, string(buf))
+
+	sc := serializer.schemas[signature(m)]
+	require.Equal(t, "com.example.cpu_load", sc.name)
+	require.JSONEq(t, `{
+		"type": "record",
+		"name": "cpu_load",
+		"namespace": "com.example",
+		"fields": [
+			{"name": "measurement", "type": "string"},
+			{"name": "timestamp", "type": "long"},
+			{"name": "data_center", "type": ["null", "string"], "default": null},
+			{"name": "host", "type": ["null", "string"], "default": null},
+			{"name": "count", "type": ["null", "long"], "default": null},
+			{"name": "ok", "type": ["null", "boolean"], "default": null},
+			{"name": "state", "type": ["null", "string"], "default": null},
+			{"name": "value", "type": ["null", "double"], "default": null}
+		]
+	}`, sc.text)
+
+	// Metrics with the same layout must reuse the schema
+	_, err = serializer.Serialize(m.Copy())
+	require.NoError(t, err)
+	require.Len(t, serializer.schemas, 1)
+}
+
+func TestConfiguredSchema(t *testing.T) {
+	serializer := &Serializer{
+		Format: "json",
+		Schema: `{
+			"type": "record",
+			"name": "reading",
+			"fields": [
+				{"name": "time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+				{"name": "sensor", "type": "string"},
+				{"name": "level", "type": {"type": "enum", "name": "level", "symbols": ["low", "high"]}},
+				{"name": "value", "type": "float"},
+				{"name": "count", "type": "int"},
+				{"name": "note", "type": ["null", "string"]},
+				{"name": "unit", "type": "string", "default": "Cel"}
+			]
+		}`,
+		Timestamp: "time",
+	}
+	require.NoError(t, serializer.Init())
+
+	m := metric.New(
+		"reading",
+		map[string]string{"sensor": "s1", "level": "low"},
+		map[string]interface{}{"value": 21.5, "count": uint64(7)},
+		time.Unix(1700000000, 0),
+	)
+	buf, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"time": 1700000000000,
+		"sensor": "s1",
+		"level": "low",
+		"value": 21.5,
+		"count": 7,
+		"note": null,
+		"unit": "Cel"
+	}`, string(buf))
+
+	// Missing required values must fail
+	m.RemoveTag("sensor")
+	_, err = serializer.Serialize(m)
+	require.ErrorContains(t, err, `converting "sensor" of metric "reading" failed`)
+}
+
+func TestBatchJSON(t *testing.T) {
+	serializer := &Serializer{Format: "json", TimestampFormat: "unix"}
+	require.NoError(t, serializer.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("a", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(1, 0)),
+		metric.New("b", map[string]string{}, map[string]interface{}{"value": "x"}, time.Unix(2, 0)),
+	}
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+	require.Len(t, lines, 2)
+	require.JSONEq(t, `{"timestamp":1,"value":{"double":1}}`, lines[0])
+	require.JSONEq(t, `{"timestamp":2,"value":{"string":"x"}}`, lines[1])
+}
+
+// registry is a minimal Confluent-compatible schema registry for testing
+type registry struct {
+	schemas  []string
+	subjects map[string]int
+	sync.Mutex
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+
+	var id int
+	if n, _ := fmt.Sscanf(req.URL.Path, "/schemas/ids/%d", &id); n == 1 && req.Method == http.MethodGet {
+		if id < 1 || id > len(r.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"schema": r.schemas[id-1]})
+		return
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	var request struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	subject, found := strings.CutPrefix(req.URL.Path, "/subjects/")
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	subject, register := strings.CutSuffix(subject, "/versions")
+	key := subject + "\x00" + request.Schema
+	if id, found := r.subjects[key]; found {
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+		return
+	}
+	if !register {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		return
+	}
+	r.schemas = append(r.schemas, request.Schema)
+	r.subjects[key] = len(r.schemas)
+	_ = json.NewEncoder(w).Encode(map[string]int{"id": len(r.schemas)})
+}
+
+func TestRegistry(t *testing.T) {
+	reg := &registry{subjects: make(map[string]int)}
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	serializer := &Serializer{
+		SchemaRegistry:   server.URL,
+		Namespace:        "telegraf",
+		MeasurementField: "name",
+	}
+	require.NoError(t, serializer.Init())
+
+	parser := &parsers_avro.Parser{
+		SchemaRegistry:   server.URL,
+		MeasurementField: "name",
+		Tags:             []string{"host"},
+		Fields:           []string{"usage", "free"},
+		Timestamp:        "timestamp",
+		TimestampFormat:  "unix_ns",
+		UnionMode:        "nullable",
+	}
+	require.NoError(t, parser.Init())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5}, time.Unix(1700000000, 123)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"free": int64(1024)}, time.Unix(1700000001, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 23.0}, time.Unix(1700000002, 0)),
+	}
+	for i, m := range input {
+		buf, err := serializer.Serialize(m)
+		require.NoError(t, err)
+
+		// Check the wire-format header
+		require.Equal(t, byte(0), buf[0])
+		expectedID := map[string]uint32{"cpu": 1, "mem": 2}[m.Name()]
+		require.Equal(t, expectedID, binary.BigEndian.Uint32(buf[1:5]), "wrong schema ID for metric %d", i)
+
+		actual, err := parser.Parse(buf)
+		require.NoError(t, err)
+		testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, actual)
+	}
+	require.Len(t, reg.schemas, 2)
+	require.Contains(t, reg.subjects, "telegraf.cpu\x00"+reg.schemas[0])
+
+	// Lookup of unregistered schemas must fail
+	autoRegister := false
+	serializer = &Serializer{
+		SchemaRegistry: server.URL,
+		Subject:        "lookup",
+		AutoRegister:   &autoRegister,
+	}
+	require.NoError(t, serializer.Init())
+	_, err := serializer.Serialize(input[0])
+	require.ErrorContains(t, err, `getting schema ID for subject "lookup" failed: schema registry returned status 404`)
+}
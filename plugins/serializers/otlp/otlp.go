@@ -0,0 +1,59 @@
+package otlp
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/influxdata/telegraf"
+	common_otel "github.com/influxdata/telegraf/plugins/common/opentelemetry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// Serializer encodes metrics as OTLP metrics export requests
+type Serializer struct {
+	Format  string          `toml:"otlp_format"`
+	UnitTag string          `toml:"otlp_unit_tag"`
+	Log     telegraf.Logger `toml:"-"`
+
+	converter *common_otel.MetricsConverter
+}
+
+func (s *Serializer) Init() error {
+	switch s.Format {
+	case "":
+		s.Format = "protobuf"
+	case "protobuf", "json":
+	default:
+		return fmt.Errorf("invalid otlp_format %q", s.Format)
+	}
+
+	converter, err := common_otel.NewMetricsConverter(s.Log)
+	if err != nil {
+		return fmt.Errorf("creating converter failed: %w", err)
+	}
+	converter.UnitTag = s.UnitTag
+	s.converter = converter
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	req := pmetricotlp.NewExportRequestFromMetrics(s.converter.Convert(metrics))
+	if s.Format == "json" {
+		return req.MarshalJSON()
+	}
+	return req.MarshalProto()
+}
+
+func init() {
+	serializers.Add("otlp",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
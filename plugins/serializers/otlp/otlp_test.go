@@ -0,0 +1,83 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	serializer := &Serializer{Format: "xml"}
+	require.ErrorContains(t, serializer.Init(), `invalid otlp_format "xml"`)
+}
+
+func expectedMetrics() pmetric.Metrics {
+	expected := pmetric.NewMetrics()
+	rm := expected.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("host.name", "potato")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("My Library Name")
+	m := sm.Metrics().AppendEmpty()
+	m.SetName("cpu_temp")
+	m.SetUnit("Cel")
+	dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.Attributes().PutStr("foo", "bar")
+	dp.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+	dp.SetDoubleValue(87.332)
+	return expected
+}
+
+func input() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New(
+			"cpu_temp",
+			map[string]string{
+				"foo":               "bar",
+				"unit":              "Cel",
+				"otel.library.name": "My Library Name",
+				"host.name":         "potato",
+			},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848686000000000),
+			telegraf.Gauge,
+		),
+	}
+}
+
+func TestSerializeProtobuf(t *testing.T) {
+	serializer := &Serializer{UnitTag: "unit", Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	buf, err := serializer.SerializeBatch(input())
+	require.NoError(t, err)
+
+	req := pmetricotlp.NewExportRequest()
+	require.NoError(t, req.UnmarshalProto(buf))
+
+	var marshaller pmetric.JSONMarshaler
+	expected, err := marshaller.MarshalMetrics(expectedMetrics())
+	require.NoError(t, err)
+	actual, err := marshaller.MarshalMetrics(req.Metrics())
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(actual))
+}
+
+func TestSerializeJSON(t *testing.T) {
+	serializer := &Serializer{Format: "json", UnitTag: "unit", Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	actual, err := serializer.Serialize(input()[0])
+	require.NoError(t, err)
+
+	expected, err := pmetricotlp.NewExportRequestFromMetrics(expectedMetrics()).MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(actual))
+}
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/exphistogram"
 )
 
 const helpString = "Telegraf collected metric"
@@ -54,6 +55,7 @@ type histogram struct {
 	Buckets []bucket
 	Count   uint64
 	Sum     float64
+	Native  *exphistogram.Histogram
 }
 
 func (h *histogram) merge(b bucket) {
@@ -182,6 +184,16 @@ func (c *Collection) createLabels(metric telegraf.Metric) []labelPair {
 
 func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 	labels := c.createLabels(metric)
+
+	// Exponential histograms are described by all fields of the metric, so
+	// convert them as a whole into a native histogram.
+	if metric.Type() == telegraf.Histogram {
+		if h, ok := exphistogram.FromFields(metric.Fields()); ok {
+			c.addNativeHistogram(metric, labels, h, now)
+			return
+		}
+	}
+
 	for _, field := range metric.FieldList() {
 		metricName := MetricName(metric.Name(), field.Key, metric.Type())
 		metricName, ok := SanitizeMetricName(metricName)
@@ -339,6 +351,43 @@ func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 	}
 }
 
+func (c *Collection) addNativeHistogram(metric telegraf.Metric, labels []labelPair, h *exphistogram.Histogram, now time.Time) {
+	metricName, ok := SanitizeMetricName(metric.Name())
+	if !ok {
+		return
+	}
+
+	family := metricFamily{
+		Name: metricName,
+		Type: telegraf.Histogram,
+	}
+
+	singleEntry, ok := c.Entries[family]
+	if !ok {
+		singleEntry = entry{
+			Family:  family,
+			Metrics: make(map[metricKey]*Metric),
+		}
+		c.Entries[family] = singleEntry
+	}
+
+	metricKey := makeMetricKey(labels)
+	if m, ok := singleEntry.Metrics[metricKey]; ok && metric.Time().Before(m.Time) {
+		return
+	}
+
+	singleEntry.Metrics[metricKey] = &Metric{
+		Labels:  labels,
+		Time:    metric.Time(),
+		AddTime: now,
+		Histogram: &histogram{
+			Count:  uint64(h.Count),
+			Sum:    h.Sum,
+			Native: h,
+		},
+	}
+}
+
 func (c *Collection) Expire(now time.Time, age time.Duration) {
 	expireTime := now.Add(-age)
 	for _, entry := range c.Entries {
@@ -458,6 +507,14 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 					SampleCount: proto.Uint64(metric.Histogram.Count),
 					SampleSum:   proto.Float64(metric.Histogram.Sum),
 				}
+				if native := metric.Histogram.Native; native != nil {
+					m.Histogram.Schema = proto.Int32(native.Schema)
+					m.Histogram.ZeroThreshold = proto.Float64(native.ZeroThreshold)
+					m.Histogram.ZeroCountFloat = proto.Float64(native.ZeroCount)
+					m.Histogram.SampleCountFloat = proto.Float64(native.Count)
+					m.Histogram.PositiveSpan, m.Histogram.PositiveCount = nativeBuckets(native.Positive)
+					m.Histogram.NegativeSpan, m.Histogram.NegativeCount = nativeBuckets(native.Negative)
+				}
 			case telegraf.Summary:
 				quantiles := make([]*dto.Quantile, 0, len(metric.Summary.Quantiles))
 				for _, quantile := range metric.Summary.Quantiles {
@@ -486,3 +543,15 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 
 	return result
 }
+
+// nativeBuckets converts the buckets into a single span with absolute counts
+func nativeBuckets(b exphistogram.Buckets) ([]*dto.BucketSpan, []float64) {
+	if len(b.Counts) == 0 {
+		return nil, nil
+	}
+	spans := []*dto.BucketSpan{{
+		Offset: proto.Int32(b.Offset),
+		Length: proto.Uint32(uint32(len(b.Counts))),
+	}}
+	return spans, b.Counts
+}
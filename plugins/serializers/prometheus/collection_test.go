@@ -843,3 +843,63 @@ func TestExportTimestamps(t *testing.T) {
 		})
 	}
 }
+
+func TestNativeHistogram(t *testing.T) {
+	input := testutil.MustMetric(
+		"http_latency",
+		map[string]string{"path": "/"},
+		map[string]interface{}{
+			"count":                  float64(4),
+			"sum":                    float64(3.5),
+			"schema":                 int64(0),
+			"counter_reset_hint":     uint64(0),
+			"zero_threshold":         float64(0),
+			"zero_count":             float64(1),
+			"positive_span_0_offset": int64(1),
+			"positive_span_0_length": uint64(2),
+			"positive_bucket_0":      float64(1),
+			"positive_bucket_1":      float64(1),
+			"negative_span_0_offset": int64(0),
+			"negative_span_0_length": uint64(1),
+			"negative_bucket_0":      float64(1),
+		},
+		time.Unix(0, 0),
+		telegraf.Histogram,
+	)
+
+	expected := []*dto.MetricFamily{
+		{
+			Name: proto.String("http_latency"),
+			Help: proto.String(helpString),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{
+						{Name: proto.String("path"), Value: proto.String("/")},
+					},
+					Histogram: &dto.Histogram{
+						Bucket:           make([]*dto.Bucket, 0),
+						SampleCount:      proto.Uint64(4),
+						SampleCountFloat: proto.Float64(4),
+						SampleSum:        proto.Float64(3.5),
+						Schema:           proto.Int32(0),
+						ZeroThreshold:    proto.Float64(0),
+						ZeroCountFloat:   proto.Float64(1),
+						PositiveSpan: []*dto.BucketSpan{
+							{Offset: proto.Int32(1), Length: proto.Uint32(2)},
+						},
+						PositiveCount: []float64{1, 1},
+						NegativeSpan: []*dto.BucketSpan{
+							{Offset: proto.Int32(0), Length: proto.Uint32(1)},
+						},
+						NegativeCount: []float64{1},
+					},
+				},
+			},
+		},
+	}
+
+	c := NewCollection(FormatConfig{})
+	c.Add(input, time.Unix(0, 0))
+	require.Equal(t, expected, c.GetProto())
+}
@@ -54,6 +54,9 @@ type FormatConfig struct {
 	// helps to reduce payload size.
 	CompactEncoding bool        `toml:"prometheus_compact_encoding"`
 	TypeMappings    MetricTypes `toml:"prometheus_metric_types"`
+	// ExpositionFormat selects between the "text" format and the
+	// length-delimited "protobuf" format able to represent native histograms
+	ExpositionFormat string `toml:"prometheus_exposition_format"`
 }
 
 type Serializer struct {
@@ -61,6 +64,11 @@ type Serializer struct {
 }
 
 func (s *Serializer) Init() error {
+	switch s.ExpositionFormat {
+	case "", "text", "protobuf":
+	default:
+		return fmt.Errorf("invalid prometheus_exposition_format %q", s.ExpositionFormat)
+	}
 	return s.FormatConfig.TypeMappings.Init()
 }
 
@@ -74,9 +82,14 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 		coll.Add(metric, time.Now())
 	}
 
+	format := expfmt.NewFormat(expfmt.TypeTextPlain)
+	if s.ExpositionFormat == "protobuf" {
+		format = expfmt.NewFormat(expfmt.TypeProtoDelim)
+	}
+
 	var buf bytes.Buffer
 	for _, mf := range coll.GetProto() {
-		enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+		enc := expfmt.NewEncoder(&buf, format)
 		err := enc.Encode(mf)
 		if err != nil {
 			return nil, err
@@ -1,10 +1,13 @@
 package prometheus
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -762,6 +765,53 @@ rpc_duration_seconds_count 2693
 	}
 }
 
+func TestSerializeNativeHistogramProtobuf(t *testing.T) {
+	input := testutil.MustMetric(
+		"http_latency",
+		map[string]string{"path": "/"},
+		map[string]interface{}{
+			"count":                  float64(3),
+			"sum":                    float64(3.5),
+			"schema":                 int64(0),
+			"counter_reset_hint":     uint64(0),
+			"zero_threshold":         float64(0),
+			"zero_count":             float64(1),
+			"positive_span_0_offset": int64(1),
+			"positive_span_0_length": uint64(2),
+			"positive_bucket_0":      float64(1),
+			"positive_bucket_1":      float64(1),
+		},
+		time.Unix(0, 0),
+		telegraf.Histogram,
+	)
+
+	s := &Serializer{FormatConfig{ExpositionFormat: "protobuf"}}
+	require.NoError(t, s.Init())
+	buf, err := s.Serialize(input)
+	require.NoError(t, err)
+
+	// The native buckets must be contained in the output
+	var mf dto.MetricFamily
+	dec := expfmt.NewDecoder(bytes.NewReader(buf), expfmt.NewFormat(expfmt.TypeProtoDelim))
+	require.NoError(t, dec.Decode(&mf))
+	require.Equal(t, "http_latency", mf.GetName())
+	require.Len(t, mf.GetMetric(), 1)
+	h := mf.GetMetric()[0].GetHistogram()
+	require.Equal(t, uint64(3), h.GetSampleCount())
+	require.InDelta(t, 3.5, h.GetSampleSum(), 0)
+	require.Equal(t, int32(0), h.GetSchema())
+	require.InDelta(t, 1.0, h.GetZeroCountFloat(), 0)
+	require.Len(t, h.GetPositiveSpan(), 1)
+	require.Equal(t, int32(1), h.GetPositiveSpan()[0].GetOffset())
+	require.Equal(t, uint32(2), h.GetPositiveSpan()[0].GetLength())
+	require.Equal(t, []float64{1, 1}, h.GetPositiveCount())
+}
+
+func TestInvalidExpositionFormat(t *testing.T) {
+	s := &Serializer{FormatConfig{ExpositionFormat: "json"}}
+	require.ErrorContains(t, s.Init(), `invalid prometheus_exposition_format "json"`)
+}
+
 func BenchmarkSerialize(b *testing.B) {
 	s := &Serializer{}
 	require.NoError(b, s.Init())
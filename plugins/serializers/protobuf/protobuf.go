@@ -0,0 +1,331 @@
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_protobuf "github.com/influxdata/telegraf/plugins/common/protobuf"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+const timestampMessage = "google.protobuf.Timestamp"
+
+// Serializer encodes metrics as protocol-buffer messages of a user-defined
+// type by mapping the metric properties onto the message fields
+type Serializer struct {
+	MessageFiles   []string          `toml:"protobuf_files"`
+	MessageType    string            `toml:"protobuf_type"`
+	ImportPaths    []string          `toml:"protobuf_import_paths"`
+	Mapping        map[string]string `toml:"protobuf_mapping"`
+	TimestampUnits config.Duration   `toml:"protobuf_timestamp_units"`
+	Log            telegraf.Logger   `toml:"-"`
+
+	desc     protoreflect.MessageDescriptor
+	mappings []mapping
+}
+
+// mapping describes the metric property to set on the message field found by
+// following the field path
+type mapping struct {
+	path   []protoreflect.FieldDescriptor
+	source string
+	key    string
+}
+
+func (s *Serializer) Init() error {
+	if len(s.MessageFiles) == 0 {
+		return errors.New("protocol-buffer files not set")
+	}
+	if s.MessageType == "" {
+		return errors.New("protocol-buffer message-type not set")
+	}
+	if len(s.Mapping) == 0 {
+		return errors.New("no protobuf_mapping given")
+	}
+
+	// Default precision is 1ns
+	if s.TimestampUnits <= 0 {
+		s.TimestampUnits = config.Duration(time.Nanosecond)
+	}
+
+	registry, err := common_protobuf.Load(s.MessageFiles, s.ImportPaths)
+	if err != nil {
+		return err
+	}
+	if s.desc, err = common_protobuf.FindMessage(registry, s.MessageType, s.Log); err != nil {
+		return err
+	}
+
+	// Resolve the mappings in a stable order
+	fields := make([]string, 0, len(s.Mapping))
+	for field := range s.Mapping {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	s.mappings = make([]mapping, 0, len(fields))
+	for _, field := range fields {
+		m, err := s.resolve(field, s.Mapping[field])
+		if err != nil {
+			return fmt.Errorf("invalid mapping for field %q: %w", field, err)
+		}
+		s.mappings = append(s.mappings, m)
+	}
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	msg := dynamicpb.NewMessage(s.desc)
+	for _, m := range s.mappings {
+		if err := s.apply(msg, m, metric); err != nil {
+			return nil, fmt.Errorf("setting field %q failed: %w", fieldPath(m.path), err)
+		}
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// SerializeBatch outputs the messages of all metrics each prefixed by its
+// length as varint, i.e. as a stream of length-delimited messages
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, metric := range metrics {
+		serialized, err := s.Serialize(metric)
+		if err != nil {
+			return nil, err
+		}
+		buf = protowire.AppendBytes(buf, serialized)
+	}
+	return buf, nil
+}
+
+// resolve looks up the field path in the message descriptor and checks if the
+// field can hold the given source
+func (s *Serializer) resolve(field, source string) (mapping, error) {
+	var m mapping
+	switch {
+	case source == "name", source == "timestamp", source == "tags", source == "fields":
+		m.source = source
+	case strings.HasPrefix(source, "tag:"), strings.HasPrefix(source, "field:"):
+		m.source, m.key, _ = strings.Cut(source, ":")
+		if m.key == "" {
+			return m, fmt.Errorf("empty key in source %q", source)
+		}
+	default:
+		return m, fmt.Errorf("invalid source %q", source)
+	}
+
+	desc := s.desc
+	parts := strings.Split(field, ".")
+	for i, part := range parts {
+		fd := desc.Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			fd = desc.Fields().ByJSONName(part)
+		}
+		if fd == nil {
+			return m, fmt.Errorf("field %q not found in message %q", part, desc.FullName())
+		}
+		m.path = append(m.path, fd)
+
+		if i == len(parts)-1 {
+			break
+		}
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return m, fmt.Errorf("field %q is not a message", part)
+		}
+		desc = fd.Message()
+	}
+
+	fd := m.path[len(m.path)-1]
+	switch {
+	case m.source == "tags" || m.source == "fields":
+		if !fd.IsMap() || fd.MapKey().Kind() != protoreflect.StringKind {
+			return m, fmt.Errorf("source %q requires a map with string keys", m.source)
+		}
+		if m.source == "tags" && fd.MapValue().Kind() != protoreflect.StringKind {
+			return m, errors.New(`source "tags" requires a map with string values`)
+		}
+		if fd.MapValue().Kind() == protoreflect.MessageKind {
+			return m, errors.New("maps with message values are not supported")
+		}
+	case fd.IsList() || fd.IsMap():
+		return m, errors.New("repeated fields are not supported")
+	case fd.Kind() == protoreflect.MessageKind:
+		if m.source != "timestamp" || fd.Message().FullName() != timestampMessage {
+			return m, fmt.Errorf("message fields other than %s are not supported", timestampMessage)
+		}
+	}
+
+	return m, nil
+}
+
+// apply sets the message field of the mapping. Fields of missing tags or
+// fields are left unset.
+func (s *Serializer) apply(msg protoreflect.Message, m mapping, metric telegraf.Metric) error {
+	fd := m.path[len(m.path)-1]
+
+	// Get the value before creating the parent messages to avoid emitting
+	// empty messages for missing tags or fields
+	var value interface{}
+	switch m.source {
+	case "name":
+		value = metric.Name()
+	case "timestamp":
+		value = metric.Time()
+	case "tags":
+		value = metric.Tags()
+	case "fields":
+		value = metric.Fields()
+	case "tag":
+		v, found := metric.GetTag(m.key)
+		if !found {
+			return nil
+		}
+		value = v
+	case "field":
+		v, found := metric.GetField(m.key)
+		if !found {
+			return nil
+		}
+		value = v
+	}
+
+	for _, parent := range m.path[:len(m.path)-1] {
+		msg = msg.Mutable(parent).Message()
+	}
+
+	switch v := value.(type) {
+	case map[string]string:
+		entries := msg.Mutable(fd).Map()
+		for key, raw := range v {
+			pv, err := convert(fd.MapValue(), raw)
+			if err != nil {
+				return fmt.Errorf("converting tag %q failed: %w", key, err)
+			}
+			entries.Set(protoreflect.ValueOfString(key).MapKey(), pv)
+		}
+	case map[string]interface{}:
+		entries := msg.Mutable(fd).Map()
+		for key, raw := range v {
+			pv, err := convert(fd.MapValue(), raw)
+			if err != nil {
+				return fmt.Errorf("converting field %q failed: %w", key, err)
+			}
+			entries.Set(protoreflect.ValueOfString(key).MapKey(), pv)
+		}
+	case time.Time:
+		return s.setTimestamp(msg, fd, v)
+	default:
+		pv, err := convert(fd, v)
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, pv)
+	}
+	return nil
+}
+
+// setTimestamp sets the timestamp depending on the type of the field. Integer
+// fields hold the time since epoch in the configured units, floating-point
+// fields the time since epoch in seconds and string fields the time in RFC3339
+// format.
+func (s *Serializer) setTimestamp(msg protoreflect.Message, fd protoreflect.FieldDescriptor, t time.Time) error {
+	var value interface{}
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		ts := msg.Mutable(fd).Message()
+		fields := ts.Descriptor().Fields()
+		ts.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+		ts.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+		return nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		value = float64(t.UnixNano()) / float64(time.Second)
+	case protoreflect.StringKind:
+		value = t.UTC().Format(time.RFC3339Nano)
+	default:
+		value = t.UnixNano() / int64(s.TimestampUnits)
+	}
+
+	pv, err := convert(fd, value)
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, pv)
+	return nil
+}
+
+// convert converts the value to the type of the given field
+func convert(fd protoreflect.FieldDescriptor, value interface{}) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := internal.ToBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := internal.ToInt32(value)
+		return protoreflect.ValueOfInt32(v), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := internal.ToInt64(value)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := internal.ToUint32(value)
+		return protoreflect.ValueOfUint32(v), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := internal.ToUint64(value)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := internal.ToFloat32(value)
+		return protoreflect.ValueOfFloat32(v), err
+	case protoreflect.DoubleKind:
+		v, err := internal.ToFloat64(value)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfString(v), err
+	case protoreflect.BytesKind:
+		if v, ok := value.([]byte); ok {
+			return protoreflect.ValueOfBytes(v), nil
+		}
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfBytes([]byte(v)), err
+	case protoreflect.EnumKind:
+		// Enums can be given by name or number
+		if name, ok := value.(string); ok {
+			if ev := fd.Enum().Values().ByName(protoreflect.Name(name)); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), nil
+			}
+		}
+		v, err := internal.ToInt32(value)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid value %v for enum %q", value, fd.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported field type %q", fd.Kind())
+}
+
+func fieldPath(path []protoreflect.FieldDescriptor) string {
+	parts := make([]string, 0, len(path))
+	for _, fd := range path {
+		parts = append(parts, string(fd.Name()))
+	}
+	return strings.Join(parts, ".")
+}
+
+func init() {
+	serializers.Add("protobuf",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
@@ -0,0 +1,210 @@
+package protobuf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newSerializer(mapping map[string]string) *Serializer {
+	return &Serializer{
+		MessageFiles: []string{"testdata/measurement.proto"},
+		MessageType:  "test.Measurement",
+		Mapping:      mapping,
+		Log:          testutil.Logger{},
+	}
+}
+
+func newMetric() telegraf.Metric {
+	return metric.New(
+		"cpu",
+		map[string]string{"host": "localhost", "cpu": "cpu0"},
+		map[string]interface{}{"usage": 42.5, "count": 3, "status": "FAILED"},
+		time.Unix(1700000000, 123456789),
+	)
+}
+
+// decode unmarshals the message and returns its JSON representation
+func decode(t *testing.T, s *Serializer, buf []byte) string {
+	t.Helper()
+	msg := dynamicpb.NewMessage(s.desc)
+	require.NoError(t, proto.Unmarshal(buf, msg))
+	serialized, err := protojson.Marshal(msg)
+	require.NoError(t, err)
+	return string(serialized)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  map[string]string
+		expected string
+	}{
+		{
+			name:     "no mapping",
+			expected: "no protobuf_mapping given",
+		},
+		{
+			name:     "invalid source",
+			mapping:  map[string]string{"name": "measurement"},
+			expected: `invalid source "measurement"`,
+		},
+		{
+			name:     "empty key",
+			mapping:  map[string]string{"host": "tag:"},
+			expected: `empty key in source "tag:"`,
+		},
+		{
+			name:     "unknown field",
+			mapping:  map[string]string{"foo": "name"},
+			expected: `field "foo" not found in message "test.Measurement"`,
+		},
+		{
+			name:     "unknown nested field",
+			mapping:  map[string]string{"header.foo": "name"},
+			expected: `field "foo" not found in message "test.Header"`,
+		},
+		{
+			name:     "path through scalar",
+			mapping:  map[string]string{"name.foo": "name"},
+			expected: `field "name" is not a message`,
+		},
+		{
+			name:     "tags to scalar",
+			mapping:  map[string]string{"name": "tags"},
+			expected: `source "tags" requires a map with string keys`,
+		},
+		{
+			name:     "tags to double map",
+			mapping:  map[string]string{"fields": "tags"},
+			expected: `source "tags" requires a map with string values`,
+		},
+		{
+			name:     "repeated field",
+			mapping:  map[string]string{"list": "tag:host"},
+			expected: "repeated fields are not supported",
+		},
+		{
+			name:     "message field",
+			mapping:  map[string]string{"header": "name"},
+			expected: "message fields other than google.protobuf.Timestamp are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSerializer(tt.mapping)
+			require.ErrorContains(t, s.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitUnknownType(t *testing.T) {
+	s := newSerializer(map[string]string{"name": "name"})
+	s.MessageType = "test.Unknown"
+	require.ErrorContains(t, s.Init(), "not found")
+}
+
+func TestSerialize(t *testing.T) {
+	s := newSerializer(map[string]string{
+		"header.source": "tag:host",
+		"header.time":   "timestamp",
+		"name":          "name",
+		"host":          "tag:host",
+		"value":         "field:usage",
+		"count":         "field:count",
+		"status":        "field:status",
+		"timestamp_ms":  "timestamp",
+		"tags":          "tags",
+		"raw":           "tag:cpu",
+	})
+	s.TimestampUnits = config.Duration(time.Millisecond)
+	require.NoError(t, s.Init())
+
+	buf, err := s.Serialize(newMetric())
+	require.NoError(t, err)
+
+	expected := `{
+		"header": {"source": "localhost", "time": "2023-11-14T22:13:20.123456789Z"},
+		"name": "cpu",
+		"host": "localhost",
+		"value": 42.5,
+		"count": "3",
+		"status": "FAILED",
+		"timestampMs": "1700000000123",
+		"tags": {"host": "localhost", "cpu": "cpu0"},
+		"raw": "Y3B1MA=="
+	}`
+	require.JSONEq(t, expected, decode(t, s, buf))
+}
+
+func TestSerializeMissing(t *testing.T) {
+	s := newSerializer(map[string]string{
+		"header.source": "tag:region",
+		"name":          "name",
+		"value":         "field:temperature",
+	})
+	require.NoError(t, s.Init())
+
+	buf, err := s.Serialize(newMetric())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "cpu"}`, decode(t, s, buf))
+}
+
+func TestSerializeFieldsMap(t *testing.T) {
+	s := newSerializer(map[string]string{"fields": "fields", "timestamp_ms": "timestamp"})
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"usage": 42.5, "count": 3},
+		time.Unix(1700000000, 123456789),
+	)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `{"fields": {"usage": 42.5, "count": 3}, "timestampMs": "1700000000123456789"}`
+	require.JSONEq(t, expected, decode(t, s, buf))
+
+	_, err = s.Serialize(newMetric())
+	require.ErrorContains(t, err, `setting field "fields" failed: converting field "status" failed`)
+}
+
+func TestSerializeInvalidValue(t *testing.T) {
+	s := newSerializer(map[string]string{"status": "field:usage", "count": "tag:host"})
+	require.NoError(t, s.Init())
+
+	_, err := s.Serialize(newMetric())
+	require.ErrorContains(t, err, `setting field "count" failed`)
+}
+
+func TestSerializeBatch(t *testing.T) {
+	s := newSerializer(map[string]string{"name": "name", "host": "tag:host"})
+	require.NoError(t, s.Init())
+
+	m := newMetric()
+	single, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	buf, err := s.SerializeBatch([]telegraf.Metric{m, m})
+	require.NoError(t, err)
+
+	for range 2 {
+		msg, n := protowire.ConsumeBytes(buf)
+		require.Positive(t, n)
+		require.Equal(t, single, msg)
+		buf = buf[n:]
+	}
+	require.Empty(t, buf)
+}
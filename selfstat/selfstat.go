@@ -102,6 +102,25 @@ func Metrics() []telegraf.Metric {
 	return metrics
 }
 
+// Snapshot returns the current values of all stats registered for the given
+// measurement and tags keyed by field name. In contrast to Metrics(), timing
+// stats are not reset and report the average of the last completed cycle.
+func Snapshot(measurement string, tags map[string]string) map[string]int64 {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	stats := registry.stats[key("internal_"+measurement, tags)]
+	values := make(map[string]int64, len(stats))
+	for fieldname, s := range stats {
+		if ts, ok := s.(*timingStat); ok {
+			values[fieldname] = ts.peek()
+			continue
+		}
+		values[fieldname] = s.Get()
+	}
+	return values
+}
+
 type Registry struct {
 	stats map[uint64]map[string]Stat
 	mu    sync.Mutex
@@ -211,3 +211,26 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestSnapshot(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+
+	tags := map[string]string{"test": "foo"}
+	s := Register("test", "counter", tags)
+	ts := RegisterTiming("test", "timing", tags)
+	Register("test", "other", map[string]string{"test": "bar"}).Incr(42)
+
+	s.Incr(3)
+	ts.Incr(10)
+	ts.Incr(20)
+
+	expected := map[string]int64{"counter": 3, "timing": 15}
+	require.Equal(t, expected, Snapshot("test", tags))
+
+	// Taking a snapshot must not reset the timing stats
+	require.Equal(t, int64(15), ts.Get())
+	require.Equal(t, expected, Snapshot("test", tags))
+
+	require.Empty(t, Snapshot("unknown", tags))
+}